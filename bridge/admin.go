@@ -0,0 +1,244 @@
+package bridge
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/matrix-org/slackbridge/matrix"
+	"github.com/matrix-org/slackbridge/slack"
+)
+
+// NewAdminHandler returns an http.Handler serving an API for managing the
+// bridge's room and user links while it runs. It expects to be mounted at
+// /admin/, and requests must carry token as a bearer token.
+//
+//	GET    /admin/rooms                  list room links
+//	POST   /admin/rooms                  link a room
+//	DELETE /admin/rooms/{matrix_room_id} unlink a room
+//	GET    /admin/users                  list user links
+//	POST   /admin/users                  link a user
+//	DELETE /admin/users/{matrix_user_id} unlink a user
+func NewAdminHandler(token string, b *Bridge) *AdminHandler {
+	return &AdminHandler{
+		token:  token,
+		bridge: b,
+	}
+}
+
+type AdminHandler struct {
+	token  string
+	bridge *Bridge
+
+	mu                   sync.Mutex
+	roomLinkedHandlers   []func(slackChannel string)
+	userLinkedHandlers   []func(*slack.User)
+	userUnlinkedHandlers []func(*slack.User)
+}
+
+// OnRoomLinked registers a handler which is called after a room is linked.
+func (h *AdminHandler) OnRoomLinked(f func(slackChannel string)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.roomLinkedHandlers = append(h.roomLinkedHandlers, f)
+}
+
+// OnUserLinked registers a handler which is called after a user is linked.
+func (h *AdminHandler) OnUserLinked(f func(*slack.User)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.userLinkedHandlers = append(h.userLinkedHandlers, f)
+}
+
+// OnUserUnlinked registers a handler which is called after a user is
+// unlinked.
+func (h *AdminHandler) OnUserUnlinked(f func(*slack.User)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.userUnlinkedHandlers = append(h.userUnlinkedHandlers, f)
+}
+
+func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	auth := req.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		writeAdminError(w, http.StatusUnauthorized, "Missing access token")
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(auth[len("Bearer "):]), []byte(h.token)) != 1 {
+		writeAdminError(w, http.StatusForbidden, "Bad access token")
+		return
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, "/admin/")
+	collection, id := path, ""
+	if i := strings.Index(path, "/"); i != -1 {
+		collection, id = path[:i], path[i+1:]
+	}
+	switch {
+	case collection == "rooms" && id == "" && req.Method == "GET":
+		h.listRooms(w)
+	case collection == "rooms" && id == "" && req.Method == "POST":
+		h.linkRoom(w, req)
+	case collection == "rooms" && id != "" && req.Method == "DELETE":
+		h.unlinkRoom(w, id)
+	case collection == "users" && id == "" && req.Method == "GET":
+		h.listUsers(w)
+	case collection == "users" && id == "" && req.Method == "POST":
+		h.linkUser(w, req)
+	case collection == "users" && id != "" && req.Method == "DELETE":
+		h.unlinkUser(w, id)
+	case collection == "rooms" || collection == "users":
+		writeAdminError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	default:
+		writeAdminError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+func (h *AdminHandler) listRooms(w http.ResponseWriter) {
+	rooms := []adminRoom{}
+	for matrixID, slackID := range h.bridge.RoomMap.Links() {
+		rooms = append(rooms, adminRoom{SlackChannelID: slackID, MatrixRoomID: matrixID})
+	}
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].MatrixRoomID < rooms[j].MatrixRoomID })
+	writeAdminJSON(w, http.StatusOK, rooms)
+}
+
+func (h *AdminHandler) linkRoom(w http.ResponseWriter, req *http.Request) {
+	var r adminRoom
+	if err := json.NewDecoder(req.Body).Decode(&r); err != nil {
+		writeAdminError(w, http.StatusBadRequest, "Error decoding body")
+		return
+	}
+	if r.SlackChannelID == "" || r.MatrixRoomID == "" {
+		writeAdminError(w, http.StatusBadRequest, "slack_channel_id and matrix_room_id are required")
+		return
+	}
+	if h.bridge.RoomMap.SlackForMatrix(r.MatrixRoomID) != "" || h.bridge.RoomMap.MatrixForSlack(r.SlackChannelID) != nil {
+		writeAdminError(w, http.StatusConflict, "Room is already linked")
+		return
+	}
+	if err := h.bridge.RoomMap.Link(matrix.NewRoom(r.MatrixRoomID), r.SlackChannelID); err != nil {
+		log.Printf("Error linking room: %v", err)
+		writeAdminError(w, http.StatusInternalServerError, "Error linking room")
+		return
+	}
+	h.mu.Lock()
+	handlers := h.roomLinkedHandlers
+	h.mu.Unlock()
+	for _, f := range handlers {
+		f(r.SlackChannelID)
+	}
+	writeAdminJSON(w, http.StatusCreated, r)
+}
+
+func (h *AdminHandler) unlinkRoom(w http.ResponseWriter, matrixRoomID string) {
+	slackChannel := h.bridge.RoomMap.SlackForMatrix(matrixRoomID)
+	if slackChannel == "" {
+		writeAdminError(w, http.StatusNotFound, "Room is not linked")
+		return
+	}
+	if err := h.bridge.RoomMap.Unlink(matrixRoomID); err != nil {
+		log.Printf("Error unlinking room: %v", err)
+		writeAdminError(w, http.StatusInternalServerError, "Error unlinking room")
+		return
+	}
+	// Nobody should read from or post to the channel on the bridge's behalf
+	// any more.
+	h.bridge.SlackRoomMembers.RemoveChannel(slackChannel)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AdminHandler) listUsers(w http.ResponseWriter) {
+	users := []adminUser{}
+	for matrixID, slackID := range h.bridge.UserMap.Links() {
+		users = append(users, adminUser{SlackUserID: slackID, MatrixUserID: matrixID})
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].MatrixUserID < users[j].MatrixUserID })
+	writeAdminJSON(w, http.StatusOK, users)
+}
+
+func (h *AdminHandler) linkUser(w http.ResponseWriter, req *http.Request) {
+	var r adminUser
+	if err := json.NewDecoder(req.Body).Decode(&r); err != nil {
+		writeAdminError(w, http.StatusBadRequest, "Error decoding body")
+		return
+	}
+	if r.SlackUserID == "" || r.SlackAccessToken == "" || r.MatrixUserID == "" || r.MatrixAccessToken == "" || r.MatrixHomeserver == "" {
+		writeAdminError(w, http.StatusBadRequest, "slack_user_id, slack_access_token, matrix_user_id, matrix_access_token and matrix_homeserver are required")
+		return
+	}
+	if h.bridge.UserMap.SlackForMatrix(r.MatrixUserID) != nil || h.bridge.UserMap.MatrixForSlack(r.SlackUserID) != nil {
+		writeAdminError(w, http.StatusConflict, "User is already linked")
+		return
+	}
+
+	b := h.bridge
+	matrixClient := matrix.NewClient(r.MatrixAccessToken, b.Client, r.MatrixHomeserver, b.MatrixEchoSuppresser)
+	matrixUser := matrix.NewUser(r.MatrixUserID, matrixClient)
+	slackClient := slack.NewClient(r.SlackAccessToken, b.Client, b.RoomMap.ShouldNotify)
+	slackUser := &slack.User{r.SlackUserID, slackClient}
+	if err := b.UserMap.Link(matrixUser, slackUser); err != nil {
+		log.Printf("Error linking user: %v", err)
+		writeAdminError(w, http.StatusInternalServerError, "Error linking user")
+		return
+	}
+	h.mu.Lock()
+	handlers := h.userLinkedHandlers
+	h.mu.Unlock()
+	for _, f := range handlers {
+		f(slackUser)
+	}
+	writeAdminJSON(w, http.StatusCreated, adminUser{SlackUserID: r.SlackUserID, MatrixUserID: r.MatrixUserID})
+}
+
+func (h *AdminHandler) unlinkUser(w http.ResponseWriter, matrixUserID string) {
+	slackUser, err := h.bridge.UserMap.Unlink(matrixUserID)
+	if err != nil {
+		log.Printf("Error unlinking user: %v", err)
+		writeAdminError(w, http.StatusInternalServerError, "Error unlinking user")
+		return
+	}
+	if slackUser == nil {
+		writeAdminError(w, http.StatusNotFound, "User is not linked")
+		return
+	}
+	h.mu.Lock()
+	handlers := h.userUnlinkedHandlers
+	h.mu.Unlock()
+	for _, f := range handlers {
+		f(slackUser)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeAdminJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeAdminError(w http.ResponseWriter, code int, message string) {
+	writeAdminJSON(w, code, adminError{message})
+}
+
+type adminRoom struct {
+	SlackChannelID string `json:"slack_channel_id"`
+	MatrixRoomID   string `json:"matrix_room_id"`
+}
+
+// Tokens are accepted when linking a user, but never returned.
+type adminUser struct {
+	SlackUserID       string `json:"slack_user_id"`
+	SlackAccessToken  string `json:"slack_access_token,omitempty"`
+	MatrixUserID      string `json:"matrix_user_id"`
+	MatrixAccessToken string `json:"matrix_access_token,omitempty"`
+	MatrixHomeserver  string `json:"matrix_homeserver,omitempty"`
+}
+
+type adminError struct {
+	Error string `json:"error"`
+}
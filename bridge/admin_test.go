@@ -0,0 +1,122 @@
+package bridge
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matrix-org/slackbridge/slack"
+)
+
+func TestAdminRequiresToken(t *testing.T) {
+	admin := NewAdminHandler("sekrit", makeBridge(t, makeStore(t)))
+	for _, tc := range []struct {
+		auth string
+		want int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusForbidden},
+		{"Bearer sekrit", http.StatusOK},
+	} {
+		req := httptest.NewRequest("GET", "/admin/rooms", nil)
+		if tc.auth != "" {
+			req.Header.Set("Authorization", tc.auth)
+		}
+		w := httptest.NewRecorder()
+		admin.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("%q: want %d got %d", tc.auth, tc.want, w.Code)
+		}
+	}
+}
+
+func TestAdminRooms(t *testing.T) {
+	b := makeBridge(t, makeStore(t))
+	admin := NewAdminHandler("sekrit", b)
+	var linked []string
+	admin.OnRoomLinked(func(channel string) {
+		linked = append(linked, channel)
+	})
+
+	adminRequest(t, admin, "POST", "/admin/rooms", `{"slack_channel_id": "BOWLINGALLEY", "matrix_room_id": "!def456:matrix.org"}`, http.StatusCreated)
+	if got := b.RoomMap.SlackForMatrix("!def456:matrix.org"); got != "BOWLINGALLEY" {
+		t.Errorf("SlackForMatrix: want %q got %q", "BOWLINGALLEY", got)
+	}
+	if len(linked) != 1 || linked[0] != "BOWLINGALLEY" {
+		t.Errorf("OnRoomLinked: want [BOWLINGALLEY] got %v", linked)
+	}
+	adminRequest(t, admin, "POST", "/admin/rooms", `{"slack_channel_id": "BOWLINGALLEY", "matrix_room_id": "!ghi789:matrix.org"}`, http.StatusConflict)
+
+	got := adminRequest(t, admin, "GET", "/admin/rooms", "", http.StatusOK)
+	want := `[{"slack_channel_id":"CANTINA","matrix_room_id":"!abc123:matrix.org"},{"slack_channel_id":"BOWLINGALLEY","matrix_room_id":"!def456:matrix.org"}]`
+	if got != want {
+		t.Errorf("List: want %s got %s", want, got)
+	}
+
+	b.SlackRoomMembers = slack.NewRoomMembers()
+	b.SlackRoomMembers.Add("CANTINA", b.UserMap.SlackForMatrix("@nancy:st.andrews"))
+	adminRequest(t, admin, "DELETE", "/admin/rooms/!abc123:matrix.org", "", http.StatusNoContent)
+	if got := b.RoomMap.MatrixForSlack("CANTINA"); got != nil {
+		t.Errorf("MatrixForSlack after unlink: want nil got %v", got)
+	}
+	if got := b.SlackRoomMembers.Any("CANTINA"); got != nil {
+		t.Errorf("SlackRoomMembers after unlink: want none got %v", got)
+	}
+	if got := b.slackUserFor("CANTINA", "@vivian:st.andrews"); got != nil {
+		t.Errorf("slackUserFor after unlink: want nil got %v", got)
+	}
+	adminRequest(t, admin, "DELETE", "/admin/rooms/!abc123:matrix.org", "", http.StatusNotFound)
+}
+
+func TestAdminUsers(t *testing.T) {
+	b := makeBridge(t, makeStore(t))
+	admin := NewAdminHandler("sekrit", b)
+	var linked, unlinked []*slack.User
+	admin.OnUserLinked(func(u *slack.User) {
+		linked = append(linked, u)
+	})
+	admin.OnUserUnlinked(func(u *slack.User) {
+		unlinked = append(unlinked, u)
+	})
+
+	adminRequest(t, admin, "POST", "/admin/users", `{"slack_user_id": "U35"}`, http.StatusBadRequest)
+	adminRequest(t, admin, "POST", "/admin/users", `{
+		"slack_user_id": "U35",
+		"slack_access_token": "xoxp-abc",
+		"matrix_user_id": "@vivian:st.andrews",
+		"matrix_access_token": "def",
+		"matrix_homeserver": "https://matrix.org"
+	}`, http.StatusCreated)
+	if got := b.UserMap.SlackForMatrix("@vivian:st.andrews"); got == nil || got.UserID != "U35" {
+		t.Errorf("SlackForMatrix: want U35 got %v", got)
+	}
+	if len(linked) != 1 || linked[0].UserID != "U35" {
+		t.Errorf("OnUserLinked: want [U35] got %v", linked)
+	}
+
+	got := adminRequest(t, admin, "GET", "/admin/users", "", http.StatusOK)
+	want := `[{"slack_user_id":"U34","matrix_user_id":"@nancy:st.andrews"},{"slack_user_id":"U35","matrix_user_id":"@vivian:st.andrews"}]`
+	if got != want {
+		t.Errorf("List: want %s got %s", want, got)
+	}
+
+	adminRequest(t, admin, "DELETE", "/admin/users/@vivian:st.andrews", "", http.StatusNoContent)
+	if got := b.UserMap.MatrixForSlack("U35"); got != nil {
+		t.Errorf("MatrixForSlack after unlink: want nil got %v", got)
+	}
+	if len(unlinked) != 1 || unlinked[0] != linked[0] {
+		t.Errorf("OnUserUnlinked: want %v got %v", linked, unlinked)
+	}
+}
+
+func adminRequest(t *testing.T, admin *AdminHandler, method, path, body string, wantCode int) string {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer sekrit")
+	w := httptest.NewRecorder()
+	admin.ServeHTTP(w, req)
+	if w.Code != wantCode {
+		t.Errorf("%s %s: want %d got %d (%s)", method, path, wantCode, w.Code, w.Body.String())
+	}
+	return strings.TrimSpace(w.Body.String())
+}
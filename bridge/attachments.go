@@ -0,0 +1,152 @@
+package bridge
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/matrix-org/slackbridge/matrix"
+	"github.com/matrix-org/slackbridge/slack"
+)
+
+var (
+	attachmentHexColor = regexp.MustCompile(`^#?([0-9A-Fa-f]{6})$`)
+	attachmentColors   = map[string]string{
+		"good":    "#2eb886",
+		"warning": "#daa038",
+		"danger":  "#a30200",
+	}
+)
+
+func (b *Bridge) slackAttachmentsToMatrix(slackChannel string, attachments []*slack.Attachment, matrixRoom *matrix.Room) (*matrix.TextMessageContent, []slackImage) {
+	r := &blockRenderer{bridge: b, slackChannel: slackChannel, matrixRoom: matrixRoom}
+	for _, attachment := range attachments {
+		r.attachment(attachment)
+	}
+	return r.content(), r.images
+}
+
+// attachment renders an attachment as a quote under its pretext, starting
+// with a mark in the attachment's color.
+func (r *blockRenderer) attachment(a *slack.Attachment) {
+	if body, markup := r.text(&slack.TextObject{Type: "mrkdwn", Text: a.Pretext}); body != "" {
+		r.add(body, "<p>"+markup+"</p>")
+	}
+
+	var bodies, lines []string
+	quote := ""
+	line := func(body, markup string) {
+		if body != "" {
+			bodies = append(bodies, body)
+			lines = append(lines, markup)
+		}
+	}
+	flush := func() {
+		if len(lines) > 0 {
+			quote += "<p>" + strings.Join(lines, "<br>") + "</p>"
+			lines = nil
+		}
+	}
+	if a.Title != "" {
+		title := slackToMatrix(a.Title)
+		if a.TitleLink != "" && mrkdwnSafeURL.MatchString(a.TitleLink) {
+			line(title+" ( "+a.TitleLink+" )", `<strong><a href="`+html.EscapeString(a.TitleLink)+`">`+html.EscapeString(title)+"</a></strong>")
+		} else {
+			line(title, "<strong>"+html.EscapeString(title)+"</strong>")
+		}
+	}
+	line(r.text(&slack.TextObject{Type: "mrkdwn", Text: a.Text}))
+	for _, field := range a.Fields {
+		body, value := r.text(&slack.TextObject{Type: "mrkdwn", Text: field.Value})
+		if field.Title != "" {
+			title := slackToMatrix(field.Title)
+			body = title + ": " + body
+			value = "<strong>" + html.EscapeString(title) + "</strong>: " + value
+		}
+		line(body, value)
+	}
+	if len(a.Blocks) > 0 {
+		flush()
+		blocks := &blockRenderer{bridge: r.bridge, slackChannel: r.slackChannel, matrixRoom: r.matrixRoom}
+		for _, block := range a.Blocks {
+			blocks.block(block)
+		}
+		bodies = append(bodies, blocks.body...)
+		quote += strings.Join(blocks.formatted, "")
+		for _, userID := range blocks.userIDs {
+			r.mention(userID)
+		}
+		r.room = r.room || blocks.room
+		r.images = append(r.images, blocks.images...)
+	}
+	if body, footer := r.text(&slack.TextObject{Type: "mrkdwn", Text: a.Footer}); body != "" {
+		line(body, "<sub>"+footer+"</sub>")
+	}
+	flush()
+	if len(bodies) == 0 && a.ImageURL == "" {
+		line(r.text(&slack.TextObject{Type: "plain_text", Text: a.Fallback}))
+		flush()
+	}
+
+	if len(bodies) > 0 {
+		if color := attachmentColor(a.Color); color != "" {
+			quote = `<font data-mx-color="` + color + `">▌</font>` + quote
+		}
+		r.add("> "+strings.Replace(strings.Join(bodies, "\n"), "\n", "\n> ", -1), "<blockquote>"+quote+"</blockquote>")
+	}
+	r.image(a.ImageURL, slackToMatrix(a.Title))
+}
+
+// attachmentColor returns an attachment's color as a hex color for Matrix, or
+// "" if it isn't one which slack understands.
+func attachmentColor(color string) string {
+	if hex, ok := attachmentColors[color]; ok {
+		return hex
+	}
+	if match := attachmentHexColor.FindStringSubmatch(color); match != nil {
+		return "#" + strings.ToLower(match[1])
+	}
+	return ""
+}
+
+// joinMatrixContent appends the bodies of extra to content, merging their
+// formatting and mentions.
+func joinMatrixContent(content, extra *matrix.TextMessageContent) *matrix.TextMessageContent {
+	if content.Body == "" {
+		extra.MsgType = content.MsgType
+		return extra
+	}
+	if extra.Body == "" {
+		return content
+	}
+	joined := &matrix.TextMessageContent{
+		Body:    content.Body + "\n" + extra.Body,
+		MsgType: content.MsgType,
+	}
+	if formatted := formattedBody(content) + formattedBody(extra); formatted != textToHTML(joined.Body) {
+		joined.Format = "org.matrix.custom.html"
+		joined.FormattedBody = formatted
+	}
+	if content.Mentions != nil || extra.Mentions != nil {
+		joined.Mentions = &matrix.Mentions{}
+		for _, mentions := range []*matrix.Mentions{content.Mentions, extra.Mentions} {
+			if mentions == nil {
+				continue
+			}
+			for _, userID := range mentions.UserIDs {
+				if !contains(joined.Mentions.UserIDs, userID) {
+					joined.Mentions.UserIDs = append(joined.Mentions.UserIDs, userID)
+				}
+			}
+			joined.Mentions.Room = joined.Mentions.Room || mentions.Room
+		}
+	}
+	return joined
+}
+
+func formattedBody(content *matrix.TextMessageContent) string {
+	if content.FormattedBody != "" {
+		return content.FormattedBody
+	}
+	return textToHTML(content.Body)
+}
@@ -0,0 +1,307 @@
+package bridge
+
+import (
+	"html"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/matrix-org/slackbridge/matrix"
+	"github.com/matrix-org/slackbridge/slack"
+)
+
+// slackImage is an image in a slack message which is sent to Matrix as media.
+type slackImage struct {
+	Text  string
+	Image *matrix.Image
+}
+
+// link returns the image as a link, for when it can't be sent as media.
+func (i slackImage) link() *matrix.TextMessageContent {
+	return &matrix.TextMessageContent{
+		Body:          i.Image.URL,
+		MsgType:       "m.text",
+		Format:        "org.matrix.custom.html",
+		FormattedBody: `<a href="` + html.EscapeString(i.Image.URL) + `">` + html.EscapeString(i.Text) + `</a>`,
+	}
+}
+
+// hasLayoutBlocks says whether blocks have anything which isn't in the text of
+// their message. Messages typed in slack's own clients come with a rich_text
+// block which just repeats their text.
+func hasLayoutBlocks(blocks []*slack.Block) bool {
+	for _, block := range blocks {
+		if block.Type != "rich_text" {
+			return true
+		}
+	}
+	return false
+}
+
+// blockRenderer renders Block Kit blocks as Matrix message content.
+// Interactive elements can't be used from Matrix, so they are shown as text.
+type blockRenderer struct {
+	bridge       *Bridge
+	slackChannel string
+	matrixRoom   *matrix.Room
+
+	body      []string
+	formatted []string
+	userIDs   []string
+	room      bool
+	images    []slackImage
+}
+
+func (b *Bridge) slackBlocksToMatrix(slackChannel string, blocks []*slack.Block, matrixRoom *matrix.Room) (*matrix.TextMessageContent, []slackImage) {
+	r := &blockRenderer{bridge: b, slackChannel: slackChannel, matrixRoom: matrixRoom}
+	for _, block := range blocks {
+		r.block(block)
+	}
+	return r.content(), r.images
+}
+
+func (r *blockRenderer) content() *matrix.TextMessageContent {
+	content := &matrix.TextMessageContent{
+		Body:    strings.Join(r.body, "\n"),
+		MsgType: "m.text",
+	}
+	if formatted := strings.Join(r.formatted, ""); formatted != textToHTML(content.Body) {
+		content.Format = "org.matrix.custom.html"
+		content.FormattedBody = formatted
+	}
+	if len(r.userIDs) > 0 || r.room {
+		content.Mentions = &matrix.Mentions{UserIDs: r.userIDs, Room: r.room}
+	}
+	return content
+}
+
+func (r *blockRenderer) add(body, markup string) {
+	if body == "" {
+		return
+	}
+	r.body = append(r.body, body)
+	r.formatted = append(r.formatted, markup)
+}
+
+func (r *blockRenderer) block(block *slack.Block) {
+	switch block.Type {
+	case "header":
+		body, markup := r.text(block.Text)
+		r.add(body, "<h3>"+markup+"</h3>")
+	case "divider":
+		r.add("---", "<hr>")
+	case "image":
+		text := block.AltText
+		if block.Title != nil && block.Title.Text != "" {
+			text = block.Title.Text
+		}
+		r.image(block.ImageURL, text)
+	case "context", "actions":
+		var bodies, markups []string
+		for _, element := range block.Elements {
+			if body, markup := r.element(element); body != "" {
+				bodies = append(bodies, body)
+				markups = append(markups, markup)
+			}
+		}
+		r.add(strings.Join(bodies, " "), "<p>"+strings.Join(markups, " ")+"</p>")
+	case "rich_text":
+		for _, element := range block.Elements {
+			r.richText(element)
+		}
+	default:
+		// Sections, and anything newer with text.
+		var bodies, markups []string
+		for _, t := range append([]*slack.TextObject{block.Text}, block.Fields...) {
+			if body, markup := r.text(t); body != "" {
+				bodies = append(bodies, body)
+				markups = append(markups, markup)
+			}
+		}
+		if accessory := block.Accessory; accessory != nil && accessory.Type == "image" {
+			r.image(accessory.ImageURL, accessory.AltText)
+		} else if body, markup := r.element(accessory); body != "" {
+			bodies = append(bodies, body)
+			markups = append(markups, markup)
+		}
+		r.add(strings.Join(bodies, "\n"), "<p>"+strings.Join(markups, "<br>")+"</p>")
+	}
+}
+
+func (r *blockRenderer) image(url, text string) {
+	if url == "" {
+		return
+	}
+	if text == "" {
+		text = path.Base(url)
+	}
+	r.images = append(r.images, slackImage{Text: text, Image: &matrix.Image{URL: url}})
+}
+
+// text converts a text object, which is either plain text or mrkdwn.
+func (r *blockRenderer) text(t *slack.TextObject) (body, formatted string) {
+	if t == nil || t.Text == "" {
+		return "", ""
+	}
+	if t.Type == "plain_text" {
+		// Plain text isn't escaped, but emoji in it are still shown.
+		body = slackToMatrix(matrixToSlack(t.Text))
+		return body, textToHTML(body)
+	}
+	body, formatted, mentions := slackToMatrixWithLinks(t.Text, func(slackUserID, label string) *matrixMention {
+		return r.bridge.matrixMention(r.slackChannel, slackUserID, label, r.matrixRoom)
+	}, r.bridge.matrixRoomLink)
+	if mentions != nil {
+		for _, userID := range mentions.UserIDs {
+			r.mention(userID)
+		}
+		r.room = r.room || mentions.Room
+	}
+	if formatted == "" {
+		formatted = textToHTML(body)
+	}
+	return body, formatted
+}
+
+func (r *blockRenderer) mention(userID string) {
+	if !contains(r.userIDs, userID) {
+		r.userIDs = append(r.userIDs, userID)
+	}
+}
+
+// element converts an element of a context or actions block, or a section's
+// accessory.
+func (r *blockRenderer) element(e *slack.BlockElement) (body, formatted string) {
+	if e == nil {
+		return "", ""
+	}
+	switch e.Type {
+	case "mrkdwn", "plain_text":
+		if e.Text == nil {
+			return "", ""
+		}
+		return r.text(&slack.TextObject{Type: e.Type, Text: e.Text.Text})
+	case "image":
+		return e.AltText, html.EscapeString(e.AltText)
+	}
+	label, _ := r.text(e.Text)
+	if label == "" {
+		label, _ = r.text(e.Placeholder)
+	}
+	if label == "" {
+		return "", ""
+	}
+	if e.URL != "" && mrkdwnSafeURL.MatchString(e.URL) {
+		return label + " ( " + e.URL + " )", `<a href="` + html.EscapeString(e.URL) + `">` + html.EscapeString(label) + "</a>"
+	}
+	return "[" + label + "]", html.EscapeString("[" + label + "]")
+}
+
+// richText converts a section, list, quote or preformatted element of a
+// rich_text block.
+func (r *blockRenderer) richText(e *slack.BlockElement) {
+	switch e.Type {
+	case "rich_text_list":
+		tag, indent := "ul", strings.Repeat("    ", e.Indent)
+		if e.Style != nil && e.Style.Name == "ordered" {
+			tag = "ol"
+		}
+		var bodies, markups []string
+		for i, item := range e.Elements {
+			body, markup := r.richInline(item.Elements)
+			bullet := "• "
+			if tag == "ol" {
+				bullet = strconv.Itoa(e.Offset+i+1) + ". "
+			}
+			bodies = append(bodies, indent+bullet+body)
+			markups = append(markups, "<li>"+markup+"</li>")
+		}
+		start := ""
+		if tag == "ol" && e.Offset != 0 {
+			start = ` start="` + strconv.Itoa(e.Offset+1) + `"`
+		}
+		r.add(strings.Join(bodies, "\n"), "<"+tag+start+">"+strings.Join(markups, "")+"</"+tag+">")
+	case "rich_text_quote":
+		body, markup := r.richInline(e.Elements)
+		r.add("> "+strings.Replace(body, "\n", "\n> ", -1), "<blockquote>"+markup+"</blockquote>")
+	case "rich_text_preformatted":
+		code := ""
+		for _, element := range e.Elements {
+			if element.Text != nil {
+				code += element.Text.Text
+			} else if element.URL != "" {
+				code += element.URL
+			}
+		}
+		r.add(code, "<pre><code>"+html.EscapeString(code)+"</code></pre>")
+	default:
+		body, markup := r.richInline(e.Elements)
+		r.add(strings.TrimSuffix(body, "\n"), "<p>"+strings.TrimSuffix(markup, "<br>")+"</p>")
+	}
+}
+
+// richInline converts the text, links, mentions and emoji in a rich text
+// element.
+func (r *blockRenderer) richInline(elements []*slack.BlockElement) (body, formatted string) {
+	for _, e := range elements {
+		text, markup := "", ""
+		switch e.Type {
+		case "link":
+			text = e.URL
+			if e.Text != nil && e.Text.Text != "" && e.Text.Text != e.URL {
+				text = e.Text.Text + " ( " + e.URL + " )"
+				markup = e.Text.Text
+			}
+			if mrkdwnSafeURL.MatchString(e.URL) {
+				if markup == "" {
+					markup = e.URL
+				}
+				markup = `<a href="` + html.EscapeString(e.URL) + `">` + html.EscapeString(markup) + "</a>"
+			} else {
+				markup = textToHTML(text)
+			}
+		case "user":
+			text = "@" + e.UserID
+			markup = html.EscapeString(text)
+			if mention := r.bridge.matrixMention(r.slackChannel, e.UserID, "", r.matrixRoom); mention != nil {
+				text = mention.DisplayName
+				markup = matrixLinkHTML(mention.UserID, mention.DisplayName)
+				r.mention(mention.UserID)
+			}
+		case "channel":
+			text = "#" + r.bridge.slackChannelName(r.slackChannel, e.ChannelID)
+			markup = html.EscapeString(text)
+			if roomLink := r.bridge.matrixRoomLink(e.ChannelID); roomLink != "" {
+				markup = matrixLinkHTML(roomLink, text)
+			}
+		case "usergroup":
+			text = "@" + e.UsergroupID
+			markup = html.EscapeString(text)
+		case "broadcast":
+			text, markup = "@room", "@room"
+			r.room = true
+		case "emoji":
+			text = slackReactionToMatrix(e.Name)
+			markup = html.EscapeString(text)
+		default:
+			if e.Text == nil {
+				continue
+			}
+			text = e.Text.Text
+			markup = textToHTML(text)
+		}
+		if e.Style != nil {
+			for _, style := range []struct {
+				on  bool
+				tag string
+			}{{e.Style.Code, "code"}, {e.Style.Strike, "del"}, {e.Style.Italic, "em"}, {e.Style.Bold, "strong"}} {
+				if style.on {
+					markup = "<" + style.tag + ">" + markup + "</" + style.tag + ">"
+				}
+			}
+		}
+		body += text
+		formatted += markup
+	}
+	return body, formatted
+}
@@ -6,12 +6,15 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
 	"path"
+	"regexp"
 	"strings"
 
 	"github.com/matrix-org/slackbridge/common"
 	"github.com/matrix-org/slackbridge/matrix"
 	"github.com/matrix-org/slackbridge/slack"
+	"github.com/matrix-org/slackbridge/store"
 )
 
 type Config struct {
@@ -19,11 +22,16 @@ type Config struct {
 	UserPrefix          string
 	HomeserverBaseURL   string
 	HomeserverName      string
+
+	// The most messages to replay per channel when catching up on Slack.
+	// 0 means no limit.
+	SlackCatchUpLimit int
 }
 
 type Bridge struct {
 	UserMap              *UserMap
 	RoomMap              *RoomMap
+	MessageMap           *MessageMap
 	SlackRoomMembers     *slack.RoomMembers
 	MatrixUsers          *matrix.Users
 	Client               http.Client
@@ -37,33 +45,117 @@ func (b *Bridge) OnSlackMessage(m slack.Message) {
 		log.Printf("Ignoring event for unknown slack room %q", m.Channel)
 		return
 	}
-	matrixUser := b.UserMap.MatrixForSlack(m.User)
-	if matrixUser == nil {
-		matrixUser = b.matrixUserFor(m.Channel, m.User, matrixRoom)
+	switch m.Subtype {
+	case "message_changed":
+		b.handleSlackEdit(m, matrixRoom)
+		return
+	case "message_deleted":
+		b.handleSlackDelete(m, matrixRoom)
+		return
 	}
+	matrixUser := b.matrixUserForSlack(m.Channel, m.User, matrixRoom)
 	if matrixUser == nil {
 		log.Printf("Ignoring event from unknown slack user %q", m.User)
 		return
 	}
 
+	content, images := b.slackMessageContent(m.Channel, &m, matrixRoom)
 	if m.Subtype == "me_message" {
-		if err := matrixUser.Client.SendEmote(matrixRoom.ID, slackToMatrix(m.Text)); err != nil {
-			log.Printf("Error sending emote to Matrix: %v", err)
+		content.MsgType = "m.emote"
+	} else if m.File != nil {
+		if handled := b.handleSlackFile(m, matrixRoom, matrixUser); handled {
+			return
 		}
+	}
+
+	// Images are sent first so that the text, which can be edited, is the
+	// last event for the message, as with files.
+	for _, image := range images {
+		eventID, err := matrixUser.Client.SendImage(matrixRoom.ID, image.Text, image.Image)
+		if err != nil {
+			log.Printf("Error sending image to Matrix: %v - sending it as a link", err)
+			content = joinMatrixContent(content, image.link())
+			continue
+		}
+		b.MessageMap.Add(m.Channel, m.TS, matrixRoom.ID, eventID, matrixUser.UserID)
+	}
+	content.RelatesTo = b.slackThreadToMatrix(m)
+	if content.RelatesTo == nil {
+		content.RelatesTo = b.slackReplyToMatrix(m)
+	}
+	if content.Body == "" && len(images) > 0 {
 		return
 	}
-	if m.File != nil {
-		if handled := b.handleSlackFile(m, matrixRoom.ID, matrixUser); handled {
-			return
+
+	eventID, err := sendMatrixMessage(matrixUser.Client, matrixRoom.ID, content)
+	if err != nil {
+		log.Printf("Error sending message to Matrix: %v", err)
+	}
+	b.MessageMap.Add(m.Channel, m.TS, matrixRoom.ID, eventID, matrixUser.UserID)
+}
+
+// slackThreadToMatrix returns the relation which puts a slack thread reply in
+// the Matrix thread rooted at the event its parent was bridged to or from.
+// Replies which were also sent to the channel are shown in the room as plain
+// replies to the parent instead.
+func (b *Bridge) slackThreadToMatrix(m slack.Message) *matrix.RelatesTo {
+	if m.ThreadTS == "" || m.ThreadTS == m.TS {
+		return nil
+	}
+	parents := b.MessageMap.MatrixForSlack(m.Channel, m.ThreadTS)
+	if len(parents) == 0 {
+		log.Printf("No matrix event for slack thread %q - sending reply to the room", m.ThreadTS)
+		return nil
+	}
+	root := parents[0].MatrixEventID
+	if m.Subtype == "thread_broadcast" {
+		return &matrix.RelatesTo{InReplyTo: &matrix.InReplyTo{EventID: root}}
+	}
+	return &matrix.RelatesTo{
+		RelType:       "m.thread",
+		EventID:       root,
+		InReplyTo:     &matrix.InReplyTo{EventID: root},
+		IsFallingBack: true,
+	}
+}
+
+var slackPermalink = regexp.MustCompile(`<https://[^/|>]+/archives/([A-Z0-9]+)/p(\d{10})(\d{6})[^>]*>`)
+
+// slackReplyToMatrix returns the relation which makes m a Matrix reply to the
+// first bridged message in the same channel which it links to. Slack has no
+// replies outside threads, so people reply by pasting a link to the message.
+func (b *Bridge) slackReplyToMatrix(m slack.Message) *matrix.RelatesTo {
+	for _, match := range slackPermalink.FindAllStringSubmatch(m.Text, -1) {
+		if match[1] != m.Channel {
+			continue
+		}
+		if originals := b.MessageMap.MatrixForSlack(m.Channel, match[2]+"."+match[3]); len(originals) > 0 {
+			return &matrix.RelatesTo{InReplyTo: &matrix.InReplyTo{EventID: originals[0].MatrixEventID}}
 		}
 	}
+	return nil
+}
+
+// sendMatrixMessage sends content using the simplest method which can carry
+// it.
+func sendMatrixMessage(client matrix.Client, roomID string, content *matrix.TextMessageContent) (string, error) {
+	switch {
+	case content.RelatesTo != nil, content.Mentions != nil:
+		return client.SendMessage(roomID, content)
+	case content.MsgType == "m.emote":
+		return client.SendEmote(roomID, content.Body, content.FormattedBody)
+	}
+	return client.SendText(roomID, content.Body, content.FormattedBody)
+}
 
-	if err := matrixUser.Client.SendText(matrixRoom.ID, slackToMatrix(m.Text)); err != nil {
-		log.Printf("Error sending text to Matrix: %v", err)
+func (b *Bridge) matrixUserForSlack(slackChannel, slackUserID string, matrixRoom *matrix.Room) *matrix.User {
+	if matrixUser := b.UserMap.MatrixForSlack(slackUserID); matrixUser != nil {
+		return matrixUser
 	}
+	return b.matrixUserFor(slackChannel, slackUserID, matrixRoom)
 }
 
-func (b *Bridge) handleSlackFile(m slack.Message, matrixRoom string, matrixUser *matrix.User) bool {
+func (b *Bridge) handleSlackFile(m slack.Message, matrixRoom *matrix.Room, matrixUser *matrix.User) bool {
 	if !strings.HasPrefix(m.File.MIMEType, "image/") {
 		return false
 	}
@@ -77,17 +169,143 @@ func (b *Bridge) handleSlackFile(m slack.Message, matrixRoom string, matrixUser
 		},
 	}
 	basename := path.Base(m.File.URL)
-	if err := matrixUser.Client.SendImage(matrixRoom, basename, matrixImage); err != nil {
+	eventID, err := matrixUser.Client.SendImage(matrixRoom.ID, basename, matrixImage)
+	if err != nil {
 		log.Printf("Error sending image to Matrix: %v", err)
 	}
+	b.MessageMap.Add(m.Channel, m.TS, matrixRoom.ID, eventID, matrixUser.UserID)
 	if m.File.CommentsCount == 1 && m.File.InitialComment != nil {
-		if err := matrixUser.Client.SendText(matrixRoom, slackToMatrix(m.File.InitialComment.Comment)); err != nil {
+		content := b.slackToMatrixContent(m.Channel, m.File.InitialComment.Comment, matrixRoom)
+		eventID, err := sendMatrixMessage(matrixUser.Client, matrixRoom.ID, content)
+		if err != nil {
 			log.Printf("Error sending text to Matrix: %v", err)
 		}
+		b.MessageMap.Add(m.Channel, m.TS, matrixRoom.ID, eventID, matrixUser.UserID)
 	}
 	return true
 }
 
+// handleSlackEdit sends a slack message_changed event to Matrix as an edit of
+// the event the original message was bridged to.
+func (b *Bridge) handleSlackEdit(m slack.Message, matrixRoom *matrix.Room) {
+	edited := m.Message
+	if edited == nil {
+		log.Printf("Ignoring message_changed event with no message: %v", m)
+		return
+	}
+	// Slack also sends message_changed when it unfurls links.
+	if m.PreviousMessage != nil && m.PreviousMessage.Text == edited.Text {
+		return
+	}
+	messages := b.MessageMap.MatrixForSlack(m.Channel, edited.TS)
+	if len(messages) == 0 {
+		log.Printf("Ignoring edit of unknown slack message %q", edited.TS)
+		return
+	}
+	matrixUser := b.matrixUserForSlack(m.Channel, edited.User, matrixRoom)
+	if matrixUser == nil {
+		log.Printf("Ignoring edit from unknown slack user %q", edited.User)
+		return
+	}
+
+	content, _ := b.slackMessageContent(m.Channel, edited, matrixRoom)
+	if edited.Subtype == "me_message" {
+		content.MsgType = "m.emote"
+	}
+	// Any file comment is sent last, and is the text which can be edited.
+	original := messages[len(messages)-1]
+	if _, err := matrixUser.Client.SendMessage(matrixRoom.ID, matrix.NewEdit(original.MatrixEventID, content)); err != nil {
+		log.Printf("Error sending edit to Matrix: %v", err)
+	}
+}
+
+// handleSlackDelete redacts the Matrix events which a deleted slack message
+// was bridged to or from. Slack has already checked that the deletion was
+// allowed.
+func (b *Bridge) handleSlackDelete(m slack.Message, matrixRoom *matrix.Room) {
+	for _, message := range b.MessageMap.MatrixForSlack(m.Channel, m.DeletedTS) {
+		// Our own users can redact their own events, but anyone else's need
+		// the bot's power.
+		client := b.matrixClientFor(message.MatrixSender)
+		if _, err := client.Redact(matrixRoom.ID, message.MatrixEventID); err != nil {
+			log.Printf("Error redacting matrix event %q: %v", message.MatrixEventID, err)
+		}
+	}
+}
+
+// OnSlackReaction annotates the Matrix event which a slack message was bridged
+// to or from, as the reacting user, or redacts the annotation when the
+// reaction is removed.
+func (b *Bridge) OnSlackReaction(r slack.Reaction) {
+	matrixRoom := b.RoomMap.MatrixForSlack(r.Item.Channel)
+	if matrixRoom == nil {
+		return
+	}
+	b.MessageMap.reactionsMu.Lock()
+	defer b.MessageMap.reactionsMu.Unlock()
+	matrixUser := b.matrixUserForSlack(r.Item.Channel, r.User, matrixRoom)
+	if matrixUser == nil {
+		log.Printf("Ignoring reaction from unknown slack user %q", r.User)
+		return
+	}
+
+	if r.Type == "reaction_removed" {
+		reaction := b.MessageMap.Reaction(r.Item.Channel, r.Item.TS, r.User, r.Reaction)
+		if reaction == nil {
+			return
+		}
+		if _, err := matrixUser.Client.Redact(reaction.MatrixRoomID, reaction.MatrixEventID); err != nil {
+			log.Printf("Error redacting matrix reaction %q: %v", reaction.MatrixEventID, err)
+			return
+		}
+		b.MessageMap.RemoveReaction(reaction)
+		return
+	}
+
+	// Every listener in the channel hears about each reaction, including the
+	// ones we add from Matrix.
+	if b.MessageMap.Reaction(r.Item.Channel, r.Item.TS, r.User, r.Reaction) != nil {
+		return
+	}
+	messages := b.MessageMap.MatrixForSlack(r.Item.Channel, r.Item.TS)
+	if len(messages) == 0 {
+		return
+	}
+	eventID, err := matrixUser.Client.SendReaction(matrixRoom.ID, messages[0].MatrixEventID, slackReactionToMatrix(r.Reaction))
+	if err != nil {
+		log.Printf("Error sending reaction to Matrix: %v", err)
+		return
+	}
+	b.MessageMap.AddReaction(store.Reaction{
+		SlackChannelID: r.Item.Channel,
+		SlackTS:        r.Item.TS,
+		SlackUserID:    r.User,
+		Name:           r.Reaction,
+		MatrixRoomID:   matrixRoom.ID,
+		MatrixEventID:  eventID,
+		MatrixSender:   matrixUser.UserID,
+	})
+}
+
+// matrixClientFor returns the client of the bridge's Matrix user matrixUserID,
+// or the bot's client if the bridge doesn't control that user.
+func (b *Bridge) matrixClientFor(matrixUserID string) matrix.Client {
+	if slackUser := b.UserMap.SlackForMatrix(matrixUserID); slackUser != nil {
+		if matrixUser := b.UserMap.MatrixForSlack(slackUser.UserID); matrixUser != nil {
+			return matrixUser.Client
+		}
+	}
+	if b.MatrixUsers != nil {
+		b.MatrixUsers.Mu.Lock()
+		user := b.MatrixUsers.Get_Locked(matrixUserID)
+		b.MatrixUsers.Mu.Unlock()
+		if user != nil {
+			return user.Client
+		}
+	}
+	return b.matrixBotClient()
+}
+
 func (b *Bridge) OnMatrixRoomMember(m matrix.RoomMemberEvent) {
 	room := b.RoomMap.MatrixRoom(m.RoomID)
 	if room == nil {
@@ -118,30 +336,368 @@ func (b *Bridge) OnMatrixRoomMessage(m matrix.RoomMessage) {
 		log.Printf("Error unmarshaling room message content: %v", err)
 		return
 	}
+	if c.RelatesTo != nil && c.RelatesTo.RelType == "m.replace" {
+		if handled := b.handleMatrixEdit(m, c, slackUser); handled {
+			return
+		}
+	}
 	if c.MsgType == "m.image" {
-		if err := b.handleMatrixImage(m, slackChannel, slackUser); err == nil {
+		ts, err := b.handleMatrixImage(m, slackChannel, slackUser)
+		if err == nil {
+			b.MessageMap.Add(slackChannel, ts, m.RoomID, m.EventID, m.UserID)
 			return
-		} else {
-			log.Printf("Error sending image to slack: %v - falling back to text", err)
 		}
+		log.Printf("Error sending image to slack: %v - falling back to text", err)
 	}
 
-	if err := slackUser.Client.SendText(slackChannel, matrixToSlack(c.Body)); err != nil {
+	body := c.Body
+	var quote []string
+	threadTS := b.matrixThreadToSlack(m.RoomID, c.RelatesTo)
+	// Thread events only reply to the latest event for clients which don't
+	// understand threads, and have no fallback quote to strip.
+	isReply := c.RelatesTo != nil && c.RelatesTo.InReplyTo != nil && !c.RelatesTo.IsFallingBack
+	if isReply {
+		// Replies are sent in a thread on the original if we can, so the
+		// quote of it in the fallback is just noise.
+		quote, body = splitReplyFallback(c.Body, c.FormattedBody)
+	}
+	text := b.matrixToSlackText(slackChannel, m.RoomID, m.UserID, body, &c)
+	if isReply && threadTS == "" {
+		inReplyTo := c.RelatesTo.InReplyTo.EventID
+		if original := b.MessageMap.SlackForMatrix(m.RoomID, inReplyTo); original != nil {
+			threadTS = original.SlackTS
+		} else {
+			text = slackQuote(matrixToLink(m.RoomID, inReplyTo), quote) + text
+		}
+	}
+	var ts string
+	var err error
+	if threadTS != "" {
+		ts, err = slackUser.Client.SendThreadText(slackChannel, threadTS, text)
+	} else {
+		ts, err = slackUser.Client.SendText(slackChannel, text)
+	}
+	if err != nil {
 		log.Printf("Error sending text to Slack: %v", err)
 	}
+	b.MessageMap.Add(slackChannel, ts, m.RoomID, m.EventID, m.UserID)
+}
+
+var matrixRoomPing = regexp.MustCompile(`\B@room\b`)
+
+// matrixToSlackText converts body, the plain text of c, to slack text in which
+// the Matrix users whom c mentions are mentioned as their slack users, and
+// links to bridged rooms refer to their slack channels. A room ping from
+// someone allowed to notify the room pings the slack channel.
+func (b *Bridge) matrixToSlackText(slackChannel, matrixRoomID, sender, body string, c *matrix.TextMessageContent) string {
+	text := b.matrixToSlackLinks(slackChannel, matrixRoomID, body, c)
+	ping := c.Mentions != nil && c.Mentions.Room || c.Mentions == nil && matrixRoomPing.MatchString(body)
+	if !ping || !b.canNotifyRoom(matrixRoomID, sender) {
+		return text
+	}
+	if matrixRoomPing.MatchString(text) {
+		return matrixRoomPing.ReplaceAllLiteralString(text, "<!channel>")
+	}
+	return "<!channel> " + text
+}
+
+func (b *Bridge) matrixToSlackLinks(slackChannel, matrixRoomID, body string, c *matrix.TextMessageContent) string {
+	formatted := ""
+	if c.Format == "org.matrix.custom.html" {
+		formatted = c.FormattedBody
+	}
+	links := matrixLinks(body, formatted)
+	var mentioned []matrixLink
+	if c.Mentions != nil {
+		room := b.RoomMap.MatrixRoom(matrixRoomID)
+		for _, userID := range c.Mentions.UserIDs {
+			linked := false
+			for _, link := range links {
+				linked = linked || link.Target == userID
+			}
+			if !linked && room != nil {
+				mentioned = append(mentioned, matrixLink{Target: userID, Text: room.Users[userID].DisplayName})
+			}
+		}
+	}
+	resolved := make(map[string]string)
+	resolve := func(target string) string {
+		if reference, ok := resolved[target]; ok {
+			return reference
+		}
+		reference := b.slackReferenceFor(slackChannel, target)
+		resolved[target] = reference
+		return reference
+	}
+	if formatted != "" {
+		text, err := htmlToMrkdwn(formatted, resolve)
+		if err != nil {
+			log.Printf("Error converting matrix HTML to slack: %v - using plain body", err)
+		} else if text != "" {
+			return replaceMatrixLinks(text, mentioned, resolve)
+		}
+	}
+	links = append(links, mentioned...)
+	if len(links) == 0 {
+		return matrixToSlack(body)
+	}
+	return replaceMatrixLinks(matrixToSlack(body), links, resolve)
+}
+
+// slackReferenceFor returns the slack mention of a Matrix user, or the slack
+// reference to the channel bridged to a Matrix room ID or alias, or "" if
+// there's nothing on slack which target stands for.
+func (b *Bridge) slackReferenceFor(slackChannel, target string) string {
+	switch {
+	case strings.HasPrefix(target, "@"):
+		if slackUserID := b.slackUserIDFor(slackChannel, target); slackUserID != "" {
+			return "<@" + slackUserID + ">"
+		}
+	case strings.HasPrefix(target, "#"):
+		roomID, err := b.matrixBotClient().ResolveAlias(target)
+		if err != nil {
+			log.Printf("Error resolving matrix alias %q: %v", target, err)
+			return ""
+		}
+		if channel := b.RoomMap.SlackForMatrix(roomID); channel != "" {
+			return "<#" + channel + ">"
+		}
+	case strings.HasPrefix(target, "!"):
+		if channel := b.RoomMap.SlackForMatrix(target); channel != "" {
+			return "<#" + channel + ">"
+		}
+	}
+	return ""
 }
 
-func (b *Bridge) handleMatrixImage(m matrix.RoomMessage, slackChannel string, slackUser *slack.User) error {
+// slackUserIDFor returns the ID of the slack user which matrixUserID is linked
+// to, or which it is the ghost of, or "" if there is none.
+func (b *Bridge) slackUserIDFor(slackChannel, matrixUserID string) string {
+	if slackUser := b.UserMap.SlackForMatrix(matrixUserID); slackUser != nil {
+		return slackUser.UserID
+	}
+	suffix := ":" + b.Config.HomeserverName
+	if b.Config.UserPrefix == "" || !strings.HasPrefix(matrixUserID, b.Config.UserPrefix) || !strings.HasSuffix(matrixUserID, suffix) {
+		return ""
+	}
+	if slackUserID := b.UserMap.SlackForGhost(matrixUserID); slackUserID != "" {
+		return slackUserID
+	}
+	name := matrixUserID[len(b.Config.UserPrefix) : len(matrixUserID)-len(suffix)]
+	return b.slackUserIDForName(slackChannel, name)
+}
+
+// matrixThreadToSlack returns the timestamp of the slack message which the
+// root of a matrix thread was bridged to or from, or "" if the event isn't in
+// a bridged thread.
+func (b *Bridge) matrixThreadToSlack(matrixRoomID string, relatesTo *matrix.RelatesTo) string {
+	if relatesTo == nil || relatesTo.RelType != "m.thread" {
+		return ""
+	}
+	root := b.MessageMap.SlackForMatrix(matrixRoomID, relatesTo.EventID)
+	if root == nil {
+		log.Printf("No slack message for matrix thread %q - sending reply to the channel", relatesTo.EventID)
+		return ""
+	}
+	return root.SlackTS
+}
+
+func (b *Bridge) handleMatrixImage(m matrix.RoomMessage, slackChannel string, slackUser *slack.User) (string, error) {
 	var c matrix.ImageMessageContent
 	if err := json.Unmarshal(m.Content, &c); err != nil {
-		return fmt.Errorf("Error unmarshaling room message content: %v", err)
+		return "", fmt.Errorf("Error unmarshaling room message content: %v", err)
 	}
 	return slackUser.Client.SendImage(slackChannel, matrixToSlack(c.Body), b.mxcToHTTPS(c.URL))
 }
 
+// handleMatrixEdit updates the slack message which the edited matrix event was
+// bridged to or from. If it can't, the edit's fallback body should be sent as
+// a new message instead.
+func (b *Bridge) handleMatrixEdit(m matrix.RoomMessage, c matrix.TextMessageContent, slackUser *slack.User) bool {
+	original := b.MessageMap.SlackForMatrix(m.RoomID, c.RelatesTo.EventID)
+	if original == nil {
+		log.Printf("No slack message for edited matrix event %q - sending edit as a new message", c.RelatesTo.EventID)
+		return false
+	}
+	// Matrix ignores edits by anyone but the original sender, and unlinked
+	// users all share a slack token which could update anyone's message.
+	if m.UserID != original.MatrixSender {
+		log.Printf("Ignoring edit by %q of matrix event %q sent by %q", m.UserID, c.RelatesTo.EventID, original.MatrixSender)
+		return true
+	}
+	content := &c
+	if c.NewContent != nil {
+		content = c.NewContent
+	}
+	text := b.matrixToSlackText(original.SlackChannelID, m.RoomID, m.UserID, content.Body, content)
+	if err := slackUser.Client.UpdateText(original.SlackChannelID, original.SlackTS, text); err != nil {
+		log.Printf("Error updating slack message %q: %v - sending edit as a new message", original.SlackTS, err)
+		return false
+	}
+	return true
+}
+
+// OnMatrixReaction reacts to the slack message which the annotated matrix
+// event was bridged to or from. Slack reactions can't say who they're from
+// unless we can react as them, so reactions from unlinked users, and emoji
+// which Slack has no name for, are sent as a reply in the message's thread.
+func (b *Bridge) OnMatrixReaction(r matrix.Reaction) {
+	slackChannel := b.RoomMap.SlackForMatrix(r.RoomID)
+	if slackChannel == "" {
+		log.Printf("Ignoring reaction for unknown matrix room %q", r.RoomID)
+		return
+	}
+	relatesTo := r.Content.RelatesTo
+	if relatesTo == nil || relatesTo.RelType != "m.annotation" {
+		return
+	}
+	original := b.MessageMap.SlackForMatrix(r.RoomID, relatesTo.EventID)
+	if original == nil {
+		log.Printf("Ignoring reaction to unbridged matrix event %q", relatesTo.EventID)
+		return
+	}
+	slackUser := b.UserMap.SlackForMatrix(r.UserID)
+	if slackUser != nil {
+		if name, ok := matrixReactionToSlack(relatesTo.Key); ok {
+			b.MessageMap.reactionsMu.Lock()
+			defer b.MessageMap.reactionsMu.Unlock()
+			err := slackUser.Client.AddReaction(original.SlackChannelID, original.SlackTS, name)
+			if err == nil {
+				b.MessageMap.AddReaction(store.Reaction{
+					SlackChannelID: original.SlackChannelID,
+					SlackTS:        original.SlackTS,
+					SlackUserID:    slackUser.UserID,
+					Name:           name,
+					MatrixRoomID:   r.RoomID,
+					MatrixEventID:  r.EventID,
+					MatrixSender:   r.UserID,
+				})
+				return
+			}
+			log.Printf("Error adding slack reaction %q: %v - sending it as a reply", name, err)
+		}
+	} else {
+		slackUser = b.slackUserFor(slackChannel, r.UserID)
+	}
+	if slackUser == nil {
+		log.Printf("Ignoring reaction from unknown matrix user %q", r.UserID)
+		return
+	}
+	ts, err := slackUser.Client.SendThreadText(original.SlackChannelID, original.SlackTS, matrixToSlack("reacted with "+relatesTo.Key))
+	if err != nil {
+		log.Printf("Error sending reaction to Slack: %v", err)
+		return
+	}
+	// The reply is recorded as a reaction rather than a message, so that
+	// redacting the reaction deletes it but nothing from Slack can target the
+	// reaction as if it were a message.
+	b.MessageMap.AddReaction(store.Reaction{
+		SlackChannelID: original.SlackChannelID,
+		SlackTS:        ts,
+		MatrixRoomID:   r.RoomID,
+		MatrixEventID:  r.EventID,
+		MatrixSender:   r.UserID,
+		Note:           true,
+	})
+}
+
+// handleMatrixReactionRedaction removes the slack reaction which a redacted
+// matrix annotation was bridged to or from. We can only remove reactions which
+// we can make as the user who reacted.
+func (b *Bridge) handleMatrixReactionRedaction(r matrix.Redaction, reaction *store.Reaction) {
+	matrixUser := b.UserMap.MatrixForSlack(reaction.SlackUserID)
+	if matrixUser == nil {
+		log.Printf("Not removing slack reaction by unlinked user %q", reaction.SlackUserID)
+		return
+	}
+	if r.UserID != matrixUser.UserID && !b.canRedact(r.RoomID, r.UserID) {
+		log.Printf("Not removing slack reaction: %q may not redact %q's events", r.UserID, matrixUser.UserID)
+		return
+	}
+	slackUser := b.UserMap.SlackForMatrix(matrixUser.UserID)
+	if slackUser == nil {
+		return
+	}
+	b.MessageMap.reactionsMu.Lock()
+	defer b.MessageMap.reactionsMu.Unlock()
+	if err := slackUser.Client.RemoveReaction(reaction.SlackChannelID, reaction.SlackTS, reaction.Name); err != nil {
+		log.Printf("Error removing slack reaction %q: %v", reaction.Name, err)
+		return
+	}
+	b.MessageMap.RemoveReaction(reaction)
+}
+
+// OnMatrixRedaction deletes the slack message or reaction which a redacted
+// matrix event was bridged to or from. Only the event's sender, or someone
+// with the power to redact other people's events, may delete it from Slack.
+func (b *Bridge) OnMatrixRedaction(r matrix.Redaction) {
+	slackChannel := b.RoomMap.SlackForMatrix(r.RoomID)
+	if slackChannel == "" {
+		log.Printf("Ignoring redaction for unknown matrix room %q", r.RoomID)
+		return
+	}
+	reaction := b.MessageMap.ReactionForMatrix(r.RoomID, r.Redacts)
+	if reaction != nil && !reaction.Note {
+		b.handleMatrixReactionRedaction(r, reaction)
+		return
+	}
+	original := b.MessageMap.SlackForMatrix(r.RoomID, r.Redacts)
+	if reaction != nil {
+		// The reaction was sent as a reply, which is deleted instead.
+		original = &store.Message{
+			SlackChannelID: reaction.SlackChannelID,
+			SlackTS:        reaction.SlackTS,
+			MatrixRoomID:   reaction.MatrixRoomID,
+			MatrixEventID:  reaction.MatrixEventID,
+			MatrixSender:   reaction.MatrixSender,
+		}
+	}
+	if original == nil {
+		log.Printf("Ignoring redaction of unbridged matrix event %q", r.Redacts)
+		return
+	}
+	if r.UserID != original.MatrixSender && !b.canRedact(r.RoomID, r.UserID) {
+		log.Printf("Not deleting slack message %q: %q may not redact %q's events", original.SlackTS, r.UserID, original.MatrixSender)
+		return
+	}
+	slackUser := b.UserMap.SlackForMatrix(r.UserID)
+	if slackUser == nil {
+		slackUser = b.slackUserFor(slackChannel, r.UserID)
+	}
+	if slackUser == nil {
+		log.Printf("Ignoring redaction from unknown matrix user %q", r.UserID)
+		return
+	}
+	if err := slackUser.Client.Delete(original.SlackChannelID, original.SlackTS); err != nil {
+		log.Printf("Error deleting slack message %q: %v", original.SlackTS, err)
+		return
+	}
+	if reaction != nil {
+		b.MessageMap.RemoveReaction(reaction)
+	}
+}
+
+func (b *Bridge) canRedact(matrixRoomID, matrixUserID string) bool {
+	powerLevels, err := b.matrixBotClient().PowerLevels(matrixRoomID)
+	if err != nil {
+		log.Printf("Error getting power levels for %q: %v", matrixRoomID, err)
+		return false
+	}
+	return powerLevels.UserLevel(matrixUserID) >= powerLevels.RedactLevel()
+}
+
+func (b *Bridge) canNotifyRoom(matrixRoomID, matrixUserID string) bool {
+	powerLevels, err := b.matrixBotClient().PowerLevels(matrixRoomID)
+	if err != nil {
+		log.Printf("Error getting power levels for %q: %v", matrixRoomID, err)
+		return false
+	}
+	return powerLevels.UserLevel(matrixUserID) >= powerLevels.RoomNotificationLevel()
+}
+
 func (b *Bridge) slackUserFor(slackChannel, matrixUserID string) *slack.User {
-	token := b.botAccessToken(slackChannel)
-	if token == "" {
+	member := b.SlackRoomMembers.Any(slackChannel)
+	if member == nil || member.Client.AccessToken() == "" {
 		return nil
 	}
 
@@ -154,20 +710,12 @@ func (b *Bridge) slackUserFor(slackChannel, matrixUserID string) *slack.User {
 		displayName = userInfo.DisplayName
 	}
 
-	client := slack.NewBotClient(token, matrixUserID, displayName, iconURL, b.Client, b.RoomMap.ShouldNotify)
+	client := slack.NewBotClient(member.Client, matrixUserID, displayName, iconURL, b.Client, b.RoomMap.ShouldNotify)
 	user := &slack.User{matrixUserID, client}
 	b.SlackRoomMembers.Add(slackChannel, user)
 	return user
 }
 
-func (b *Bridge) botAccessToken(slackChannel string) string {
-	user := b.SlackRoomMembers.Any(slackChannel)
-	if user == nil {
-		return ""
-	}
-	return user.Client.AccessToken()
-}
-
 func (b *Bridge) mxcToHTTPS(url string) string {
 	if !strings.HasPrefix(url, "mxc://") {
 		return url
@@ -176,34 +724,15 @@ func (b *Bridge) mxcToHTTPS(url string) string {
 }
 
 func (b *Bridge) matrixUserFor(slackChannel, slackUserID string, matrixRoom *matrix.Room) *matrix.User {
-	slackUserInRoom := b.SlackRoomMembers.Any(slackChannel)
-	if slackUserInRoom == nil {
-		return nil
-	}
-	resp, err := b.Client.Get(fmt.Sprintf("https://slack.com/api/users.info?token=%s&user=%s", slackUserInRoom.Client.AccessToken(), slackUserID))
-	if err != nil {
-		log.Printf("Error looking up user %q: %v", slackUserID, err)
-		return nil
-	}
-	defer resp.Body.Close()
-	respBytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		log.Printf("Error reading user info response: %v", err)
-		return nil
-	}
-	var r slackUserInfoResponse
-	if err := json.Unmarshal(respBytes, &r); err != nil {
-		log.Printf("Error unmarshaling user info response: %v (%s)", err, string(respBytes))
-		return nil
-	}
-
-	if r.User == nil {
+	info := b.slackUserInfo(slackChannel, slackUserID)
+	if info == nil {
 		log.Printf("Ignoring slack message from non-slack user - probably our own Matrix bot")
 		return nil
 	}
 
 	b.MatrixUsers.Mu.Lock()
-	matrixUserID := b.Config.UserPrefix + r.User.Name + ":" + b.Config.HomeserverName
+	matrixUserID := b.ghostUserID(info.Name)
+	b.UserMap.AddGhost(matrixUserID, slackUserID)
 	user := b.MatrixUsers.Get_Locked(matrixUserID)
 	if user == nil {
 		client := matrix.NewBotClient(b.Config.MatrixASAccessToken, matrixUserID, b.Client, b.Config.HomeserverBaseURL, b.MatrixEchoSuppresser)
@@ -231,11 +760,202 @@ func (b *Bridge) matrixBotClient() matrix.Client {
 	return matrix.NewClient(b.Config.MatrixASAccessToken, b.Client, b.Config.HomeserverBaseURL, b.MatrixEchoSuppresser)
 }
 
+// slackUserInfo looks up a slack user as someone in slackChannel, returning
+// nil if there's no such user.
+func (b *Bridge) slackUserInfo(slackChannel, slackUserID string) *slackUser {
+	slackUserInRoom := b.SlackRoomMembers.Any(slackChannel)
+	if slackUserInRoom == nil {
+		return nil
+	}
+	resp, err := b.Client.Get(fmt.Sprintf("https://slack.com/api/users.info?token=%s&user=%s", slackUserInRoom.Client.AccessToken(), slackUserID))
+	if err != nil {
+		log.Printf("Error looking up user %q: %v", slackUserID, err)
+		return nil
+	}
+	defer resp.Body.Close()
+	respBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		log.Printf("Error reading user info response: %v", err)
+		return nil
+	}
+	var r slackUserInfoResponse
+	if err := json.Unmarshal(respBytes, &r); err != nil {
+		log.Printf("Error unmarshaling user info response: %v (%s)", err, string(respBytes))
+		return nil
+	}
+	return r.User
+}
+
+// slackChannelName looks up the name of a slack channel as someone in
+// slackChannel, returning its ID if the name can't be found.
+func (b *Bridge) slackChannelName(slackChannel, slackChannelID string) string {
+	slackUserInRoom := b.SlackRoomMembers.Any(slackChannel)
+	if slackUserInRoom == nil {
+		return slackChannelID
+	}
+	resp, err := b.Client.Get(fmt.Sprintf("https://slack.com/api/conversations.info?token=%s&channel=%s", slackUserInRoom.Client.AccessToken(), slackChannelID))
+	if err != nil {
+		log.Printf("Error looking up channel %q: %v", slackChannelID, err)
+		return slackChannelID
+	}
+	defer resp.Body.Close()
+	respBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		log.Printf("Error reading channel info response: %v", err)
+		return slackChannelID
+	}
+	var r slackConversationInfoResponse
+	if err := json.Unmarshal(respBytes, &r); err != nil {
+		log.Printf("Error unmarshaling channel info response: %v (%s)", err, string(respBytes))
+		return slackChannelID
+	}
+	if r.Channel.Name == "" {
+		return slackChannelID
+	}
+	return r.Channel.Name
+}
+
+// slackUserIDForName looks up the ID of the slack user called name, as someone
+// in slackChannel. Slack can only list every user, so this is slow, but every
+// user seen along the way is remembered for next time.
+func (b *Bridge) slackUserIDForName(slackChannel, name string) string {
+	slackUserInRoom := b.SlackRoomMembers.Any(slackChannel)
+	if slackUserInRoom == nil {
+		return ""
+	}
+	cursor := ""
+	for {
+		resp, err := b.Client.Get(fmt.Sprintf("https://slack.com/api/users.list?token=%s&cursor=%s", slackUserInRoom.Client.AccessToken(), url.QueryEscape(cursor)))
+		if err != nil {
+			log.Printf("Error listing users: %v", err)
+			return ""
+		}
+		var r slackUsersListResponse
+		err = json.NewDecoder(resp.Body).Decode(&r)
+		resp.Body.Close()
+		if err != nil {
+			log.Printf("Error unmarshaling users list response: %v", err)
+			return ""
+		}
+		for _, member := range r.Members {
+			b.UserMap.AddGhost(b.ghostUserID(member.Name), member.ID)
+			if member.Name == name {
+				return member.ID
+			}
+		}
+		if r.ResponseMetadata.NextCursor == "" {
+			return ""
+		}
+		cursor = r.ResponseMetadata.NextCursor
+	}
+}
+
+// ghostUserID returns the ID of the Matrix user which the bridge controls on
+// behalf of the unlinked slack user called name.
+func (b *Bridge) ghostUserID(name string) string {
+	return b.Config.UserPrefix + name + ":" + b.Config.HomeserverName
+}
+
+// matrixMention resolves a mention of a slack user to the Matrix user which
+// speaks for them in matrixRoom. label is the name which slack sometimes
+// gives with the mention.
+func (b *Bridge) matrixMention(slackChannel, slackUserID, label string, matrixRoom *matrix.Room) *matrixMention {
+	var matrixUserID, name string
+	if matrixUser := b.UserMap.MatrixForSlack(slackUserID); matrixUser != nil {
+		matrixUserID = matrixUser.UserID
+	} else if info := b.slackUserInfo(slackChannel, slackUserID); info != nil {
+		matrixUserID = b.ghostUserID(info.Name)
+		name = info.Name
+	} else {
+		return nil
+	}
+	if displayName := matrixRoom.Users[matrixUserID].DisplayName; displayName != "" {
+		name = displayName
+	} else if label != "" {
+		name = label
+	} else if name == "" {
+		name = matrixUserID
+	}
+	return &matrixMention{UserID: matrixUserID, DisplayName: name}
+}
+
+// slackMessageContent converts a slack message to Matrix message content,
+// rendering its blocks instead of its text if they have more in them, followed
+// by its attachments. The images in the blocks and attachments are returned to
+// be sent separately.
+func (b *Bridge) slackMessageContent(slackChannel string, m *slack.Message, matrixRoom *matrix.Room) (*matrix.TextMessageContent, []slackImage) {
+	var content *matrix.TextMessageContent
+	var images []slackImage
+	if m.Text == "" || hasLayoutBlocks(m.Blocks) {
+		content, images = b.slackBlocksToMatrix(slackChannel, m.Blocks, matrixRoom)
+	}
+	if content == nil || content.Body == "" && len(images) == 0 {
+		content, images = b.slackToMatrixContent(slackChannel, m.Text, matrixRoom), nil
+	}
+	if len(m.Attachments) > 0 {
+		attachments, attachmentImages := b.slackAttachmentsToMatrix(slackChannel, m.Attachments, matrixRoom)
+		content = joinMatrixContent(content, attachments)
+		images = append(images, attachmentImages...)
+	}
+	return content, images
+}
+
+// slackToMatrixContent converts slack text to Matrix message content, with
+// mentions of slack users as pills which notify the mentioned Matrix users, and
+// references to bridged channels as links to their Matrix rooms.
+func (b *Bridge) slackToMatrixContent(slackChannel, text string, matrixRoom *matrix.Room) *matrix.TextMessageContent {
+	body, formatted, mentions := slackToMatrixWithLinks(text, func(slackUserID, label string) *matrixMention {
+		return b.matrixMention(slackChannel, slackUserID, label, matrixRoom)
+	}, b.matrixRoomLink)
+	content := &matrix.TextMessageContent{
+		Body:    body,
+		MsgType: "m.text",
+	}
+	if formatted != "" {
+		content.Format = "org.matrix.custom.html"
+		content.FormattedBody = formatted
+	}
+	content.Mentions = mentions
+	return content
+}
+
+// matrixRoomLink returns the alias of the Matrix room bridged to slackChannel,
+// or its ID if it has no alias, or "" if the channel isn't bridged.
+func (b *Bridge) matrixRoomLink(slackChannel string) string {
+	room := b.RoomMap.MatrixForSlack(slackChannel)
+	if room == nil {
+		return ""
+	}
+	alias, err := b.matrixBotClient().CanonicalAlias(room.ID)
+	if err != nil {
+		log.Printf("Error getting alias of matrix room %q: %v", room.ID, err)
+	}
+	if alias != "" {
+		return alias
+	}
+	return room.ID
+}
+
 type slackUserInfoResponse struct {
 	OK   bool       `json:"ok"`
 	User *slackUser `json:"user"`
 }
 
+type slackConversationInfoResponse struct {
+	OK      bool `json:"ok"`
+	Channel struct {
+		Name string `json:"name"`
+	} `json:"channel"`
+}
+
+type slackUsersListResponse struct {
+	OK               bool         `json:"ok"`
+	Members          []*slackUser `json:"members"`
+	ResponseMetadata struct {
+		NextCursor string `json:"next_cursor"`
+	} `json:"response_metadata"`
+}
+
 type slackUser struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
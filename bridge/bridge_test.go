@@ -1,13 +1,16 @@
 package bridge
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"path"
 	"reflect"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -15,6 +18,7 @@ import (
 	"github.com/matrix-org/slackbridge/common"
 	"github.com/matrix-org/slackbridge/matrix"
 	"github.com/matrix-org/slackbridge/slack"
+	"github.com/matrix-org/slackbridge/store"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -23,7 +27,7 @@ func TestSlackMessage(t *testing.T) {
 	mockMatrixClient := &MockMatrixClient{}
 	mockSlackClient := &MockSlackClient{}
 
-	db := makeDB(t)
+	db := makeStore(t)
 	rooms, err := NewRoomMap(db)
 	if err != nil {
 		t.Fatal(err)
@@ -39,7 +43,7 @@ func TestSlackMessage(t *testing.T) {
 	slackUser := &slack.User{"U34", mockSlackClient}
 	users.Link(matrixUser, slackUser)
 
-	bridge := Bridge{users, rooms, nil, nil, http.Client{}, echoSuppresser, Config{}}
+	bridge := Bridge{users, rooms, NewMessageMap(db), nil, nil, http.Client{}, echoSuppresser, Config{}}
 	bridge.OnSlackMessage(slack.Message{
 		Type:    "message",
 		Channel: "CANTINA",
@@ -47,7 +51,688 @@ func TestSlackMessage(t *testing.T) {
 		Text:    "Take more chances",
 	})
 
-	want := []call{call{"SendText", []interface{}{"!abc123:matrix.org", "Take more chances"}}}
+	want := []call{call{"SendText", []interface{}{"!abc123:matrix.org", "Take more chances", ""}}}
+	if !reflect.DeepEqual(mockMatrixClient.calls, want) {
+		t.Fatalf("Wrong Matrix calls, want %v got %v", want, mockMatrixClient.calls)
+	}
+}
+
+func TestSlackEdit(t *testing.T) {
+	mockMatrixClient := &MockMatrixClient{}
+	mockSlackClient := &MockSlackClient{}
+
+	db := makeStore(t)
+	rooms, err := NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "CANTINA")
+
+	echoSuppresser := common.NewEchoSuppresser()
+	users, err := NewUserMap(db, http.Client{}, rooms, echoSuppresser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matrixUser := matrix.NewUser("@nancy:st.andrews", mockMatrixClient)
+	slackUser := &slack.User{"U34", mockSlackClient}
+	users.Link(matrixUser, slackUser)
+
+	bridge := Bridge{users, rooms, NewMessageMap(db), nil, nil, http.Client{}, echoSuppresser, Config{}}
+	original := slack.Message{
+		Type:    "message",
+		Channel: "CANTINA",
+		User:    "U34",
+		Text:    "Take more chances",
+		TS:      "1.000",
+	}
+	bridge.OnSlackMessage(original)
+	edited := original
+	edited.Text = "Take fewer chances"
+	bridge.OnSlackMessage(slack.Message{
+		Type:            "message",
+		Subtype:         "message_changed",
+		Channel:         "CANTINA",
+		TS:              "2.000",
+		Message:         &edited,
+		PreviousMessage: &original,
+	})
+	// Unfurling a link doesn't change the text, so isn't an edit.
+	bridge.OnSlackMessage(slack.Message{
+		Type:            "message",
+		Subtype:         "message_changed",
+		Channel:         "CANTINA",
+		TS:              "3.000",
+		Message:         &edited,
+		PreviousMessage: &edited,
+	})
+
+	want := []call{
+		call{"SendText", []interface{}{"!abc123:matrix.org", "Take more chances", ""}},
+		call{"SendMessage", []interface{}{"!abc123:matrix.org", matrix.NewEdit("$event1", &matrix.TextMessageContent{
+			Body:    "Take fewer chances",
+			MsgType: "m.text",
+		})}},
+	}
+	if !reflect.DeepEqual(mockMatrixClient.calls, want) {
+		t.Fatalf("Wrong Matrix calls, want %v got %v", want, mockMatrixClient.calls)
+	}
+}
+
+func TestSlackDelete(t *testing.T) {
+	mockMatrixClient := &MockMatrixClient{}
+	mockSlackClient := &MockSlackClient{}
+
+	db := makeStore(t)
+	rooms, err := NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "CANTINA")
+
+	echoSuppresser := common.NewEchoSuppresser()
+	users, err := NewUserMap(db, http.Client{}, rooms, echoSuppresser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matrixUser := matrix.NewUser("@nancy:st.andrews", mockMatrixClient)
+	slackUser := &slack.User{"U34", mockSlackClient}
+	users.Link(matrixUser, slackUser)
+
+	bridge := Bridge{users, rooms, NewMessageMap(db), nil, nil, http.Client{}, echoSuppresser, Config{}}
+	original := slack.Message{
+		Type:    "message",
+		Channel: "CANTINA",
+		User:    "U34",
+		Text:    "Take more chances",
+		TS:      "1.000",
+	}
+	bridge.OnSlackMessage(original)
+	bridge.OnSlackMessage(slack.Message{
+		Type:            "message",
+		Subtype:         "message_deleted",
+		Channel:         "CANTINA",
+		TS:              "2.000",
+		DeletedTS:       "1.000",
+		PreviousMessage: &original,
+	})
+
+	want := []call{
+		call{"SendText", []interface{}{"!abc123:matrix.org", "Take more chances", ""}},
+		call{"Redact", []interface{}{"!abc123:matrix.org", "$event1"}},
+	}
+	if !reflect.DeepEqual(mockMatrixClient.calls, want) {
+		t.Fatalf("Wrong Matrix calls, want %v got %v", want, mockMatrixClient.calls)
+	}
+}
+
+func TestSlackReaction(t *testing.T) {
+	mockMatrixClient := &MockMatrixClient{}
+	mockSlackClient := &MockSlackClient{}
+
+	db := makeStore(t)
+	rooms, err := NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "CANTINA")
+
+	echoSuppresser := common.NewEchoSuppresser()
+	users, err := NewUserMap(db, http.Client{}, rooms, echoSuppresser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matrixUser := matrix.NewUser("@nancy:st.andrews", mockMatrixClient)
+	slackUser := &slack.User{"U34", mockSlackClient}
+	users.Link(matrixUser, slackUser)
+
+	bridge := Bridge{users, rooms, NewMessageMap(db), nil, nil, http.Client{}, echoSuppresser, Config{}}
+	bridge.OnSlackMessage(slack.Message{
+		Type:    "message",
+		Channel: "CANTINA",
+		User:    "U34",
+		Text:    "Take more chances",
+		TS:      "1.000",
+	})
+	item := slack.ReactionItem{Type: "message", Channel: "CANTINA", TS: "1.000"}
+	bridge.OnSlackReaction(slack.Reaction{Type: "reaction_added", User: "U34", Reaction: "+1::skin-tone-2", Item: item})
+	bridge.OnSlackReaction(slack.Reaction{Type: "reaction_added", User: "U34", Reaction: "godzillavodka", Item: item})
+	bridge.OnSlackReaction(slack.Reaction{Type: "reaction_removed", User: "U34", Reaction: "+1::skin-tone-2", Item: item})
+	bridge.OnSlackReaction(slack.Reaction{Type: "reaction_removed", User: "U34", Reaction: "+1::skin-tone-2", Item: item})
+
+	want := []call{
+		call{"SendText", []interface{}{"!abc123:matrix.org", "Take more chances", ""}},
+		call{"SendReaction", []interface{}{"!abc123:matrix.org", "$event1", "👍🏻"}},
+		call{"SendReaction", []interface{}{"!abc123:matrix.org", "$event1", ":godzillavodka:"}},
+		call{"Redact", []interface{}{"!abc123:matrix.org", "$event2"}},
+	}
+	if !reflect.DeepEqual(mockMatrixClient.calls, want) {
+		t.Fatalf("Wrong Matrix calls, want %v got %v", want, mockMatrixClient.calls)
+	}
+}
+
+// slowReactionClient takes a while to send reactions, so that concurrent
+// handlers overlap.
+type slowReactionClient struct {
+	*MockMatrixClient
+}
+
+func (c slowReactionClient) SendReaction(roomID, eventID, key string) (string, error) {
+	time.Sleep(10 * time.Millisecond)
+	return c.MockMatrixClient.SendReaction(roomID, eventID, key)
+}
+
+func TestSlackReactionHeardByEveryListener(t *testing.T) {
+	mockMatrixClient := &MockMatrixClient{}
+	db := makeStore(t)
+	bridge := makeBridge(t, db)
+	bridge.UserMap.Link(matrix.NewUser("@vivian:st.andrews", slowReactionClient{mockMatrixClient}), &slack.User{"U35", &MockSlackClient{}})
+	bridge.MessageMap.Add("CANTINA", "1.000", "!abc123:matrix.org", "$event1", "@nancy:st.andrews")
+
+	// In RTM mode each linked user's listener gets the same reaction_added.
+	reaction := slack.Reaction{
+		Type:     "reaction_added",
+		User:     "U35",
+		Reaction: "fire",
+		Item:     slack.ReactionItem{Type: "message", Channel: "CANTINA", TS: "1.000"},
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bridge.OnSlackReaction(reaction)
+		}()
+	}
+	wg.Wait()
+
+	want := []call{
+		call{"SendReaction", []interface{}{"!abc123:matrix.org", "$event1", "🔥"}},
+	}
+	if !reflect.DeepEqual(mockMatrixClient.calls, want) {
+		t.Fatalf("Wrong Matrix calls, want %v got %v", want, mockMatrixClient.calls)
+	}
+}
+
+func TestSlackThread(t *testing.T) {
+	mockMatrixClient := &MockMatrixClient{}
+	mockSlackClient := &MockSlackClient{}
+
+	db := makeStore(t)
+	rooms, err := NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "CANTINA")
+
+	echoSuppresser := common.NewEchoSuppresser()
+	users, err := NewUserMap(db, http.Client{}, rooms, echoSuppresser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matrixUser := matrix.NewUser("@nancy:st.andrews", mockMatrixClient)
+	slackUser := &slack.User{"U34", mockSlackClient}
+	users.Link(matrixUser, slackUser)
+
+	bridge := Bridge{users, rooms, NewMessageMap(db), nil, nil, http.Client{}, echoSuppresser, Config{}}
+	bridge.OnSlackMessage(slack.Message{
+		Type:    "message",
+		Channel: "CANTINA",
+		User:    "U34",
+		Text:    "Take more chances",
+		TS:      "1.000",
+	})
+	bridge.OnSlackMessage(slack.Message{
+		Type:     "message",
+		Channel:  "CANTINA",
+		User:     "U34",
+		Text:     "Dance more dances",
+		TS:       "2.000",
+		ThreadTS: "1.000",
+	})
+	bridge.OnSlackMessage(slack.Message{
+		Type:     "message",
+		Subtype:  "thread_broadcast",
+		Channel:  "CANTINA",
+		User:     "U34",
+		Text:     "Take more chances, dance more dances",
+		TS:       "3.000",
+		ThreadTS: "1.000",
+	})
+
+	want := []call{
+		call{"SendText", []interface{}{"!abc123:matrix.org", "Take more chances", ""}},
+		call{"SendMessage", []interface{}{"!abc123:matrix.org", &matrix.TextMessageContent{
+			Body:    "Dance more dances",
+			MsgType: "m.text",
+			RelatesTo: &matrix.RelatesTo{
+				RelType:       "m.thread",
+				EventID:       "$event1",
+				InReplyTo:     &matrix.InReplyTo{EventID: "$event1"},
+				IsFallingBack: true,
+			},
+		}}},
+		call{"SendMessage", []interface{}{"!abc123:matrix.org", &matrix.TextMessageContent{
+			Body:    "Take more chances, dance more dances",
+			MsgType: "m.text",
+			RelatesTo: &matrix.RelatesTo{
+				InReplyTo: &matrix.InReplyTo{EventID: "$event1"},
+			},
+		}}},
+	}
+	if !reflect.DeepEqual(mockMatrixClient.calls, want) {
+		t.Fatalf("Wrong Matrix calls, want %v got %v", want, mockMatrixClient.calls)
+	}
+}
+
+func TestSlackReply(t *testing.T) {
+	mockMatrixClient := &MockMatrixClient{}
+	mockSlackClient := &MockSlackClient{}
+
+	db := makeStore(t)
+	rooms, err := NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "CANTINA")
+
+	echoSuppresser := common.NewEchoSuppresser()
+	users, err := NewUserMap(db, http.Client{}, rooms, echoSuppresser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matrixUser := matrix.NewUser("@nancy:st.andrews", mockMatrixClient)
+	slackUser := &slack.User{"U34", mockSlackClient}
+	users.Link(matrixUser, slackUser)
+
+	bridge := Bridge{users, rooms, NewMessageMap(db), nil, nil, http.Client{}, echoSuppresser, Config{}}
+	bridge.OnSlackMessage(slack.Message{
+		Type:    "message",
+		Channel: "CANTINA",
+		User:    "U34",
+		Text:    "Take more chances",
+		TS:      "1234567890.123456",
+	})
+	text := "<https://st-andrews.slack.com/archives/CANTINA/p1234567890123456> Dance more dances"
+	bridge.OnSlackMessage(slack.Message{
+		Type:    "message",
+		Channel: "CANTINA",
+		User:    "U34",
+		Text:    text,
+		TS:      "1234567891.000000",
+	})
+
+	want := []call{
+		call{"SendText", []interface{}{"!abc123:matrix.org", "Take more chances", ""}},
+		call{"SendMessage", []interface{}{"!abc123:matrix.org", &matrix.TextMessageContent{
+			Body:      slackToMatrix(text),
+			MsgType:   "m.text",
+			RelatesTo: &matrix.RelatesTo{InReplyTo: &matrix.InReplyTo{EventID: "$event1"}},
+		}}},
+	}
+	if !reflect.DeepEqual(mockMatrixClient.calls, want) {
+		t.Fatalf("Wrong Matrix calls, want %v got %v", want, mockMatrixClient.calls)
+	}
+}
+
+func TestSlackMention(t *testing.T) {
+	mockMatrixClient := &MockMatrixClient{}
+	mockSlackClient := &MockSlackClient{}
+
+	db := makeStore(t)
+	rooms, err := NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matrixRoom := matrix.NewRoom("!abc123:matrix.org")
+	matrixRoom.Users["@nancy:st.andrews"] = matrix.UserInfo{DisplayName: "Nancy"}
+	rooms.Link(matrixRoom, "CANTINA")
+
+	echoSuppresser := common.NewEchoSuppresser()
+	users, err := NewUserMap(db, http.Client{}, rooms, echoSuppresser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matrixUser := matrix.NewUser("@nancy:st.andrews", mockMatrixClient)
+	slackUser := &slack.User{"U34", mockSlackClient}
+	users.Link(matrixUser, slackUser)
+
+	slackRoomMembers := slack.NewRoomMembers()
+	slackRoomMembers.Add("CANTINA", slackUser)
+	client := http.Client{
+		Transport: &spyRoundTripper{func(req *http.Request) string {
+			if req.URL.Path != "/api/users.info" {
+				t.Errorf("Unexpected request to %v", req.URL)
+			}
+			switch req.URL.Query().Get("user") {
+			case "U35":
+				return `{"ok": true, "user": {"id": "U35", "name": "vivian"}}`
+			}
+			return `{"ok": false, "error": "user_not_found"}`
+		}},
+	}
+	bridge := Bridge{users, rooms, NewMessageMap(db), slackRoomMembers, nil, client, echoSuppresser, Config{
+		UserPrefix:     "@prefix_",
+		HomeserverName: "st.andrews",
+	}}
+	bridge.OnSlackMessage(slack.Message{
+		Type:    "message",
+		Channel: "CANTINA",
+		User:    "U34",
+		Text:    "<@U34> & <@U35|viv> & <@U36>",
+		TS:      "1.000",
+	})
+
+	want := []call{
+		call{"SendMessage", []interface{}{"!abc123:matrix.org", &matrix.TextMessageContent{
+			Body:          "Nancy & viv & @U36",
+			MsgType:       "m.text",
+			Format:        "org.matrix.custom.html",
+			FormattedBody: `<a href="https://matrix.to/#/@nancy:st.andrews">Nancy</a> &amp; <a href="https://matrix.to/#/@prefix_vivian:st.andrews">viv</a> &amp; @U36`,
+			Mentions:      &matrix.Mentions{UserIDs: []string{"@nancy:st.andrews", "@prefix_vivian:st.andrews"}},
+		}}},
+	}
+	if !reflect.DeepEqual(mockMatrixClient.calls, want) {
+		t.Fatalf("Wrong Matrix calls, want %v got %v", want, mockMatrixClient.calls)
+	}
+}
+
+func TestSlackChannelReference(t *testing.T) {
+	mockMatrixClient := &MockMatrixClient{}
+	mockSlackClient := &MockSlackClient{}
+
+	db := makeStore(t)
+	rooms, err := NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "CANTINA")
+	rooms.Link(matrix.NewRoom("!def456:matrix.org"), "BOWLINGALLEY")
+
+	echoSuppresser := common.NewEchoSuppresser()
+	users, err := NewUserMap(db, http.Client{}, rooms, echoSuppresser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matrixUser := matrix.NewUser("@nancy:st.andrews", mockMatrixClient)
+	slackUser := &slack.User{"U34", mockSlackClient}
+	users.Link(matrixUser, slackUser)
+
+	client := http.Client{
+		Transport: &spyRoundTripper{func(req *http.Request) string {
+			if req.URL.Path != "/_matrix/client/api/v1/rooms/!def456:matrix.org/state/m.room.canonical_alias" {
+				t.Errorf("Unexpected request to %v", req.URL)
+			}
+			return `{"alias": "#bowling:matrix.org"}`
+		}},
+	}
+	bridge := Bridge{users, rooms, NewMessageMap(db), nil, nil, client, echoSuppresser, Config{
+		HomeserverBaseURL: "https://matrix.org",
+	}}
+	bridge.OnSlackMessage(slack.Message{
+		Type:    "message",
+		Channel: "CANTINA",
+		User:    "U34",
+		Text:    "See <#BOWLINGALLEY|bowling> or <#C0FFEE|coffee>",
+		TS:      "1.000",
+	})
+
+	want := []call{
+		call{"SendText", []interface{}{"!abc123:matrix.org", "See #bowling or #coffee", `See <a href="https://matrix.to/#/#bowling:matrix.org">#bowling</a> or #coffee`}},
+	}
+	if !reflect.DeepEqual(mockMatrixClient.calls, want) {
+		t.Fatalf("Wrong Matrix calls, want %v got %v", want, mockMatrixClient.calls)
+	}
+}
+
+func TestSlackBlocks(t *testing.T) {
+	mockMatrixClient := &MockMatrixClient{}
+	mockSlackClient := &MockSlackClient{}
+
+	db := makeStore(t)
+	rooms, err := NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "CANTINA")
+	rooms.Link(matrix.NewRoom("!def456:matrix.org"), "BOWLINGALLEY")
+
+	echoSuppresser := common.NewEchoSuppresser()
+	users, err := NewUserMap(db, http.Client{}, rooms, echoSuppresser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matrixUser := matrix.NewUser("@nancy:st.andrews", mockMatrixClient)
+	slackUser := &slack.User{"U34", mockSlackClient}
+	users.Link(matrixUser, slackUser)
+
+	slackRoomMembers := slack.NewRoomMembers()
+	slackRoomMembers.Add("CANTINA", slackUser)
+	client := http.Client{
+		Transport: &spyRoundTripper{func(req *http.Request) string {
+			if req.URL.Path == "/api/conversations.info" {
+				switch req.URL.Query().Get("channel") {
+				case "C0FFEE":
+					return `{"ok": true, "channel": {"id": "C0FFEE", "name": "coffee"}}`
+				case "BOWLINGALLEY":
+					return `{"ok": true, "channel": {"id": "BOWLINGALLEY", "name": "bowling"}}`
+				}
+				return `{"ok": false, "error": "channel_not_found"}`
+			}
+			return `{"alias": "#bowling:matrix.org"}`
+		}},
+	}
+
+	var m slack.Message
+	if err := json.Unmarshal([]byte(`{
+		"type": "message",
+		"channel": "CANTINA",
+		"user": "U34",
+		"text": "Deploy finished",
+		"ts": "1.000",
+		"blocks": [
+			{"type": "header", "text": {"type": "plain_text", "text": "Deploy :rocket:"}},
+			{"type": "section", "text": {"type": "mrkdwn", "text": "*prod* is live, <@U34>"},
+				"fields": [{"type": "mrkdwn", "text": "*Version*\n1.2"}],
+				"accessory": {"type": "button", "text": {"type": "plain_text", "text": "Logs"}, "url": "https://ci.example.com/1"}},
+			{"type": "divider"},
+			{"type": "image", "image_url": "https://example.com/graph.png", "alt_text": "graph"},
+			{"type": "context", "elements": [
+				{"type": "image", "image_url": "https://example.com/ci.png", "alt_text": "ci"},
+				{"type": "mrkdwn", "text": "by _deploybot_"}
+			]},
+			{"type": "actions", "elements": [
+				{"type": "button", "text": {"type": "plain_text", "text": "Roll back"}, "style": "danger"},
+				{"type": "static_select", "placeholder": {"type": "plain_text", "text": "Pick env"}}
+			]},
+			{"type": "rich_text", "elements": [{"type": "rich_text_section", "elements": [
+				{"type": "text", "text": "see "},
+				{"type": "channel", "channel_id": "C0FFEE"},
+				{"type": "text", "text": " or "},
+				{"type": "channel", "channel_id": "BOWLINGALLEY"},
+				{"type": "text", "text": " or "},
+				{"type": "channel", "channel_id": "CGONE"},
+				{"type": "text", "text": " now", "style": {"bold": true}}
+			]}]}
+		]
+	}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	bridge := Bridge{users, rooms, NewMessageMap(db), slackRoomMembers, nil, client, echoSuppresser, Config{}}
+	bridge.OnSlackMessage(m)
+
+	want := []call{
+		call{"SendImage", []interface{}{"!abc123:matrix.org", "graph", matrix.Image{URL: "https://example.com/graph.png"}}},
+		call{"SendMessage", []interface{}{"!abc123:matrix.org", &matrix.TextMessageContent{
+			Body: "Deploy 🚀\n" +
+				"*prod* is live, @nancy:st.andrews\n*Version*\n1.2\nLogs ( https://ci.example.com/1 )\n" +
+				"---\n" +
+				"ci by _deploybot_\n" +
+				"[Roll back] [Pick env]\n" +
+				"see #coffee or #bowling or #CGONE now",
+			MsgType: "m.text",
+			Format:  "org.matrix.custom.html",
+			FormattedBody: "<h3>Deploy 🚀</h3>" +
+				`<p><strong>prod</strong> is live, <a href="https://matrix.to/#/@nancy:st.andrews">@nancy:st.andrews</a><br><strong>Version</strong><br>1.2<br><a href="https://ci.example.com/1">Logs</a></p>` +
+				"<hr>" +
+				"<p>ci by <em>deploybot</em></p>" +
+				"<p>[Roll back] [Pick env]</p>" +
+				`<p>see #coffee or <a href="https://matrix.to/#/#bowling:matrix.org">#bowling</a> or #CGONE<strong> now</strong></p>`,
+			Mentions: &matrix.Mentions{UserIDs: []string{"@nancy:st.andrews"}},
+		}}},
+	}
+	if !reflect.DeepEqual(mockMatrixClient.calls, want) {
+		t.Fatalf("Wrong Matrix calls, want %v got %v", want, mockMatrixClient.calls)
+	}
+	if got := bridge.MessageMap.MatrixForSlack("CANTINA", "1.000"); len(got) != 2 {
+		t.Errorf("MatrixForSlack: want 2 events got %v", got)
+	}
+}
+
+func TestSlackAttachments(t *testing.T) {
+	mockMatrixClient := &MockMatrixClient{}
+	mockSlackClient := &MockSlackClient{}
+
+	db := makeStore(t)
+	rooms, err := NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "CANTINA")
+
+	echoSuppresser := common.NewEchoSuppresser()
+	users, err := NewUserMap(db, http.Client{}, rooms, echoSuppresser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matrixUser := matrix.NewUser("@nancy:st.andrews", mockMatrixClient)
+	slackUser := &slack.User{"U34", mockSlackClient}
+	users.Link(matrixUser, slackUser)
+
+	var m slack.Message
+	if err := json.Unmarshal([]byte(`{
+		"type": "message",
+		"channel": "CANTINA",
+		"user": "U34",
+		"text": "Incident for <@U34>",
+		"ts": "1.000",
+		"attachments": [{
+			"fallback": "Disk full on db1",
+			"color": "danger",
+			"pretext": "New incident",
+			"title": "Disk full &amp; rising",
+			"title_link": "https://pd.example.com/1",
+			"text": "_db1_ is at 99%",
+			"fields": [{"title": "Priority", "value": "*P1*", "short": true}],
+			"image_url": "https://pd.example.com/graph.png",
+			"footer": "PagerDuty"
+		}, {
+			"fallback": "Resolved",
+			"color": "not a color"
+		}]
+	}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	bridge := Bridge{users, rooms, NewMessageMap(db), nil, nil, http.Client{}, echoSuppresser, Config{}}
+	bridge.OnSlackMessage(m)
+
+	want := []call{
+		call{"SendImage", []interface{}{"!abc123:matrix.org", "Disk full & rising", matrix.Image{URL: "https://pd.example.com/graph.png"}}},
+		call{"SendMessage", []interface{}{"!abc123:matrix.org", &matrix.TextMessageContent{
+			Body: "Incident for @nancy:st.andrews\n" +
+				"New incident\n" +
+				"> Disk full & rising ( https://pd.example.com/1 )\n> _db1_ is at 99%\n> Priority: *P1*\n> PagerDuty\n" +
+				"> Resolved",
+			MsgType: "m.text",
+			Format:  "org.matrix.custom.html",
+			FormattedBody: `Incident for <a href="https://matrix.to/#/@nancy:st.andrews">@nancy:st.andrews</a>` +
+				"<p>New incident</p>" +
+				`<blockquote><font data-mx-color="#a30200">▌</font><p><strong><a href="https://pd.example.com/1">Disk full &amp; rising</a></strong><br><em>db1</em> is at 99%<br><strong>Priority</strong>: <strong>P1</strong><br><sub>PagerDuty</sub></p></blockquote>` +
+				"<blockquote><p>Resolved</p></blockquote>",
+			Mentions: &matrix.Mentions{UserIDs: []string{"@nancy:st.andrews"}},
+		}}},
+	}
+	if !reflect.DeepEqual(mockMatrixClient.calls, want) {
+		t.Fatalf("Wrong Matrix calls, want %v got %v", want, mockMatrixClient.calls)
+	}
+}
+
+// failingImageClient can't send images, like when they aren't served safely.
+type failingImageClient struct {
+	*MockMatrixClient
+}
+
+func (c failingImageClient) SendImage(roomID, text string, image *matrix.Image) (string, error) {
+	c.MockMatrixClient.SendImage(roomID, text, image)
+	return "", errors.New("refusing to fetch image from non-https URL")
+}
+
+func TestSlackImageSentAsLinkWhenItCantBeSent(t *testing.T) {
+	mockMatrixClient := &MockMatrixClient{}
+	mockSlackClient := &MockSlackClient{}
+
+	db := makeStore(t)
+	rooms, err := NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "CANTINA")
+
+	echoSuppresser := common.NewEchoSuppresser()
+	users, err := NewUserMap(db, http.Client{}, rooms, echoSuppresser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matrixUser := matrix.NewUser("@nancy:st.andrews", failingImageClient{mockMatrixClient})
+	slackUser := &slack.User{"U34", mockSlackClient}
+	users.Link(matrixUser, slackUser)
+
+	var m slack.Message
+	if err := json.Unmarshal([]byte(`{
+		"type": "message",
+		"channel": "CANTINA",
+		"user": "U34",
+		"text": "Graph",
+		"ts": "1.000",
+		"blocks": [
+			{"type": "image", "image_url": "http://10.0.0.1/graph.png", "alt_text": "graph"}
+		]
+	}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	bridge := Bridge{users, rooms, NewMessageMap(db), nil, nil, http.Client{}, echoSuppresser, Config{}}
+	bridge.OnSlackMessage(m)
+
+	want := []call{
+		call{"SendImage", []interface{}{"!abc123:matrix.org", "graph", matrix.Image{URL: "http://10.0.0.1/graph.png"}}},
+		call{"SendText", []interface{}{"!abc123:matrix.org", "http://10.0.0.1/graph.png", `<a href="http://10.0.0.1/graph.png">graph</a>`}},
+	}
+	if !reflect.DeepEqual(mockMatrixClient.calls, want) {
+		t.Fatalf("Wrong Matrix calls, want %v got %v", want, mockMatrixClient.calls)
+	}
+	if got := bridge.MessageMap.MatrixForSlack("CANTINA", "1.000"); len(got) != 1 {
+		t.Errorf("MatrixForSlack: want 1 event got %v", got)
+	}
+
+	// Attachment images follow the attachment's text.
+	mockMatrixClient.calls = nil
+	var attached slack.Message
+	if err := json.Unmarshal([]byte(`{
+		"type": "message",
+		"channel": "CANTINA",
+		"user": "U34",
+		"text": "Incident",
+		"ts": "2.000",
+		"attachments": [{"fallback": "Disk full", "title": "Disk full", "image_url": "https://pd.example.com/graph.png"}]
+	}`), &attached); err != nil {
+		t.Fatal(err)
+	}
+	bridge.OnSlackMessage(attached)
+
+	want = []call{
+		call{"SendImage", []interface{}{"!abc123:matrix.org", "Disk full", matrix.Image{URL: "https://pd.example.com/graph.png"}}},
+		call{"SendText", []interface{}{"!abc123:matrix.org", "Incident\n> Disk full\nhttps://pd.example.com/graph.png",
+			`Incident<blockquote><p><strong>Disk full</strong></p></blockquote><a href="https://pd.example.com/graph.png">Disk full</a>`}},
+	}
 	if !reflect.DeepEqual(mockMatrixClient.calls, want) {
 		t.Fatalf("Wrong Matrix calls, want %v got %v", want, mockMatrixClient.calls)
 	}
@@ -57,101 +742,391 @@ func TestSlackMeMessage(t *testing.T) {
 	mockMatrixClient := &MockMatrixClient{}
 	mockSlackClient := &MockSlackClient{}
 
-	db := makeDB(t)
+	db := makeStore(t)
+	rooms, err := NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "CANTINA")
+
+	echoSuppresser := common.NewEchoSuppresser()
+	users, err := NewUserMap(db, http.Client{}, rooms, echoSuppresser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matrixUser := matrix.NewUser("@nancy:st.andrews", mockMatrixClient)
+	slackUser := &slack.User{"U34", mockSlackClient}
+	users.Link(matrixUser, slackUser)
+
+	bridge := Bridge{users, rooms, NewMessageMap(db), nil, nil, http.Client{}, echoSuppresser, Config{}}
+	bridge.OnSlackMessage(slack.Message{
+		Type:    "message",
+		Channel: "CANTINA",
+		User:    "U34",
+		Subtype: "me_message",
+		Text:    "takes more chances",
+	})
+
+	want := []call{call{"SendEmote", []interface{}{"!abc123:matrix.org", "takes more chances", ""}}}
+	if !reflect.DeepEqual(mockMatrixClient.calls, want) {
+		t.Fatalf("Wrong Matrix calls, want %v got %v", want, mockMatrixClient.calls)
+	}
+}
+
+func TestSlackMessageWithImage(t *testing.T) {
+	mockMatrixClient := &MockMatrixClient{}
+	mockSlackClient := &MockSlackClient{}
+
+	db := makeStore(t)
+	rooms, err := NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "CANTINA")
+
+	echoSuppresser := common.NewEchoSuppresser()
+	users, err := NewUserMap(db, http.Client{}, rooms, echoSuppresser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matrixUser := matrix.NewUser("@nancy:st.andrews", mockMatrixClient)
+	slackUser := &slack.User{"U34", mockSlackClient}
+	users.Link(matrixUser, slackUser)
+
+	bridge := Bridge{users, rooms, NewMessageMap(db), nil, nil, http.Client{}, echoSuppresser, Config{}}
+
+	imageURL := "https://slack-files.com/files-pub/T02TMLW97-F0D2M81QA-38528eaf47/otters.jpg"
+	bridge.OnSlackMessage(slack.Message{
+		Type:    "message",
+		Channel: "CANTINA",
+		User:    "U34",
+		Text:    "Cute otter",
+		File: &slack.File{
+			MIMEType:       "image/jpeg",
+			URL:            imageURL,
+			OriginalHeight: 768,
+			OriginalWidth:  1024,
+			Size:           90,
+			CommentsCount:  1,
+			InitialComment: &slack.Comment{
+				Comment: "omg",
+				User:    "U34",
+			},
+		},
+	})
+
+	want := []call{
+		call{"SendImage", []interface{}{"!abc123:matrix.org", "otters.jpg", matrix.Image{
+			URL: imageURL,
+			Info: &matrix.ImageInfo{
+				Width:    1024,
+				Height:   768,
+				MIMEType: "image/jpeg",
+				Size:     90,
+			},
+		}}},
+		call{"SendText", []interface{}{"!abc123:matrix.org", "omg", ""}},
+	}
+	if !reflect.DeepEqual(mockMatrixClient.calls, want) {
+		t.Fatalf("Wrong Matrix calls, want:\n%v\ngot:\n%v", want, mockMatrixClient.calls)
+	}
+}
+
+func TestMatrixMessage(t *testing.T) {
+	mockMatrixClient := &MockMatrixClient{}
+	mockSlackClient := &MockSlackClient{}
+
+	db := makeStore(t)
+	rooms, err := NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "BOWLINGALLEY")
+
+	echoSuppresser := common.NewEchoSuppresser()
+	users, err := NewUserMap(db, http.Client{}, rooms, echoSuppresser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matrixUser := matrix.NewUser("@sean:st.andrews", mockMatrixClient)
+	slackUser := &slack.User{"U35", mockSlackClient}
+	users.Link(matrixUser, slackUser)
+
+	bridge := Bridge{users, rooms, NewMessageMap(db), nil, nil, http.Client{}, echoSuppresser, Config{}}
+	bridge.OnMatrixRoomMessage(matrix.RoomMessage{
+		Type:    "m.room.message",
+		Content: []byte(`{"msgtype": "m.text", "body": "It's Nancy!"}`),
+		UserID:  "@sean:st.andrews",
+		RoomID:  "!abc123:matrix.org",
+	})
+
+	want := []call{call{"SendText", []interface{}{"BOWLINGALLEY", "It's Nancy!"}}}
+	if !reflect.DeepEqual(mockSlackClient.calls, want) {
+		t.Fatalf("Wrong Slack calls, want %v got %v", want, mockSlackClient.calls)
+	}
+}
+
+func TestMatrixEdit(t *testing.T) {
+	mockMatrixClient := &MockMatrixClient{}
+	mockSlackClient := &MockSlackClient{}
+
+	db := makeStore(t)
+	rooms, err := NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "BOWLINGALLEY")
+
+	echoSuppresser := common.NewEchoSuppresser()
+	users, err := NewUserMap(db, http.Client{}, rooms, echoSuppresser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matrixUser := matrix.NewUser("@sean:st.andrews", mockMatrixClient)
+	slackUser := &slack.User{"U35", mockSlackClient}
+	users.Link(matrixUser, slackUser)
+
+	bridge := Bridge{users, rooms, NewMessageMap(db), nil, nil, http.Client{}, echoSuppresser, Config{}}
+	bridge.OnMatrixRoomMessage(matrix.RoomMessage{
+		Type:    "m.room.message",
+		Content: []byte(`{"msgtype": "m.text", "body": "It's Nancy!"}`),
+		UserID:  "@sean:st.andrews",
+		RoomID:  "!abc123:matrix.org",
+		EventID: "$original",
+	})
+	bridge.OnMatrixRoomMessage(matrix.RoomMessage{
+		Type: "m.room.message",
+		Content: []byte(`{
+			"msgtype": "m.text",
+			"body": "* It's Vivian!",
+			"m.new_content": {"msgtype": "m.text", "body": "It's Vivian!"},
+			"m.relates_to": {"rel_type": "m.replace", "event_id": "$original"}
+		}`),
+		UserID:  "@sean:st.andrews",
+		RoomID:  "!abc123:matrix.org",
+		EventID: "$edit",
+	})
+	// Edits of messages which were never bridged fall back to a new message.
+	bridge.OnMatrixRoomMessage(matrix.RoomMessage{
+		Type: "m.room.message",
+		Content: []byte(`{
+			"msgtype": "m.text",
+			"body": "* It's Martha!",
+			"m.new_content": {"msgtype": "m.text", "body": "It's Martha!"},
+			"m.relates_to": {"rel_type": "m.replace", "event_id": "$unknown"}
+		}`),
+		UserID:  "@sean:st.andrews",
+		RoomID:  "!abc123:matrix.org",
+		EventID: "$edit2",
+	})
+
+	want := []call{
+		call{"SendText", []interface{}{"BOWLINGALLEY", "It's Nancy!"}},
+		call{"UpdateText", []interface{}{"BOWLINGALLEY", "1.000", "It's Vivian!"}},
+		call{"SendText", []interface{}{"BOWLINGALLEY", "* It's Martha!"}},
+	}
+	if !reflect.DeepEqual(mockSlackClient.calls, want) {
+		t.Fatalf("Wrong Slack calls, want %v got %v", want, mockSlackClient.calls)
+	}
+}
+
+func TestMatrixEditOfSomeoneElsesMessage(t *testing.T) {
+	db := makeStore(t)
+	rooms, err := NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "BOWLINGALLEY")
+
+	echoSuppresser := common.NewEchoSuppresser()
+	users, err := NewUserMap(db, http.Client{}, rooms, echoSuppresser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seanSlackClient := &MockSlackClient{}
+	users.Link(matrix.NewUser("@sean:st.andrews", &MockMatrixClient{}), &slack.User{"U35", seanSlackClient})
+	vivianSlackClient := &MockSlackClient{}
+	users.Link(matrix.NewUser("@vivian:st.andrews", &MockMatrixClient{}), &slack.User{"U36", vivianSlackClient})
+
+	bridge := Bridge{users, rooms, NewMessageMap(db), nil, nil, http.Client{}, echoSuppresser, Config{}}
+	bridge.OnMatrixRoomMessage(matrix.RoomMessage{
+		Type:    "m.room.message",
+		Content: []byte(`{"msgtype": "m.text", "body": "It's Nancy!"}`),
+		UserID:  "@sean:st.andrews",
+		RoomID:  "!abc123:matrix.org",
+		EventID: "$original",
+	})
+	bridge.OnMatrixRoomMessage(matrix.RoomMessage{
+		Type: "m.room.message",
+		Content: []byte(`{
+			"msgtype": "m.text",
+			"body": "* It's Vivian!",
+			"m.new_content": {"msgtype": "m.text", "body": "It's Vivian!"},
+			"m.relates_to": {"rel_type": "m.replace", "event_id": "$original"}
+		}`),
+		UserID:  "@vivian:st.andrews",
+		RoomID:  "!abc123:matrix.org",
+		EventID: "$edit",
+	})
+
+	want := []call{
+		call{"SendText", []interface{}{"BOWLINGALLEY", "It's Nancy!"}},
+	}
+	if !reflect.DeepEqual(seanSlackClient.calls, want) {
+		t.Errorf("Wrong Slack calls for sender, want %v got %v", want, seanSlackClient.calls)
+	}
+	if len(vivianSlackClient.calls) != 0 {
+		t.Errorf("Wrong Slack calls for editor, want none got %v", vivianSlackClient.calls)
+	}
+}
+
+func TestMatrixRedaction(t *testing.T) {
+	mockMatrixClient := &MockMatrixClient{}
+	mockSlackClient := &MockSlackClient{}
+
+	db := makeStore(t)
 	rooms, err := NewRoomMap(db)
 	if err != nil {
 		t.Fatal(err)
 	}
-	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "CANTINA")
+	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "BOWLINGALLEY")
 
 	echoSuppresser := common.NewEchoSuppresser()
 	users, err := NewUserMap(db, http.Client{}, rooms, echoSuppresser)
 	if err != nil {
 		t.Fatal(err)
 	}
-	matrixUser := matrix.NewUser("@nancy:st.andrews", mockMatrixClient)
-	slackUser := &slack.User{"U34", mockSlackClient}
+	matrixUser := matrix.NewUser("@sean:st.andrews", mockMatrixClient)
+	slackUser := &slack.User{"U35", mockSlackClient}
 	users.Link(matrixUser, slackUser)
 
-	bridge := Bridge{users, rooms, nil, nil, http.Client{}, echoSuppresser, Config{}}
-	bridge.OnSlackMessage(slack.Message{
-		Type:    "message",
-		Channel: "CANTINA",
-		User:    "U34",
-		Subtype: "me_message",
-		Text:    "takes more chances",
+	client := http.Client{
+		Transport: &spyRoundTripper{func(req *http.Request) string {
+			if !strings.HasSuffix(req.URL.Path, "/state/m.room.power_levels") {
+				t.Errorf("Unexpected request to %v", req.URL)
+			}
+			return `{"users": {"@moderator:st.andrews": 50}}`
+		}},
+	}
+	bridge := Bridge{users, rooms, NewMessageMap(db), nil, nil, client, echoSuppresser, Config{}}
+	bridge.OnMatrixRoomMessage(matrix.RoomMessage{
+		Type:    "m.room.message",
+		Content: []byte(`{"msgtype": "m.text", "body": "It's Nancy!"}`),
+		UserID:  "@sean:st.andrews",
+		RoomID:  "!abc123:matrix.org",
+		EventID: "$original",
+	})
+	// Someone without power to redact others' events can't delete from Slack.
+	bridge.OnMatrixRedaction(matrix.Redaction{
+		Type:    "m.room.redaction",
+		Redacts: "$original",
+		UserID:  "@mallory:st.andrews",
+		RoomID:  "!abc123:matrix.org",
+		EventID: "$redaction1",
+	})
+	bridge.OnMatrixRedaction(matrix.Redaction{
+		Type:    "m.room.redaction",
+		Redacts: "$original",
+		UserID:  "@sean:st.andrews",
+		RoomID:  "!abc123:matrix.org",
+		EventID: "$redaction2",
 	})
 
-	want := []call{call{"SendEmote", []interface{}{"!abc123:matrix.org", "takes more chances"}}}
-	if !reflect.DeepEqual(mockMatrixClient.calls, want) {
-		t.Fatalf("Wrong Matrix calls, want %v got %v", want, mockMatrixClient.calls)
+	want := []call{
+		call{"SendText", []interface{}{"BOWLINGALLEY", "It's Nancy!"}},
+		call{"Delete", []interface{}{"BOWLINGALLEY", "1.000"}},
+	}
+	if !reflect.DeepEqual(mockSlackClient.calls, want) {
+		t.Fatalf("Wrong Slack calls, want %v got %v", want, mockSlackClient.calls)
 	}
 }
 
-func TestSlackMessageWithImage(t *testing.T) {
+func TestMatrixReaction(t *testing.T) {
 	mockMatrixClient := &MockMatrixClient{}
 	mockSlackClient := &MockSlackClient{}
 
-	db := makeDB(t)
+	db := makeStore(t)
 	rooms, err := NewRoomMap(db)
 	if err != nil {
 		t.Fatal(err)
 	}
-	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "CANTINA")
+	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "BOWLINGALLEY")
 
 	echoSuppresser := common.NewEchoSuppresser()
 	users, err := NewUserMap(db, http.Client{}, rooms, echoSuppresser)
 	if err != nil {
 		t.Fatal(err)
 	}
-	matrixUser := matrix.NewUser("@nancy:st.andrews", mockMatrixClient)
-	slackUser := &slack.User{"U34", mockSlackClient}
+	matrixUser := matrix.NewUser("@sean:st.andrews", mockMatrixClient)
+	slackUser := &slack.User{"U35", mockSlackClient}
 	users.Link(matrixUser, slackUser)
 
-	bridge := Bridge{users, rooms, nil, nil, http.Client{}, echoSuppresser, Config{}}
-
-	imageURL := "https://slack-files.com/files-pub/T02TMLW97-F0D2M81QA-38528eaf47/otters.jpg"
+	bridge := Bridge{users, rooms, NewMessageMap(db), nil, nil, http.Client{}, echoSuppresser, Config{}}
+	bridge.OnMatrixRoomMessage(matrix.RoomMessage{
+		Type:    "m.room.message",
+		Content: []byte(`{"msgtype": "m.text", "body": "It's Nancy!"}`),
+		UserID:  "@sean:st.andrews",
+		RoomID:  "!abc123:matrix.org",
+		EventID: "$original",
+	})
+	react := func(eventID, key string) {
+		bridge.OnMatrixReaction(matrix.Reaction{
+			Type: "m.reaction",
+			Content: matrix.ReactionContent{RelatesTo: &matrix.RelatesTo{
+				RelType: "m.annotation",
+				EventID: "$original",
+				Key:     key,
+			}},
+			UserID:  "@sean:st.andrews",
+			RoomID:  "!abc123:matrix.org",
+			EventID: eventID,
+		})
+	}
+	redact := func(eventID string) {
+		bridge.OnMatrixRedaction(matrix.Redaction{
+			Type:    "m.room.redaction",
+			Redacts: eventID,
+			UserID:  "@sean:st.andrews",
+			RoomID:  "!abc123:matrix.org",
+			EventID: "$redaction",
+		})
+	}
+	react("$reaction1", "👍🏻")
+	react("$reaction2", "lol")
+	// Reacting to or editing the reply on Slack doesn't touch the reaction.
+	bridge.OnSlackReaction(slack.Reaction{Type: "reaction_added", User: "U35", Reaction: "fire", Item: slack.ReactionItem{Type: "message", Channel: "BOWLINGALLEY", TS: "3.000"}})
+	edited := slack.Message{Type: "message", User: "U35", Text: "reacted with rofl"}
 	bridge.OnSlackMessage(slack.Message{
-		Type:    "message",
-		Channel: "CANTINA",
-		User:    "U34",
-		Text:    "Cute otter",
-		File: &slack.File{
-			MIMEType:       "image/jpeg",
-			URL:            imageURL,
-			OriginalHeight: 768,
-			OriginalWidth:  1024,
-			Size:           90,
-			CommentsCount:  1,
-			InitialComment: &slack.Comment{
-				Comment: "omg",
-				User:    "U34",
-			},
-		},
+		Type:            "message",
+		Subtype:         "message_changed",
+		Channel:         "BOWLINGALLEY",
+		TS:              "3.000",
+		Message:         &edited,
+		PreviousMessage: &slack.Message{Type: "message", User: "U35", Text: "reacted with lol"},
 	})
+	if len(mockMatrixClient.calls) != 0 {
+		t.Errorf("Wrong Matrix calls, want none got %v", mockMatrixClient.calls)
+	}
+	redact("$reaction1")
+	redact("$reaction2")
 
 	want := []call{
-		call{"SendImage", []interface{}{"!abc123:matrix.org", "otters.jpg", matrix.Image{
-			URL: imageURL,
-			Info: &matrix.ImageInfo{
-				Width:    1024,
-				Height:   768,
-				MIMEType: "image/jpeg",
-				Size:     90,
-			},
-		}}},
-		call{"SendText", []interface{}{"!abc123:matrix.org", "omg"}},
+		call{"SendText", []interface{}{"BOWLINGALLEY", "It's Nancy!"}},
+		call{"AddReaction", []interface{}{"BOWLINGALLEY", "1.000", "+1::skin-tone-2"}},
+		call{"SendThreadText", []interface{}{"BOWLINGALLEY", "1.000", "reacted with lol"}},
+		call{"RemoveReaction", []interface{}{"BOWLINGALLEY", "1.000", "+1::skin-tone-2"}},
+		call{"Delete", []interface{}{"BOWLINGALLEY", "3.000"}},
 	}
-	if !reflect.DeepEqual(mockMatrixClient.calls, want) {
-		t.Fatalf("Wrong Matrix calls, want:\n%v\ngot:\n%v", want, mockMatrixClient.calls)
+	if !reflect.DeepEqual(mockSlackClient.calls, want) {
+		t.Fatalf("Wrong Slack calls, want %v got %v", want, mockSlackClient.calls)
 	}
 }
 
-func TestMatrixMessage(t *testing.T) {
+func TestMatrixThread(t *testing.T) {
 	mockMatrixClient := &MockMatrixClient{}
 	mockSlackClient := &MockSlackClient{}
 
-	db := makeDB(t)
+	db := makeStore(t)
 	rooms, err := NewRoomMap(db)
 	if err != nil {
 		t.Fatal(err)
@@ -167,25 +1142,322 @@ func TestMatrixMessage(t *testing.T) {
 	slackUser := &slack.User{"U35", mockSlackClient}
 	users.Link(matrixUser, slackUser)
 
-	bridge := Bridge{users, rooms, nil, nil, http.Client{}, echoSuppresser, Config{}}
+	bridge := Bridge{users, rooms, NewMessageMap(db), nil, nil, http.Client{}, echoSuppresser, Config{}}
 	bridge.OnMatrixRoomMessage(matrix.RoomMessage{
 		Type:    "m.room.message",
 		Content: []byte(`{"msgtype": "m.text", "body": "It's Nancy!"}`),
 		UserID:  "@sean:st.andrews",
 		RoomID:  "!abc123:matrix.org",
+		EventID: "$root",
+	})
+	bridge.OnMatrixRoomMessage(matrix.RoomMessage{
+		Type: "m.room.message",
+		Content: []byte(`{"msgtype": "m.text", "body": "It's Vivian!", "m.relates_to": {
+			"rel_type": "m.thread",
+			"event_id": "$root",
+			"is_falling_back": true,
+			"m.in_reply_to": {"event_id": "$root"}
+		}}`),
+		UserID:  "@sean:st.andrews",
+		RoomID:  "!abc123:matrix.org",
+		EventID: "$reply",
 	})
 
-	want := []call{call{"SendText", []interface{}{"BOWLINGALLEY", "It's Nancy!"}}}
+	want := []call{
+		call{"SendText", []interface{}{"BOWLINGALLEY", "It's Nancy!"}},
+		call{"SendThreadText", []interface{}{"BOWLINGALLEY", "1.000", "It's Vivian!"}},
+	}
+	if !reflect.DeepEqual(mockSlackClient.calls, want) {
+		t.Fatalf("Wrong Slack calls, want %v got %v", want, mockSlackClient.calls)
+	}
+}
+
+func TestMatrixReply(t *testing.T) {
+	mockMatrixClient := &MockMatrixClient{}
+	mockSlackClient := &MockSlackClient{}
+
+	db := makeStore(t)
+	rooms, err := NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "BOWLINGALLEY")
+
+	echoSuppresser := common.NewEchoSuppresser()
+	users, err := NewUserMap(db, http.Client{}, rooms, echoSuppresser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matrixUser := matrix.NewUser("@sean:st.andrews", mockMatrixClient)
+	slackUser := &slack.User{"U35", mockSlackClient}
+	users.Link(matrixUser, slackUser)
+
+	bridge := Bridge{users, rooms, NewMessageMap(db), nil, nil, http.Client{}, echoSuppresser, Config{}}
+	bridge.OnMatrixRoomMessage(matrix.RoomMessage{
+		Type:    "m.room.message",
+		Content: []byte(`{"msgtype": "m.text", "body": "It's Nancy!"}`),
+		UserID:  "@sean:st.andrews",
+		RoomID:  "!abc123:matrix.org",
+		EventID: "$original",
+	})
+	bridge.OnMatrixRoomMessage(matrix.RoomMessage{
+		Type: "m.room.message",
+		Content: []byte(`{"msgtype": "m.text", "body": "> <@sean:st.andrews> It's Nancy!\n\nIt's Vivian!", "m.relates_to": {
+			"m.in_reply_to": {"event_id": "$original"}
+		}}`),
+		UserID:  "@sean:st.andrews",
+		RoomID:  "!abc123:matrix.org",
+		EventID: "$reply1",
+	})
+	bridge.OnMatrixRoomMessage(matrix.RoomMessage{
+		Type: "m.room.message",
+		Content: []byte(`{"msgtype": "m.text", "body": "> <@nancy:st.andrews> Take more chances\n\nIt's Sean!", "m.relates_to": {
+			"m.in_reply_to": {"event_id": "$unbridged"}
+		}}`),
+		UserID:  "@sean:st.andrews",
+		RoomID:  "!abc123:matrix.org",
+		EventID: "$reply2",
+	})
+	// A plain text reply which quotes something itself has no fallback.
+	bridge.OnMatrixRoomMessage(matrix.RoomMessage{
+		Type: "m.room.message",
+		Content: []byte(`{"msgtype": "m.text", "body": "> Take more chances\n\nSaid Nancy", "m.relates_to": {
+			"m.in_reply_to": {"event_id": "$original"}
+		}}`),
+		UserID:  "@sean:st.andrews",
+		RoomID:  "!abc123:matrix.org",
+		EventID: "$reply3",
+	})
+	// Nor does a thread event, whose reply is only for older clients.
+	bridge.OnMatrixRoomMessage(matrix.RoomMessage{
+		Type: "m.room.message",
+		Content: []byte(`{"msgtype": "m.text", "body": "> <@nancy:st.andrews> Dance more dances\n\nSaid Nancy", "m.relates_to": {
+			"rel_type": "m.thread",
+			"event_id": "$original",
+			"is_falling_back": true,
+			"m.in_reply_to": {"event_id": "$reply1"}
+		}}`),
+		UserID:  "@sean:st.andrews",
+		RoomID:  "!abc123:matrix.org",
+		EventID: "$reply4",
+	})
+
+	want := []call{
+		call{"SendText", []interface{}{"BOWLINGALLEY", "It's Nancy!"}},
+		call{"SendThreadText", []interface{}{"BOWLINGALLEY", "1.000", "It's Vivian!"}},
+		call{"SendText", []interface{}{"BOWLINGALLEY", "> <https://matrix.to/#/!abc123:matrix.org/$unbridged|@nancy:st.andrews> Take more chances\nIt's Sean!"}},
+		call{"SendThreadText", []interface{}{"BOWLINGALLEY", "1.000", "&gt; Take more chances\n\nSaid Nancy"}},
+		call{"SendThreadText", []interface{}{"BOWLINGALLEY", "1.000", "&gt; &lt;@nancy:st.andrews&gt; Dance more dances\n\nSaid Nancy"}},
+	}
+	if !reflect.DeepEqual(mockSlackClient.calls, want) {
+		t.Fatalf("Wrong Slack calls, want %v got %v", want, mockSlackClient.calls)
+	}
+}
+
+func TestMatrixMention(t *testing.T) {
+	mockMatrixClient := &MockMatrixClient{}
+	mockSlackClient := &MockSlackClient{}
+
+	db := makeStore(t)
+	rooms, err := NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matrixRoom := matrix.NewRoom("!abc123:matrix.org")
+	matrixRoom.Users["@prefix_vivian:st.andrews"] = matrix.UserInfo{DisplayName: "Vivian"}
+	rooms.Link(matrixRoom, "BOWLINGALLEY")
+
+	echoSuppresser := common.NewEchoSuppresser()
+	users, err := NewUserMap(db, http.Client{}, rooms, echoSuppresser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matrixUser := matrix.NewUser("@sean:st.andrews", mockMatrixClient)
+	slackUser := &slack.User{"U35", mockSlackClient}
+	users.Link(matrixUser, slackUser)
+
+	slackRoomMembers := slack.NewRoomMembers()
+	slackRoomMembers.Add("BOWLINGALLEY", slackUser)
+	var listRequests int
+	client := http.Client{
+		Transport: &spyRoundTripper{func(req *http.Request) string {
+			if req.URL.Path != "/api/users.list" {
+				t.Errorf("Unexpected request to %v", req.URL)
+			}
+			listRequests++
+			if req.URL.Query().Get("cursor") == "" {
+				return `{"ok": true, "members": [{"id": "U34", "name": "nancy"}], "response_metadata": {"next_cursor": "abc"}}`
+			}
+			return `{"ok": true, "members": [{"id": "U36", "name": "vivian"}], "response_metadata": {"next_cursor": ""}}`
+		}},
+	}
+	bridge := Bridge{users, rooms, NewMessageMap(db), slackRoomMembers, nil, client, echoSuppresser, Config{
+		UserPrefix:     "@prefix_",
+		HomeserverName: "st.andrews",
+	}}
+	bridge.OnMatrixRoomMessage(matrix.RoomMessage{
+		Type: "m.room.message",
+		Content: []byte(`{
+			"msgtype": "m.text",
+			"body": "Sean: it's Vivian & Mallory!",
+			"format": "org.matrix.custom.html",
+			"formatted_body": "<a href=\"https://matrix.to/#/@sean:st.andrews\">Sean</a>: it's Vivian &amp; <a href=\"https://matrix.to/#/@mallory:st.andrews\">Mallory</a>!",
+			"m.mentions": {"user_ids": ["@sean:st.andrews", "@prefix_vivian:st.andrews", "@mallory:st.andrews"]}
+		}`),
+		UserID:  "@sean:st.andrews",
+		RoomID:  "!abc123:matrix.org",
+		EventID: "$original",
+	})
+	// Vivian's slack ID is remembered.
+	bridge.OnMatrixRoomMessage(matrix.RoomMessage{
+		Type: "m.room.message",
+		Content: []byte(`{
+			"msgtype": "m.text",
+			"body": "Vivian again",
+			"m.mentions": {"user_ids": ["@prefix_vivian:st.andrews"]}
+		}`),
+		UserID:  "@sean:st.andrews",
+		RoomID:  "!abc123:matrix.org",
+		EventID: "$second",
+	})
+
+	want := []call{
+		call{"SendText", []interface{}{"BOWLINGALLEY", "<@U35>: it's <@U36> &amp; Mallory!"}},
+		call{"SendText", []interface{}{"BOWLINGALLEY", "<@U36> again"}},
+	}
+	if !reflect.DeepEqual(mockSlackClient.calls, want) {
+		t.Fatalf("Wrong Slack calls, want %v got %v", want, mockSlackClient.calls)
+	}
+	if listRequests != 2 {
+		t.Errorf("Listed users %d times, want 2", listRequests)
+	}
+}
+
+func TestMatrixRoomLink(t *testing.T) {
+	mockMatrixClient := &MockMatrixClient{}
+	mockSlackClient := &MockSlackClient{}
+
+	db := makeStore(t)
+	rooms, err := NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "BOWLINGALLEY")
+	rooms.Link(matrix.NewRoom("!def456:matrix.org"), "CANTINA")
+
+	echoSuppresser := common.NewEchoSuppresser()
+	users, err := NewUserMap(db, http.Client{}, rooms, echoSuppresser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matrixUser := matrix.NewUser("@sean:st.andrews", mockMatrixClient)
+	slackUser := &slack.User{"U35", mockSlackClient}
+	users.Link(matrixUser, slackUser)
+
+	client := http.Client{
+		Transport: &spyRoundTripper{func(req *http.Request) string {
+			if req.URL.EscapedPath() != "/_matrix/client/api/v1/directory/room/%23cantina:matrix.org" {
+				t.Errorf("Unexpected request to %v", req.URL)
+			}
+			return `{"room_id": "!def456:matrix.org"}`
+		}},
+	}
+	bridge := Bridge{users, rooms, NewMessageMap(db), nil, nil, client, echoSuppresser, Config{
+		HomeserverBaseURL: "https://matrix.org",
+	}}
+	bridge.OnMatrixRoomMessage(matrix.RoomMessage{
+		Type: "m.room.message",
+		Content: []byte(`{
+			"msgtype": "m.text",
+			"body": "See #cantina:matrix.org or https://matrix.to/#/!abc123:matrix.org",
+			"format": "org.matrix.custom.html",
+			"formatted_body": "See <a href=\"https://matrix.to/#/#cantina:matrix.org\">#cantina:matrix.org</a> or https://matrix.to/#/!abc123:matrix.org"
+		}`),
+		UserID:  "@sean:st.andrews",
+		RoomID:  "!abc123:matrix.org",
+		EventID: "$original",
+	})
+
+	want := []call{
+		call{"SendText", []interface{}{"BOWLINGALLEY", "See <#CANTINA> or <#BOWLINGALLEY>"}},
+	}
 	if !reflect.DeepEqual(mockSlackClient.calls, want) {
 		t.Fatalf("Wrong Slack calls, want %v got %v", want, mockSlackClient.calls)
 	}
 }
 
+func TestMatrixRoomPing(t *testing.T) {
+	mockMatrixClient := &MockMatrixClient{}
+	mockSlackClient := &MockSlackClient{}
+
+	db := makeStore(t)
+	rooms, err := NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "BOWLINGALLEY")
+
+	echoSuppresser := common.NewEchoSuppresser()
+	users, err := NewUserMap(db, http.Client{}, rooms, echoSuppresser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users.Link(matrix.NewUser("@sean:st.andrews", mockMatrixClient), &slack.User{"U35", mockSlackClient})
+	users.Link(matrix.NewUser("@mallory:st.andrews", mockMatrixClient), &slack.User{"U36", mockSlackClient})
+
+	var powerLevelRequests int
+	client := http.Client{
+		Transport: &spyRoundTripper{func(req *http.Request) string {
+			if !strings.HasSuffix(req.URL.Path, "/state/m.room.power_levels") {
+				t.Errorf("Unexpected request to %v", req.URL)
+			}
+			powerLevelRequests++
+			return `{"users": {"@sean:st.andrews": 10}, "notifications": {"room": 10}}`
+		}},
+	}
+	bridge := Bridge{users, rooms, NewMessageMap(db), nil, nil, client, echoSuppresser, Config{
+		HomeserverBaseURL: "https://matrix.org",
+	}}
+	for i, m := range []struct {
+		userID  string
+		content string
+	}{
+		{"@sean:st.andrews", `{"msgtype": "m.text", "body": "@room bowling tonight"}`},
+		{"@sean:st.andrews", `{"msgtype": "m.text", "body": "bowling tonight", "m.mentions": {"room": true}}`},
+		{"@sean:st.andrews", `{"msgtype": "m.text", "body": "@room is not a ping", "m.mentions": {}}`},
+		{"@mallory:st.andrews", `{"msgtype": "m.text", "body": "@room bowling tonight"}`},
+		{"@sean:st.andrews", `{"msgtype": "m.text", "body": "> <@sean:st.andrews> @room bowling tonight\n\n@room bring shoes", "m.relates_to": {"m.in_reply_to": {"event_id": "$event0"}}}`},
+	} {
+		bridge.OnMatrixRoomMessage(matrix.RoomMessage{
+			Type:    "m.room.message",
+			Content: []byte(m.content),
+			UserID:  m.userID,
+			RoomID:  "!abc123:matrix.org",
+			EventID: fmt.Sprintf("$event%d", i),
+		})
+	}
+
+	want := []call{
+		call{"SendText", []interface{}{"BOWLINGALLEY", "<!channel> bowling tonight"}},
+		call{"SendText", []interface{}{"BOWLINGALLEY", "<!channel> bowling tonight"}},
+		call{"SendText", []interface{}{"BOWLINGALLEY", "@room is not a ping"}},
+		call{"SendText", []interface{}{"BOWLINGALLEY", "@room bowling tonight"}},
+		call{"SendThreadText", []interface{}{"BOWLINGALLEY", "1.000", "<!channel> bring shoes"}},
+	}
+	if !reflect.DeepEqual(mockSlackClient.calls, want) {
+		t.Fatalf("Wrong Slack calls, want %v got %v", want, mockSlackClient.calls)
+	}
+	// Replies are only converted once, without their fallback.
+	if powerLevelRequests != 4 {
+		t.Errorf("Fetched power levels %d times, want 4", powerLevelRequests)
+	}
+}
+
 func TestMatrixImageMessage(t *testing.T) {
 	mockMatrixClient := &MockMatrixClient{}
 	mockSlackClient := &MockSlackClient{}
 
-	db := makeDB(t)
+	db := makeStore(t)
 	rooms, err := NewRoomMap(db)
 	if err != nil {
 		t.Fatal(err)
@@ -201,7 +1473,7 @@ func TestMatrixImageMessage(t *testing.T) {
 	slackUser := &slack.User{"U35", mockSlackClient}
 	users.Link(matrixUser, slackUser)
 
-	bridge := Bridge{users, rooms, nil, nil, http.Client{}, echoSuppresser, Config{
+	bridge := Bridge{users, rooms, NewMessageMap(db), nil, nil, http.Client{}, echoSuppresser, Config{
 		HomeserverBaseURL: "https://some.url:1234",
 	}}
 	bridge.OnMatrixRoomMessage(matrix.RoomMessage{
@@ -218,7 +1490,7 @@ func TestMatrixImageMessage(t *testing.T) {
 }
 
 func TestMatrixMessageFromUnlinkedUser(t *testing.T) {
-	db := makeDB(t)
+	db := makeStore(t)
 	rooms, err := NewRoomMap(db)
 	if err != nil {
 		t.Fatal(err)
@@ -271,7 +1543,7 @@ func TestMatrixMessageFromUnlinkedUser(t *testing.T) {
 	client := http.Client{
 		Transport: &spyRoundTripper{verify},
 	}
-	bridge := Bridge{users, rooms, slackRoomMembers, nil, client, echoSuppresser, Config{
+	bridge := Bridge{users, rooms, NewMessageMap(db), slackRoomMembers, nil, client, echoSuppresser, Config{
 		HomeserverBaseURL: "https://hs.url",
 	}}
 	bridge.OnMatrixRoomMessage(matrix.RoomMessage{
@@ -308,7 +1580,7 @@ func TestMatrixMessageFromUnlinkedUser(t *testing.T) {
 }
 
 func TestSlackMessageFromUnlinkedUser(t *testing.T) {
-	db := makeDB(t)
+	db := makeStore(t)
 	rooms, err := NewRoomMap(db)
 	if err != nil {
 		t.Fatal(err)
@@ -392,7 +1664,7 @@ func TestSlackMessageFromUnlinkedUser(t *testing.T) {
 		Transport: &spyRoundTripper{verify},
 	}
 	matrixUsers := matrix.NewUsers()
-	bridge := Bridge{users, rooms, slackRoomMembers, matrixUsers, client, echoSuppresser, Config{
+	bridge := Bridge{users, rooms, NewMessageMap(db), slackRoomMembers, matrixUsers, client, echoSuppresser, Config{
 		MatrixASAccessToken: asToken,
 		UserPrefix:          "@prefix_",
 		HomeserverBaseURL:   "https://my.server",
@@ -438,7 +1710,7 @@ func TestLoadsConfig(t *testing.T) {
 		t.Fatal(err)
 	}
 	file := path.Join(dir, "sqlite3.db")
-	db := makeDBAt(t, file)
+	db := makeStoreAt(t, file)
 	bridge := makeBridge(t, db)
 
 	slackMessage := &slack.Message{
@@ -456,17 +1728,14 @@ func TestLoadsConfig(t *testing.T) {
 		t.Fatalf("Error closing db: %v", err)
 	}
 
-	db, err = sql.Open("sqlite3", file)
-	if err != nil {
-		t.Fatal(err)
-	}
+	db = makeStoreAt(t, file)
 	bridge = makeBridge(t, db)
 	if bridge.RoomMap.ShouldNotify(slackMessage) {
 		t.Errorf("want should not notify, got should notify")
 	}
 }
 
-func makeBridge(t *testing.T, db *sql.DB) *Bridge {
+func makeBridge(t *testing.T, db store.Store) *Bridge {
 	mockMatrixClient := &MockMatrixClient{}
 	mockSlackClient := &MockSlackClient{}
 
@@ -489,5 +1758,5 @@ func makeBridge(t *testing.T, db *sql.DB) *Bridge {
 		t.Fatalf("Error linking rooms: %v", err)
 	}
 
-	return &Bridge{users, rooms, nil, nil, http.Client{}, echoSuppresser, Config{}}
+	return &Bridge{users, rooms, NewMessageMap(db), nil, nil, http.Client{}, echoSuppresser, Config{}}
 }
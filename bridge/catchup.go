@@ -0,0 +1,48 @@
+package bridge
+
+import (
+	"log"
+
+	"github.com/matrix-org/slackbridge/slack"
+)
+
+// CatchUpSlack replays the Slack messages sent to each linked channel since
+// the last one the bridge saw, so that nothing said while the bridge was down
+// or disconnected is lost. It should be called before live events resume.
+// Channels which have never seen a message are skipped, rather than having
+// their entire history replayed.
+func (b *Bridge) CatchUpSlack() {
+	for _, channel := range b.RoomMap.SlackChannels() {
+		b.catchUpSlackChannel(channel)
+	}
+}
+
+func (b *Bridge) catchUpSlackChannel(channel string) {
+	oldest := b.RoomMap.LastSlackTimestamp(channel)
+	if oldest == "" {
+		return
+	}
+	user := b.SlackRoomMembers.Any(channel)
+	if user == nil {
+		log.Printf("Not catching up on slack channel %q: no slack users to read it as", channel)
+		return
+	}
+	messages, err := slack.History(b.Client, user.Client.AccessToken(), channel, oldest, b.Config.SlackCatchUpLimit)
+	if err != nil {
+		log.Printf("Error catching up on slack channel %q: %v", channel, err)
+		return
+	}
+	log.Printf("Catching up on %d messages in slack channel %q", len(messages), channel)
+	for _, m := range messages {
+		if !b.RoomMap.ShouldNotify(&m) {
+			continue
+		}
+		// Messages sent from Matrix while we were disconnected are already
+		// there. Those we sent as unlinked Matrix users are bot messages,
+		// whose echoes are suppressed when they arrive live.
+		if m.Subtype == "bot_message" || len(b.MessageMap.MatrixForSlack(channel, m.TS)) > 0 {
+			continue
+		}
+		b.OnSlackMessage(m)
+	}
+}
@@ -0,0 +1,79 @@
+package bridge
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/matrix-org/slackbridge/common"
+	"github.com/matrix-org/slackbridge/matrix"
+	"github.com/matrix-org/slackbridge/slack"
+)
+
+func TestCatchUpSlack(t *testing.T) {
+	mockMatrixClient := &MockMatrixClient{}
+
+	db := makeStore(t)
+	rooms, err := NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "CANTINA")
+	rooms.Link(matrix.NewRoom("!def456:matrix.org"), "BOWLINGALLEY")
+	rooms.ShouldNotify(&slack.Message{Type: "message", Channel: "CANTINA", TS: "1.000"})
+
+	echoSuppresser := common.NewEchoSuppresser()
+	users, err := NewUserMap(db, http.Client{}, rooms, echoSuppresser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slackUser := &slack.User{"U34", &MockSlackClient{}}
+	users.Link(matrix.NewUser("@nancy:st.andrews", mockMatrixClient), slackUser)
+
+	slackRoomMembers := slack.NewRoomMembers()
+	slackRoomMembers.Add("CANTINA", slackUser)
+	slackRoomMembers.Add("BOWLINGALLEY", slackUser)
+
+	client := http.Client{
+		Transport: &spyRoundTripper{func(req *http.Request) string {
+			if req.URL.Path != "/api/conversations.history" {
+				t.Fatalf("Got request to unexpected path %q", req.URL.Path)
+			}
+			req.ParseForm()
+			if got := req.Form.Get("channel"); got != "CANTINA" {
+				t.Errorf("Channel: want %q got %q", "CANTINA", got)
+			}
+			assertUrlValueEquals(t, req.Form, "oldest", "1.000")
+			return `{"ok": true, "has_more": false, "messages": [
+				{"type": "message", "ts": "3.000", "user": "U34", "text": "three"},
+				{"type": "message", "subtype": "bot_message", "ts": "2.700", "bot_id": "B01", "username": "Vivian", "text": "from an unlinked matrix user"},
+				{"type": "message", "ts": "2.500", "user": "U34", "text": "from matrix"},
+				{"type": "message", "ts": "2.000", "user": "U34", "text": "two"},
+				{"type": "message", "ts": "1.000", "user": "U34", "text": "one"}
+			]}`
+		}},
+	}
+
+	bridge := &Bridge{
+		UserMap:              users,
+		RoomMap:              rooms,
+		MessageMap:           NewMessageMap(db),
+		SlackRoomMembers:     slackRoomMembers,
+		Client:               client,
+		MatrixEchoSuppresser: echoSuppresser,
+	}
+	// Sent from Matrix while the slack connection was down.
+	bridge.MessageMap.Add("CANTINA", "2.500", "!abc123:matrix.org", "$sent", "@nancy:st.andrews")
+	bridge.CatchUpSlack()
+
+	want := []call{
+		call{"SendText", []interface{}{"!abc123:matrix.org", "two", ""}},
+		call{"SendText", []interface{}{"!abc123:matrix.org", "three", ""}},
+	}
+	if !reflect.DeepEqual(mockMatrixClient.calls, want) {
+		t.Fatalf("Wrong Matrix calls, want %v got %v", want, mockMatrixClient.calls)
+	}
+	if got := rooms.LastSlackTimestamp("CANTINA"); got != "3.000" {
+		t.Errorf("Last slack timestamp: want %q got %q", "3.000", got)
+	}
+}
@@ -0,0 +1,1528 @@
+// This file was generated by external/emoji-data/main.go -shortcodes - do not edit it manually
+package bridge
+
+var emojiShortcodes = map[string]string{
+	"#\u20e3":              "hash",
+	"#\ufe0f\u20e3":        "hash",
+	"0\u20e3":              "zero",
+	"0\ufe0f\u20e3":        "zero",
+	"1\u20e3":              "one",
+	"1\ufe0f\u20e3":        "one",
+	"2\u20e3":              "two",
+	"2\ufe0f\u20e3":        "two",
+	"3\u20e3":              "three",
+	"3\ufe0f\u20e3":        "three",
+	"4\u20e3":              "four",
+	"4\ufe0f\u20e3":        "four",
+	"5\u20e3":              "five",
+	"5\ufe0f\u20e3":        "five",
+	"6\u20e3":              "six",
+	"6\ufe0f\u20e3":        "six",
+	"7\u20e3":              "seven",
+	"7\ufe0f\u20e3":        "seven",
+	"8\u20e3":              "eight",
+	"8\ufe0f\u20e3":        "eight",
+	"9\u20e3":              "nine",
+	"9\ufe0f\u20e3":        "nine",
+	"\u00a9":               "copyright",
+	"\u00a9\ufe0f":         "copyright",
+	"\u00ae":               "registered",
+	"\u00ae\ufe0f":         "registered",
+	"\u203c":               "bangbang",
+	"\u203c\ufe0f":         "bangbang",
+	"\u2049":               "interrobang",
+	"\u2049\ufe0f":         "interrobang",
+	"\u2122":               "tm",
+	"\u2122\ufe0f":         "tm",
+	"\u2139":               "information_source",
+	"\u2139\ufe0f":         "information_source",
+	"\u2194":               "left_right_arrow",
+	"\u2194\ufe0f":         "left_right_arrow",
+	"\u2195":               "arrow_up_down",
+	"\u2195\ufe0f":         "arrow_up_down",
+	"\u2196":               "arrow_upper_left",
+	"\u2196\ufe0f":         "arrow_upper_left",
+	"\u2197":               "arrow_upper_right",
+	"\u2197\ufe0f":         "arrow_upper_right",
+	"\u2198":               "arrow_lower_right",
+	"\u2198\ufe0f":         "arrow_lower_right",
+	"\u2199":               "arrow_lower_left",
+	"\u2199\ufe0f":         "arrow_lower_left",
+	"\u21a9":               "leftwards_arrow_with_hook",
+	"\u21a9\ufe0f":         "leftwards_arrow_with_hook",
+	"\u21aa":               "arrow_right_hook",
+	"\u21aa\ufe0f":         "arrow_right_hook",
+	"\u231a":               "watch",
+	"\u231a\ufe0f":         "watch",
+	"\u231b":               "hourglass",
+	"\u231b\ufe0f":         "hourglass",
+	"\u23e9":               "fast_forward",
+	"\u23ea":               "rewind",
+	"\u23eb":               "arrow_double_up",
+	"\u23ec":               "arrow_double_down",
+	"\u23f0":               "alarm_clock",
+	"\u23f3":               "hourglass_flowing_sand",
+	"\u24c2":               "m",
+	"\u24c2\ufe0f":         "m",
+	"\u25aa":               "black_small_square",
+	"\u25aa\ufe0f":         "black_small_square",
+	"\u25ab":               "white_small_square",
+	"\u25ab\ufe0f":         "white_small_square",
+	"\u25b6":               "arrow_forward",
+	"\u25b6\ufe0f":         "arrow_forward",
+	"\u25c0":               "arrow_backward",
+	"\u25c0\ufe0f":         "arrow_backward",
+	"\u25fb":               "white_medium_square",
+	"\u25fb\ufe0f":         "white_medium_square",
+	"\u25fc":               "black_medium_square",
+	"\u25fc\ufe0f":         "black_medium_square",
+	"\u25fd":               "white_medium_small_square",
+	"\u25fd\ufe0f":         "white_medium_small_square",
+	"\u25fe":               "black_medium_small_square",
+	"\u25fe\ufe0f":         "black_medium_small_square",
+	"\u2600":               "sunny",
+	"\u2600\ufe0f":         "sunny",
+	"\u2601":               "cloud",
+	"\u2601\ufe0f":         "cloud",
+	"\u260e":               "phone",
+	"\u260e\ufe0f":         "phone",
+	"\u2611":               "ballot_box_with_check",
+	"\u2611\ufe0f":         "ballot_box_with_check",
+	"\u2614":               "umbrella",
+	"\u2614\ufe0f":         "umbrella",
+	"\u2615":               "coffee",
+	"\u2615\ufe0f":         "coffee",
+	"\u261d":               "point_up",
+	"\u261d\ufe0f":         "point_up",
+	"\u261d\U0001f3fb":     "point_up::skin-tone-2",
+	"\u261d\U0001f3fc":     "point_up::skin-tone-3",
+	"\u261d\U0001f3fd":     "point_up::skin-tone-4",
+	"\u261d\U0001f3fe":     "point_up::skin-tone-5",
+	"\u261d\U0001f3ff":     "point_up::skin-tone-6",
+	"\u263a":               "relaxed",
+	"\u263a\ufe0f":         "relaxed",
+	"\u2648":               "aries",
+	"\u2648\ufe0f":         "aries",
+	"\u2649":               "taurus",
+	"\u2649\ufe0f":         "taurus",
+	"\u264a":               "gemini",
+	"\u264a\ufe0f":         "gemini",
+	"\u264b":               "cancer",
+	"\u264b\ufe0f":         "cancer",
+	"\u264c":               "leo",
+	"\u264c\ufe0f":         "leo",
+	"\u264d":               "virgo",
+	"\u264d\ufe0f":         "virgo",
+	"\u264e":               "libra",
+	"\u264e\ufe0f":         "libra",
+	"\u264f":               "scorpius",
+	"\u264f\ufe0f":         "scorpius",
+	"\u2650":               "sagittarius",
+	"\u2650\ufe0f":         "sagittarius",
+	"\u2651":               "capricorn",
+	"\u2651\ufe0f":         "capricorn",
+	"\u2652":               "aquarius",
+	"\u2652\ufe0f":         "aquarius",
+	"\u2653":               "pisces",
+	"\u2653\ufe0f":         "pisces",
+	"\u2660":               "spades",
+	"\u2660\ufe0f":         "spades",
+	"\u2663":               "clubs",
+	"\u2663\ufe0f":         "clubs",
+	"\u2665":               "hearts",
+	"\u2665\ufe0f":         "hearts",
+	"\u2666":               "diamonds",
+	"\u2666\ufe0f":         "diamonds",
+	"\u2668":               "hotsprings",
+	"\u2668\ufe0f":         "hotsprings",
+	"\u267b":               "recycle",
+	"\u267b\ufe0f":         "recycle",
+	"\u267f":               "wheelchair",
+	"\u267f\ufe0f":         "wheelchair",
+	"\u2693":               "anchor",
+	"\u2693\ufe0f":         "anchor",
+	"\u26a0":               "warning",
+	"\u26a0\ufe0f":         "warning",
+	"\u26a1":               "zap",
+	"\u26a1\ufe0f":         "zap",
+	"\u26aa":               "white_circle",
+	"\u26aa\ufe0f":         "white_circle",
+	"\u26ab":               "black_circle",
+	"\u26ab\ufe0f":         "black_circle",
+	"\u26bd":               "soccer",
+	"\u26bd\ufe0f":         "soccer",
+	"\u26be":               "baseball",
+	"\u26be\ufe0f":         "baseball",
+	"\u26c4":               "snowman",
+	"\u26c4\ufe0f":         "snowman",
+	"\u26c5":               "partly_sunny",
+	"\u26c5\ufe0f":         "partly_sunny",
+	"\u26ce":               "ophiuchus",
+	"\u26d4":               "no_entry",
+	"\u26d4\ufe0f":         "no_entry",
+	"\u26ea":               "church",
+	"\u26ea\ufe0f":         "church",
+	"\u26f2":               "fountain",
+	"\u26f2\ufe0f":         "fountain",
+	"\u26f3":               "golf",
+	"\u26f3\ufe0f":         "golf",
+	"\u26f5":               "boat",
+	"\u26f5\ufe0f":         "boat",
+	"\u26fa":               "tent",
+	"\u26fa\ufe0f":         "tent",
+	"\u26fd":               "fuelpump",
+	"\u26fd\ufe0f":         "fuelpump",
+	"\u2702":               "scissors",
+	"\u2702\ufe0f":         "scissors",
+	"\u2705":               "white_check_mark",
+	"\u2708":               "airplane",
+	"\u2708\ufe0f":         "airplane",
+	"\u2709":               "email",
+	"\u2709\ufe0f":         "email",
+	"\u270a":               "fist",
+	"\u270a\U0001f3fb":     "fist::skin-tone-2",
+	"\u270a\U0001f3fc":     "fist::skin-tone-3",
+	"\u270a\U0001f3fd":     "fist::skin-tone-4",
+	"\u270a\U0001f3fe":     "fist::skin-tone-5",
+	"\u270a\U0001f3ff":     "fist::skin-tone-6",
+	"\u270b":               "hand",
+	"\u270b\U0001f3fb":     "hand::skin-tone-2",
+	"\u270b\U0001f3fc":     "hand::skin-tone-3",
+	"\u270b\U0001f3fd":     "hand::skin-tone-4",
+	"\u270b\U0001f3fe":     "hand::skin-tone-5",
+	"\u270b\U0001f3ff":     "hand::skin-tone-6",
+	"\u270c":               "v",
+	"\u270c\ufe0f":         "v",
+	"\u270c\U0001f3fb":     "v::skin-tone-2",
+	"\u270c\U0001f3fc":     "v::skin-tone-3",
+	"\u270c\U0001f3fd":     "v::skin-tone-4",
+	"\u270c\U0001f3fe":     "v::skin-tone-5",
+	"\u270c\U0001f3ff":     "v::skin-tone-6",
+	"\u270f":               "pencil2",
+	"\u270f\ufe0f":         "pencil2",
+	"\u2712":               "black_nib",
+	"\u2712\ufe0f":         "black_nib",
+	"\u2714":               "heavy_check_mark",
+	"\u2714\ufe0f":         "heavy_check_mark",
+	"\u2716":               "heavy_multiplication_x",
+	"\u2716\ufe0f":         "heavy_multiplication_x",
+	"\u2728":               "sparkles",
+	"\u2733":               "eight_spoked_asterisk",
+	"\u2733\ufe0f":         "eight_spoked_asterisk",
+	"\u2734":               "eight_pointed_black_star",
+	"\u2734\ufe0f":         "eight_pointed_black_star",
+	"\u2744":               "snowflake",
+	"\u2744\ufe0f":         "snowflake",
+	"\u2747":               "sparkle",
+	"\u2747\ufe0f":         "sparkle",
+	"\u274c":               "x",
+	"\u274e":               "negative_squared_cross_mark",
+	"\u2753":               "question",
+	"\u2754":               "grey_question",
+	"\u2755":               "grey_exclamation",
+	"\u2757":               "exclamation",
+	"\u2757\ufe0f":         "exclamation",
+	"\u2764":               "heart",
+	"\u2764\ufe0f":         "heart",
+	"\u2795":               "heavy_plus_sign",
+	"\u2796":               "heavy_minus_sign",
+	"\u2797":               "heavy_division_sign",
+	"\u27a1":               "arrow_right",
+	"\u27a1\ufe0f":         "arrow_right",
+	"\u27b0":               "curly_loop",
+	"\u27bf":               "loop",
+	"\u2934":               "arrow_heading_up",
+	"\u2934\ufe0f":         "arrow_heading_up",
+	"\u2935":               "arrow_heading_down",
+	"\u2935\ufe0f":         "arrow_heading_down",
+	"\u2b05":               "arrow_left",
+	"\u2b05\ufe0f":         "arrow_left",
+	"\u2b06":               "arrow_up",
+	"\u2b06\ufe0f":         "arrow_up",
+	"\u2b07":               "arrow_down",
+	"\u2b07\ufe0f":         "arrow_down",
+	"\u2b1b":               "black_large_square",
+	"\u2b1b\ufe0f":         "black_large_square",
+	"\u2b1c":               "white_large_square",
+	"\u2b1c\ufe0f":         "white_large_square",
+	"\u2b50":               "star",
+	"\u2b50\ufe0f":         "star",
+	"\u2b55":               "o",
+	"\u2b55\ufe0f":         "o",
+	"\u3030":               "wavy_dash",
+	"\u3030\ufe0f":         "wavy_dash",
+	"\u303d":               "part_alternation_mark",
+	"\u303d\ufe0f":         "part_alternation_mark",
+	"\u3297":               "congratulations",
+	"\u3297\ufe0f":         "congratulations",
+	"\u3299":               "secret",
+	"\u3299\ufe0f":         "secret",
+	"\U0001f004":           "mahjong",
+	"\U0001f004\ufe0f":     "mahjong",
+	"\U0001f0cf":           "black_joker",
+	"\U0001f170":           "a",
+	"\U0001f170\ufe0f":     "a",
+	"\U0001f171":           "b",
+	"\U0001f171\ufe0f":     "b",
+	"\U0001f17e":           "o2",
+	"\U0001f17e\ufe0f":     "o2",
+	"\U0001f17f":           "parking",
+	"\U0001f17f\ufe0f":     "parking",
+	"\U0001f18e":           "ab",
+	"\U0001f191":           "cl",
+	"\U0001f192":           "cool",
+	"\U0001f193":           "free",
+	"\U0001f194":           "id",
+	"\U0001f195":           "new",
+	"\U0001f196":           "ng",
+	"\U0001f197":           "ok",
+	"\U0001f198":           "sos",
+	"\U0001f199":           "up",
+	"\U0001f19a":           "vs",
+	"\U0001f1e6\U0001f1e8": "flag-ac",
+	"\U0001f1e6\U0001f1e9": "flag-ad",
+	"\U0001f1e6\U0001f1ea": "flag-ae",
+	"\U0001f1e6\U0001f1eb": "flag-af",
+	"\U0001f1e6\U0001f1ec": "flag-ag",
+	"\U0001f1e6\U0001f1ee": "flag-ai",
+	"\U0001f1e6\U0001f1f1": "flag-al",
+	"\U0001f1e6\U0001f1f2": "flag-am",
+	"\U0001f1e6\U0001f1f4": "flag-ao",
+	"\U0001f1e6\U0001f1f6": "flag-aq",
+	"\U0001f1e6\U0001f1f7": "flag-ar",
+	"\U0001f1e6\U0001f1f8": "flag-as",
+	"\U0001f1e6\U0001f1f9": "flag-at",
+	"\U0001f1e6\U0001f1fa": "flag-au",
+	"\U0001f1e6\U0001f1fc": "flag-aw",
+	"\U0001f1e6\U0001f1fd": "flag-ax",
+	"\U0001f1e6\U0001f1ff": "flag-az",
+	"\U0001f1e7\U0001f1e6": "flag-ba",
+	"\U0001f1e7\U0001f1e7": "flag-bb",
+	"\U0001f1e7\U0001f1e9": "flag-bd",
+	"\U0001f1e7\U0001f1ea": "flag-be",
+	"\U0001f1e7\U0001f1eb": "flag-bf",
+	"\U0001f1e7\U0001f1ec": "flag-bg",
+	"\U0001f1e7\U0001f1ed": "flag-bh",
+	"\U0001f1e7\U0001f1ee": "flag-bi",
+	"\U0001f1e7\U0001f1ef": "flag-bj",
+	"\U0001f1e7\U0001f1f1": "flag-bl",
+	"\U0001f1e7\U0001f1f2": "flag-bm",
+	"\U0001f1e7\U0001f1f3": "flag-bn",
+	"\U0001f1e7\U0001f1f4": "flag-bo",
+	"\U0001f1e7\U0001f1f6": "flag-bq",
+	"\U0001f1e7\U0001f1f7": "flag-br",
+	"\U0001f1e7\U0001f1f8": "flag-bs",
+	"\U0001f1e7\U0001f1f9": "flag-bt",
+	"\U0001f1e7\U0001f1fb": "flag-bv",
+	"\U0001f1e7\U0001f1fc": "flag-bw",
+	"\U0001f1e7\U0001f1fe": "flag-by",
+	"\U0001f1e7\U0001f1ff": "flag-bz",
+	"\U0001f1e8\U0001f1e6": "flag-ca",
+	"\U0001f1e8\U0001f1e8": "flag-cc",
+	"\U0001f1e8\U0001f1e9": "flag-cd",
+	"\U0001f1e8\U0001f1eb": "flag-cf",
+	"\U0001f1e8\U0001f1ec": "flag-cg",
+	"\U0001f1e8\U0001f1ed": "flag-ch",
+	"\U0001f1e8\U0001f1ee": "flag-ci",
+	"\U0001f1e8\U0001f1f0": "flag-ck",
+	"\U0001f1e8\U0001f1f1": "flag-cl",
+	"\U0001f1e8\U0001f1f2": "flag-cm",
+	"\U0001f1e8\U0001f1f3": "flag-cn",
+	"\U0001f1e8\U0001f1f4": "flag-co",
+	"\U0001f1e8\U0001f1f5": "flag-cp",
+	"\U0001f1e8\U0001f1f7": "flag-cr",
+	"\U0001f1e8\U0001f1fa": "flag-cu",
+	"\U0001f1e8\U0001f1fb": "flag-cv",
+	"\U0001f1e8\U0001f1fc": "flag-cw",
+	"\U0001f1e8\U0001f1fd": "flag-cx",
+	"\U0001f1e8\U0001f1fe": "flag-cy",
+	"\U0001f1e8\U0001f1ff": "flag-cz",
+	"\U0001f1e9\U0001f1ea": "flag-de",
+	"\U0001f1e9\U0001f1ec": "flag-dg",
+	"\U0001f1e9\U0001f1ef": "flag-dj",
+	"\U0001f1e9\U0001f1f0": "flag-dk",
+	"\U0001f1e9\U0001f1f2": "flag-dm",
+	"\U0001f1e9\U0001f1f4": "flag-do",
+	"\U0001f1e9\U0001f1ff": "flag-dz",
+	"\U0001f1ea\U0001f1e6": "flag-ea",
+	"\U0001f1ea\U0001f1e8": "flag-ec",
+	"\U0001f1ea\U0001f1ea": "flag-ee",
+	"\U0001f1ea\U0001f1ec": "flag-eg",
+	"\U0001f1ea\U0001f1ed": "flag-eh",
+	"\U0001f1ea\U0001f1f7": "flag-er",
+	"\U0001f1ea\U0001f1f8": "flag-es",
+	"\U0001f1ea\U0001f1f9": "flag-et",
+	"\U0001f1ea\U0001f1fa": "flag-eu",
+	"\U0001f1eb\U0001f1ee": "flag-fi",
+	"\U0001f1eb\U0001f1ef": "flag-fj",
+	"\U0001f1eb\U0001f1f0": "flag-fk",
+	"\U0001f1eb\U0001f1f2": "flag-fm",
+	"\U0001f1eb\U0001f1f4": "flag-fo",
+	"\U0001f1eb\U0001f1f7": "flag-fr",
+	"\U0001f1ec\U0001f1e6": "flag-ga",
+	"\U0001f1ec\U0001f1e7": "flag-gb",
+	"\U0001f1ec\U0001f1e9": "flag-gd",
+	"\U0001f1ec\U0001f1ea": "flag-ge",
+	"\U0001f1ec\U0001f1eb": "flag-gf",
+	"\U0001f1ec\U0001f1ec": "flag-gg",
+	"\U0001f1ec\U0001f1ed": "flag-gh",
+	"\U0001f1ec\U0001f1ee": "flag-gi",
+	"\U0001f1ec\U0001f1f1": "flag-gl",
+	"\U0001f1ec\U0001f1f2": "flag-gm",
+	"\U0001f1ec\U0001f1f3": "flag-gn",
+	"\U0001f1ec\U0001f1f5": "flag-gp",
+	"\U0001f1ec\U0001f1f6": "flag-gq",
+	"\U0001f1ec\U0001f1f7": "flag-gr",
+	"\U0001f1ec\U0001f1f8": "flag-gs",
+	"\U0001f1ec\U0001f1f9": "flag-gt",
+	"\U0001f1ec\U0001f1fa": "flag-gu",
+	"\U0001f1ec\U0001f1fc": "flag-gw",
+	"\U0001f1ec\U0001f1fe": "flag-gy",
+	"\U0001f1ed\U0001f1f0": "flag-hk",
+	"\U0001f1ed\U0001f1f2": "flag-hm",
+	"\U0001f1ed\U0001f1f3": "flag-hn",
+	"\U0001f1ed\U0001f1f7": "flag-hr",
+	"\U0001f1ed\U0001f1f9": "flag-ht",
+	"\U0001f1ed\U0001f1fa": "flag-hu",
+	"\U0001f1ee\U0001f1e8": "flag-ic",
+	"\U0001f1ee\U0001f1e9": "flag-id",
+	"\U0001f1ee\U0001f1ea": "flag-ie",
+	"\U0001f1ee\U0001f1f1": "flag-il",
+	"\U0001f1ee\U0001f1f2": "flag-im",
+	"\U0001f1ee\U0001f1f3": "flag-in",
+	"\U0001f1ee\U0001f1f4": "flag-io",
+	"\U0001f1ee\U0001f1f6": "flag-iq",
+	"\U0001f1ee\U0001f1f7": "flag-ir",
+	"\U0001f1ee\U0001f1f8": "flag-is",
+	"\U0001f1ee\U0001f1f9": "flag-it",
+	"\U0001f1ef\U0001f1ea": "flag-je",
+	"\U0001f1ef\U0001f1f2": "flag-jm",
+	"\U0001f1ef\U0001f1f4": "flag-jo",
+	"\U0001f1ef\U0001f1f5": "flag-jp",
+	"\U0001f1f0\U0001f1ea": "flag-ke",
+	"\U0001f1f0\U0001f1ec": "flag-kg",
+	"\U0001f1f0\U0001f1ed": "flag-kh",
+	"\U0001f1f0\U0001f1ee": "flag-ki",
+	"\U0001f1f0\U0001f1f2": "flag-km",
+	"\U0001f1f0\U0001f1f3": "flag-kn",
+	"\U0001f1f0\U0001f1f5": "flag-kp",
+	"\U0001f1f0\U0001f1f7": "flag-kr",
+	"\U0001f1f0\U0001f1fc": "flag-kw",
+	"\U0001f1f0\U0001f1fe": "flag-ky",
+	"\U0001f1f0\U0001f1ff": "flag-kz",
+	"\U0001f1f1\U0001f1e6": "flag-la",
+	"\U0001f1f1\U0001f1e7": "flag-lb",
+	"\U0001f1f1\U0001f1e8": "flag-lc",
+	"\U0001f1f1\U0001f1ee": "flag-li",
+	"\U0001f1f1\U0001f1f0": "flag-lk",
+	"\U0001f1f1\U0001f1f7": "flag-lr",
+	"\U0001f1f1\U0001f1f8": "flag-ls",
+	"\U0001f1f1\U0001f1f9": "flag-lt",
+	"\U0001f1f1\U0001f1fa": "flag-lu",
+	"\U0001f1f1\U0001f1fb": "flag-lv",
+	"\U0001f1f1\U0001f1fe": "flag-ly",
+	"\U0001f1f2\U0001f1e6": "flag-ma",
+	"\U0001f1f2\U0001f1e8": "flag-mc",
+	"\U0001f1f2\U0001f1e9": "flag-md",
+	"\U0001f1f2\U0001f1ea": "flag-me",
+	"\U0001f1f2\U0001f1eb": "flag-mf",
+	"\U0001f1f2\U0001f1ec": "flag-mg",
+	"\U0001f1f2\U0001f1ed": "flag-mh",
+	"\U0001f1f2\U0001f1f0": "flag-mk",
+	"\U0001f1f2\U0001f1f1": "flag-ml",
+	"\U0001f1f2\U0001f1f2": "flag-mm",
+	"\U0001f1f2\U0001f1f3": "flag-mn",
+	"\U0001f1f2\U0001f1f4": "flag-mo",
+	"\U0001f1f2\U0001f1f5": "flag-mp",
+	"\U0001f1f2\U0001f1f6": "flag-mq",
+	"\U0001f1f2\U0001f1f7": "flag-mr",
+	"\U0001f1f2\U0001f1f8": "flag-ms",
+	"\U0001f1f2\U0001f1f9": "flag-mt",
+	"\U0001f1f2\U0001f1fa": "flag-mu",
+	"\U0001f1f2\U0001f1fb": "flag-mv",
+	"\U0001f1f2\U0001f1fc": "flag-mw",
+	"\U0001f1f2\U0001f1fd": "flag-mx",
+	"\U0001f1f2\U0001f1fe": "flag-my",
+	"\U0001f1f2\U0001f1ff": "flag-mz",
+	"\U0001f1f3\U0001f1e6": "flag-na",
+	"\U0001f1f3\U0001f1e8": "flag-nc",
+	"\U0001f1f3\U0001f1ea": "flag-ne",
+	"\U0001f1f3\U0001f1eb": "flag-nf",
+	"\U0001f1f3\U0001f1ec": "flag-ng",
+	"\U0001f1f3\U0001f1ee": "flag-ni",
+	"\U0001f1f3\U0001f1f1": "flag-nl",
+	"\U0001f1f3\U0001f1f4": "flag-no",
+	"\U0001f1f3\U0001f1f5": "flag-np",
+	"\U0001f1f3\U0001f1f7": "flag-nr",
+	"\U0001f1f3\U0001f1fa": "flag-nu",
+	"\U0001f1f3\U0001f1ff": "flag-nz",
+	"\U0001f1f4\U0001f1f2": "flag-om",
+	"\U0001f1f5\U0001f1e6": "flag-pa",
+	"\U0001f1f5\U0001f1ea": "flag-pe",
+	"\U0001f1f5\U0001f1eb": "flag-pf",
+	"\U0001f1f5\U0001f1ec": "flag-pg",
+	"\U0001f1f5\U0001f1ed": "flag-ph",
+	"\U0001f1f5\U0001f1f0": "flag-pk",
+	"\U0001f1f5\U0001f1f1": "flag-pl",
+	"\U0001f1f5\U0001f1f2": "flag-pm",
+	"\U0001f1f5\U0001f1f3": "flag-pn",
+	"\U0001f1f5\U0001f1f7": "flag-pr",
+	"\U0001f1f5\U0001f1f8": "flag-ps",
+	"\U0001f1f5\U0001f1f9": "flag-pt",
+	"\U0001f1f5\U0001f1fc": "flag-pw",
+	"\U0001f1f5\U0001f1fe": "flag-py",
+	"\U0001f1f6\U0001f1e6": "flag-qa",
+	"\U0001f1f7\U0001f1ea": "flag-re",
+	"\U0001f1f7\U0001f1f4": "flag-ro",
+	"\U0001f1f7\U0001f1f8": "flag-rs",
+	"\U0001f1f7\U0001f1fa": "flag-ru",
+	"\U0001f1f7\U0001f1fc": "flag-rw",
+	"\U0001f1f8\U0001f1e6": "flag-sa",
+	"\U0001f1f8\U0001f1e7": "flag-sb",
+	"\U0001f1f8\U0001f1e8": "flag-sc",
+	"\U0001f1f8\U0001f1e9": "flag-sd",
+	"\U0001f1f8\U0001f1ea": "flag-se",
+	"\U0001f1f8\U0001f1ec": "flag-sg",
+	"\U0001f1f8\U0001f1ed": "flag-sh",
+	"\U0001f1f8\U0001f1ee": "flag-si",
+	"\U0001f1f8\U0001f1ef": "flag-sj",
+	"\U0001f1f8\U0001f1f0": "flag-sk",
+	"\U0001f1f8\U0001f1f1": "flag-sl",
+	"\U0001f1f8\U0001f1f2": "flag-sm",
+	"\U0001f1f8\U0001f1f3": "flag-sn",
+	"\U0001f1f8\U0001f1f4": "flag-so",
+	"\U0001f1f8\U0001f1f7": "flag-sr",
+	"\U0001f1f8\U0001f1f8": "flag-ss",
+	"\U0001f1f8\U0001f1f9": "flag-st",
+	"\U0001f1f8\U0001f1fb": "flag-sv",
+	"\U0001f1f8\U0001f1fd": "flag-sx",
+	"\U0001f1f8\U0001f1fe": "flag-sy",
+	"\U0001f1f8\U0001f1ff": "flag-sz",
+	"\U0001f1f9\U0001f1e6": "flag-ta",
+	"\U0001f1f9\U0001f1e8": "flag-tc",
+	"\U0001f1f9\U0001f1e9": "flag-td",
+	"\U0001f1f9\U0001f1eb": "flag-tf",
+	"\U0001f1f9\U0001f1ec": "flag-tg",
+	"\U0001f1f9\U0001f1ed": "flag-th",
+	"\U0001f1f9\U0001f1ef": "flag-tj",
+	"\U0001f1f9\U0001f1f0": "flag-tk",
+	"\U0001f1f9\U0001f1f1": "flag-tl",
+	"\U0001f1f9\U0001f1f2": "flag-tm",
+	"\U0001f1f9\U0001f1f3": "flag-tn",
+	"\U0001f1f9\U0001f1f4": "flag-to",
+	"\U0001f1f9\U0001f1f7": "flag-tr",
+	"\U0001f1f9\U0001f1f9": "flag-tt",
+	"\U0001f1f9\U0001f1fb": "flag-tv",
+	"\U0001f1f9\U0001f1fc": "flag-tw",
+	"\U0001f1f9\U0001f1ff": "flag-tz",
+	"\U0001f1fa\U0001f1e6": "flag-ua",
+	"\U0001f1fa\U0001f1ec": "flag-ug",
+	"\U0001f1fa\U0001f1f2": "flag-um",
+	"\U0001f1fa\U0001f1f8": "flag-us",
+	"\U0001f1fa\U0001f1fe": "flag-uy",
+	"\U0001f1fa\U0001f1ff": "flag-uz",
+	"\U0001f1fb\U0001f1e6": "flag-va",
+	"\U0001f1fb\U0001f1e8": "flag-vc",
+	"\U0001f1fb\U0001f1ea": "flag-ve",
+	"\U0001f1fb\U0001f1ec": "flag-vg",
+	"\U0001f1fb\U0001f1ee": "flag-vi",
+	"\U0001f1fb\U0001f1f3": "flag-vn",
+	"\U0001f1fb\U0001f1fa": "flag-vu",
+	"\U0001f1fc\U0001f1eb": "flag-wf",
+	"\U0001f1fc\U0001f1f8": "flag-ws",
+	"\U0001f1fd\U0001f1f0": "flag-xk",
+	"\U0001f1fe\U0001f1ea": "flag-ye",
+	"\U0001f1fe\U0001f1f9": "flag-yt",
+	"\U0001f1ff\U0001f1e6": "flag-za",
+	"\U0001f1ff\U0001f1f2": "flag-zm",
+	"\U0001f1ff\U0001f1fc": "flag-zw",
+	"\U0001f201":           "koko",
+	"\U0001f202":           "sa",
+	"\U0001f202\ufe0f":     "sa",
+	"\U0001f21a":           "u7121",
+	"\U0001f21a\ufe0f":     "u7121",
+	"\U0001f22f":           "u6307",
+	"\U0001f22f\ufe0f":     "u6307",
+	"\U0001f232":           "u7981",
+	"\U0001f233":           "u7a7a",
+	"\U0001f234":           "u5408",
+	"\U0001f235":           "u6e80",
+	"\U0001f236":           "u6709",
+	"\U0001f237":           "u6708",
+	"\U0001f237\ufe0f":     "u6708",
+	"\U0001f238":           "u7533",
+	"\U0001f239":           "u5272",
+	"\U0001f23a":           "u55b6",
+	"\U0001f250":           "ideograph_advantage",
+	"\U0001f251":           "accept",
+	"\U0001f300":           "cyclone",
+	"\U0001f301":           "foggy",
+	"\U0001f302":           "closed_umbrella",
+	"\U0001f303":           "night_with_stars",
+	"\U0001f304":           "sunrise_over_mountains",
+	"\U0001f305":           "sunrise",
+	"\U0001f306":           "city_sunset",
+	"\U0001f307":           "city_sunrise",
+	"\U0001f308":           "rainbow",
+	"\U0001f309":           "bridge_at_night",
+	"\U0001f30a":           "ocean",
+	"\U0001f30b":           "volcano",
+	"\U0001f30c":           "milky_way",
+	"\U0001f30d":           "earth_africa",
+	"\U0001f30e":           "earth_americas",
+	"\U0001f30f":           "earth_asia",
+	"\U0001f310":           "globe_with_meridians",
+	"\U0001f311":           "new_moon",
+	"\U0001f312":           "waxing_crescent_moon",
+	"\U0001f313":           "first_quarter_moon",
+	"\U0001f314":           "moon",
+	"\U0001f315":           "full_moon",
+	"\U0001f316":           "waning_gibbous_moon",
+	"\U0001f317":           "last_quarter_moon",
+	"\U0001f318":           "waning_crescent_moon",
+	"\U0001f319":           "crescent_moon",
+	"\U0001f31a":           "new_moon_with_face",
+	"\U0001f31b":           "first_quarter_moon_with_face",
+	"\U0001f31c":           "last_quarter_moon_with_face",
+	"\U0001f31d":           "full_moon_with_face",
+	"\U0001f31e":           "sun_with_face",
+	"\U0001f31f":           "star2",
+	"\U0001f320":           "stars",
+	"\U0001f330":           "chestnut",
+	"\U0001f331":           "seedling",
+	"\U0001f332":           "evergreen_tree",
+	"\U0001f333":           "deciduous_tree",
+	"\U0001f334":           "palm_tree",
+	"\U0001f335":           "cactus",
+	"\U0001f337":           "tulip",
+	"\U0001f338":           "cherry_blossom",
+	"\U0001f339":           "rose",
+	"\U0001f33a":           "hibiscus",
+	"\U0001f33b":           "sunflower",
+	"\U0001f33c":           "blossom",
+	"\U0001f33d":           "corn",
+	"\U0001f33e":           "ear_of_rice",
+	"\U0001f33f":           "herb",
+	"\U0001f340":           "four_leaf_clover",
+	"\U0001f341":           "maple_leaf",
+	"\U0001f342":           "fallen_leaf",
+	"\U0001f343":           "leaves",
+	"\U0001f344":           "mushroom",
+	"\U0001f345":           "tomato",
+	"\U0001f346":           "eggplant",
+	"\U0001f347":           "grapes",
+	"\U0001f348":           "melon",
+	"\U0001f349":           "watermelon",
+	"\U0001f34a":           "tangerine",
+	"\U0001f34b":           "lemon",
+	"\U0001f34c":           "banana",
+	"\U0001f34d":           "pineapple",
+	"\U0001f34e":           "apple",
+	"\U0001f34f":           "green_apple",
+	"\U0001f350":           "pear",
+	"\U0001f351":           "peach",
+	"\U0001f352":           "cherries",
+	"\U0001f353":           "strawberry",
+	"\U0001f354":           "hamburger",
+	"\U0001f355":           "pizza",
+	"\U0001f356":           "meat_on_bone",
+	"\U0001f357":           "poultry_leg",
+	"\U0001f358":           "rice_cracker",
+	"\U0001f359":           "rice_ball",
+	"\U0001f35a":           "rice",
+	"\U0001f35b":           "curry",
+	"\U0001f35c":           "ramen",
+	"\U0001f35d":           "spaghetti",
+	"\U0001f35e":           "bread",
+	"\U0001f35f":           "fries",
+	"\U0001f360":           "sweet_potato",
+	"\U0001f361":           "dango",
+	"\U0001f362":           "oden",
+	"\U0001f363":           "sushi",
+	"\U0001f364":           "fried_shrimp",
+	"\U0001f365":           "fish_cake",
+	"\U0001f366":           "icecream",
+	"\U0001f367":           "shaved_ice",
+	"\U0001f368":           "ice_cream",
+	"\U0001f369":           "doughnut",
+	"\U0001f36a":           "cookie",
+	"\U0001f36b":           "chocolate_bar",
+	"\U0001f36c":           "candy",
+	"\U0001f36d":           "lollipop",
+	"\U0001f36e":           "custard",
+	"\U0001f36f":           "honey_pot",
+	"\U0001f370":           "cake",
+	"\U0001f371":           "bento",
+	"\U0001f372":           "stew",
+	"\U0001f373":           "egg",
+	"\U0001f374":           "fork_and_knife",
+	"\U0001f375":           "tea",
+	"\U0001f376":           "sake",
+	"\U0001f377":           "wine_glass",
+	"\U0001f378":           "cocktail",
+	"\U0001f379":           "tropical_drink",
+	"\U0001f37a":           "beer",
+	"\U0001f37b":           "beers",
+	"\U0001f37c":           "baby_bottle",
+	"\U0001f380":           "ribbon",
+	"\U0001f381":           "gift",
+	"\U0001f382":           "birthday",
+	"\U0001f383":           "jack_o_lantern",
+	"\U0001f384":           "christmas_tree",
+	"\U0001f385":           "santa",
+	"\U0001f385\U0001f3fb": "santa::skin-tone-2",
+	"\U0001f385\U0001f3fc": "santa::skin-tone-3",
+	"\U0001f385\U0001f3fd": "santa::skin-tone-4",
+	"\U0001f385\U0001f3fe": "santa::skin-tone-5",
+	"\U0001f385\U0001f3ff": "santa::skin-tone-6",
+	"\U0001f386":           "fireworks",
+	"\U0001f387":           "sparkler",
+	"\U0001f388":           "balloon",
+	"\U0001f389":           "tada",
+	"\U0001f38a":           "confetti_ball",
+	"\U0001f38b":           "tanabata_tree",
+	"\U0001f38c":           "crossed_flags",
+	"\U0001f38d":           "bamboo",
+	"\U0001f38e":           "dolls",
+	"\U0001f38f":           "flags",
+	"\U0001f390":           "wind_chime",
+	"\U0001f391":           "rice_scene",
+	"\U0001f392":           "school_satchel",
+	"\U0001f393":           "mortar_board",
+	"\U0001f3a0":           "carousel_horse",
+	"\U0001f3a1":           "ferris_wheel",
+	"\U0001f3a2":           "roller_coaster",
+	"\U0001f3a3":           "fishing_pole_and_fish",
+	"\U0001f3a4":           "microphone",
+	"\U0001f3a5":           "movie_camera",
+	"\U0001f3a6":           "cinema",
+	"\U0001f3a7":           "headphones",
+	"\U0001f3a8":           "art",
+	"\U0001f3a9":           "tophat",
+	"\U0001f3aa":           "circus_tent",
+	"\U0001f3ab":           "ticket",
+	"\U0001f3ac":           "clapper",
+	"\U0001f3ad":           "performing_arts",
+	"\U0001f3ae":           "video_game",
+	"\U0001f3af":           "dart",
+	"\U0001f3b0":           "slot_machine",
+	"\U0001f3b1":           "8ball",
+	"\U0001f3b2":           "game_die",
+	"\U0001f3b3":           "bowling",
+	"\U0001f3b4":           "flower_playing_cards",
+	"\U0001f3b5":           "musical_note",
+	"\U0001f3b6":           "notes",
+	"\U0001f3b7":           "saxophone",
+	"\U0001f3b8":           "guitar",
+	"\U0001f3b9":           "musical_keyboard",
+	"\U0001f3ba":           "trumpet",
+	"\U0001f3bb":           "violin",
+	"\U0001f3bc":           "musical_score",
+	"\U0001f3bd":           "running_shirt_with_sash",
+	"\U0001f3be":           "tennis",
+	"\U0001f3bf":           "ski",
+	"\U0001f3c0":           "basketball",
+	"\U0001f3c1":           "checkered_flag",
+	"\U0001f3c2":           "snowboarder",
+	"\U0001f3c3":           "runner",
+	"\U0001f3c3\U0001f3fb": "runner::skin-tone-2",
+	"\U0001f3c3\U0001f3fc": "runner::skin-tone-3",
+	"\U0001f3c3\U0001f3fd": "runner::skin-tone-4",
+	"\U0001f3c3\U0001f3fe": "runner::skin-tone-5",
+	"\U0001f3c3\U0001f3ff": "runner::skin-tone-6",
+	"\U0001f3c4":           "surfer",
+	"\U0001f3c4\U0001f3fb": "surfer::skin-tone-2",
+	"\U0001f3c4\U0001f3fc": "surfer::skin-tone-3",
+	"\U0001f3c4\U0001f3fd": "surfer::skin-tone-4",
+	"\U0001f3c4\U0001f3fe": "surfer::skin-tone-5",
+	"\U0001f3c4\U0001f3ff": "surfer::skin-tone-6",
+	"\U0001f3c6":           "trophy",
+	"\U0001f3c7":           "horse_racing",
+	"\U0001f3c7\U0001f3fb": "horse_racing::skin-tone-2",
+	"\U0001f3c7\U0001f3fc": "horse_racing::skin-tone-3",
+	"\U0001f3c7\U0001f3fd": "horse_racing::skin-tone-4",
+	"\U0001f3c7\U0001f3fe": "horse_racing::skin-tone-5",
+	"\U0001f3c7\U0001f3ff": "horse_racing::skin-tone-6",
+	"\U0001f3c8":           "football",
+	"\U0001f3c9":           "rugby_football",
+	"\U0001f3ca":           "swimmer",
+	"\U0001f3ca\U0001f3fb": "swimmer::skin-tone-2",
+	"\U0001f3ca\U0001f3fc": "swimmer::skin-tone-3",
+	"\U0001f3ca\U0001f3fd": "swimmer::skin-tone-4",
+	"\U0001f3ca\U0001f3fe": "swimmer::skin-tone-5",
+	"\U0001f3ca\U0001f3ff": "swimmer::skin-tone-6",
+	"\U0001f3e0":           "house",
+	"\U0001f3e1":           "house_with_garden",
+	"\U0001f3e2":           "office",
+	"\U0001f3e3":           "post_office",
+	"\U0001f3e4":           "european_post_office",
+	"\U0001f3e5":           "hospital",
+	"\U0001f3e6":           "bank",
+	"\U0001f3e7":           "atm",
+	"\U0001f3e8":           "hotel",
+	"\U0001f3e9":           "love_hotel",
+	"\U0001f3ea":           "convenience_store",
+	"\U0001f3eb":           "school",
+	"\U0001f3ec":           "department_store",
+	"\U0001f3ed":           "factory",
+	"\U0001f3ee":           "izakaya_lantern",
+	"\U0001f3ef":           "japanese_castle",
+	"\U0001f3f0":           "european_castle",
+	"\U0001f3fb":           "skin-tone-2",
+	"\U0001f3fc":           "skin-tone-3",
+	"\U0001f3fd":           "skin-tone-4",
+	"\U0001f3fe":           "skin-tone-5",
+	"\U0001f3ff":           "skin-tone-6",
+	"\U0001f400":           "rat",
+	"\U0001f401":           "mouse2",
+	"\U0001f402":           "ox",
+	"\U0001f403":           "water_buffalo",
+	"\U0001f404":           "cow2",
+	"\U0001f405":           "tiger2",
+	"\U0001f406":           "leopard",
+	"\U0001f407":           "rabbit2",
+	"\U0001f408":           "cat2",
+	"\U0001f409":           "dragon",
+	"\U0001f40a":           "crocodile",
+	"\U0001f40b":           "whale2",
+	"\U0001f40c":           "snail",
+	"\U0001f40d":           "snake",
+	"\U0001f40e":           "racehorse",
+	"\U0001f40f":           "ram",
+	"\U0001f410":           "goat",
+	"\U0001f411":           "sheep",
+	"\U0001f412":           "monkey",
+	"\U0001f413":           "rooster",
+	"\U0001f414":           "chicken",
+	"\U0001f415":           "dog2",
+	"\U0001f416":           "pig2",
+	"\U0001f417":           "boar",
+	"\U0001f418":           "elephant",
+	"\U0001f419":           "octopus",
+	"\U0001f41a":           "shell",
+	"\U0001f41b":           "bug",
+	"\U0001f41c":           "ant",
+	"\U0001f41d":           "bee",
+	"\U0001f41e":           "beetle",
+	"\U0001f41f":           "fish",
+	"\U0001f420":           "tropical_fish",
+	"\U0001f421":           "blowfish",
+	"\U0001f422":           "turtle",
+	"\U0001f423":           "hatching_chick",
+	"\U0001f424":           "baby_chick",
+	"\U0001f425":           "hatched_chick",
+	"\U0001f426":           "bird",
+	"\U0001f427":           "penguin",
+	"\U0001f428":           "koala",
+	"\U0001f429":           "poodle",
+	"\U0001f42a":           "dromedary_camel",
+	"\U0001f42b":           "camel",
+	"\U0001f42c":           "dolphin",
+	"\U0001f42d":           "mouse",
+	"\U0001f42e":           "cow",
+	"\U0001f42f":           "tiger",
+	"\U0001f430":           "rabbit",
+	"\U0001f431":           "cat",
+	"\U0001f432":           "dragon_face",
+	"\U0001f433":           "whale",
+	"\U0001f434":           "horse",
+	"\U0001f435":           "monkey_face",
+	"\U0001f436":           "dog",
+	"\U0001f437":           "pig",
+	"\U0001f438":           "frog",
+	"\U0001f439":           "hamster",
+	"\U0001f43a":           "wolf",
+	"\U0001f43b":           "bear",
+	"\U0001f43c":           "panda_face",
+	"\U0001f43d":           "pig_nose",
+	"\U0001f43e":           "feet",
+	"\U0001f440":           "eyes",
+	"\U0001f442":           "ear",
+	"\U0001f442\U0001f3fb": "ear::skin-tone-2",
+	"\U0001f442\U0001f3fc": "ear::skin-tone-3",
+	"\U0001f442\U0001f3fd": "ear::skin-tone-4",
+	"\U0001f442\U0001f3fe": "ear::skin-tone-5",
+	"\U0001f442\U0001f3ff": "ear::skin-tone-6",
+	"\U0001f443":           "nose",
+	"\U0001f443\U0001f3fb": "nose::skin-tone-2",
+	"\U0001f443\U0001f3fc": "nose::skin-tone-3",
+	"\U0001f443\U0001f3fd": "nose::skin-tone-4",
+	"\U0001f443\U0001f3fe": "nose::skin-tone-5",
+	"\U0001f443\U0001f3ff": "nose::skin-tone-6",
+	"\U0001f444":           "lips",
+	"\U0001f445":           "tongue",
+	"\U0001f446":           "point_up_2",
+	"\U0001f446\U0001f3fb": "point_up_2::skin-tone-2",
+	"\U0001f446\U0001f3fc": "point_up_2::skin-tone-3",
+	"\U0001f446\U0001f3fd": "point_up_2::skin-tone-4",
+	"\U0001f446\U0001f3fe": "point_up_2::skin-tone-5",
+	"\U0001f446\U0001f3ff": "point_up_2::skin-tone-6",
+	"\U0001f447":           "point_down",
+	"\U0001f447\U0001f3fb": "point_down::skin-tone-2",
+	"\U0001f447\U0001f3fc": "point_down::skin-tone-3",
+	"\U0001f447\U0001f3fd": "point_down::skin-tone-4",
+	"\U0001f447\U0001f3fe": "point_down::skin-tone-5",
+	"\U0001f447\U0001f3ff": "point_down::skin-tone-6",
+	"\U0001f448":           "point_left",
+	"\U0001f448\U0001f3fb": "point_left::skin-tone-2",
+	"\U0001f448\U0001f3fc": "point_left::skin-tone-3",
+	"\U0001f448\U0001f3fd": "point_left::skin-tone-4",
+	"\U0001f448\U0001f3fe": "point_left::skin-tone-5",
+	"\U0001f448\U0001f3ff": "point_left::skin-tone-6",
+	"\U0001f449":           "point_right",
+	"\U0001f449\U0001f3fb": "point_right::skin-tone-2",
+	"\U0001f449\U0001f3fc": "point_right::skin-tone-3",
+	"\U0001f449\U0001f3fd": "point_right::skin-tone-4",
+	"\U0001f449\U0001f3fe": "point_right::skin-tone-5",
+	"\U0001f449\U0001f3ff": "point_right::skin-tone-6",
+	"\U0001f44a":           "facepunch",
+	"\U0001f44a\U0001f3fb": "facepunch::skin-tone-2",
+	"\U0001f44a\U0001f3fc": "facepunch::skin-tone-3",
+	"\U0001f44a\U0001f3fd": "facepunch::skin-tone-4",
+	"\U0001f44a\U0001f3fe": "facepunch::skin-tone-5",
+	"\U0001f44a\U0001f3ff": "facepunch::skin-tone-6",
+	"\U0001f44b":           "wave",
+	"\U0001f44b\U0001f3fb": "wave::skin-tone-2",
+	"\U0001f44b\U0001f3fc": "wave::skin-tone-3",
+	"\U0001f44b\U0001f3fd": "wave::skin-tone-4",
+	"\U0001f44b\U0001f3fe": "wave::skin-tone-5",
+	"\U0001f44b\U0001f3ff": "wave::skin-tone-6",
+	"\U0001f44c":           "ok_hand",
+	"\U0001f44c\U0001f3fb": "ok_hand::skin-tone-2",
+	"\U0001f44c\U0001f3fc": "ok_hand::skin-tone-3",
+	"\U0001f44c\U0001f3fd": "ok_hand::skin-tone-4",
+	"\U0001f44c\U0001f3fe": "ok_hand::skin-tone-5",
+	"\U0001f44c\U0001f3ff": "ok_hand::skin-tone-6",
+	"\U0001f44d":           "+1",
+	"\U0001f44d\U0001f3fb": "+1::skin-tone-2",
+	"\U0001f44d\U0001f3fc": "+1::skin-tone-3",
+	"\U0001f44d\U0001f3fd": "+1::skin-tone-4",
+	"\U0001f44d\U0001f3fe": "+1::skin-tone-5",
+	"\U0001f44d\U0001f3ff": "+1::skin-tone-6",
+	"\U0001f44e":           "-1",
+	"\U0001f44e\U0001f3fb": "-1::skin-tone-2",
+	"\U0001f44e\U0001f3fc": "-1::skin-tone-3",
+	"\U0001f44e\U0001f3fd": "-1::skin-tone-4",
+	"\U0001f44e\U0001f3fe": "-1::skin-tone-5",
+	"\U0001f44e\U0001f3ff": "-1::skin-tone-6",
+	"\U0001f44f":           "clap",
+	"\U0001f44f\U0001f3fb": "clap::skin-tone-2",
+	"\U0001f44f\U0001f3fc": "clap::skin-tone-3",
+	"\U0001f44f\U0001f3fd": "clap::skin-tone-4",
+	"\U0001f44f\U0001f3fe": "clap::skin-tone-5",
+	"\U0001f44f\U0001f3ff": "clap::skin-tone-6",
+	"\U0001f450":           "open_hands",
+	"\U0001f450\U0001f3fb": "open_hands::skin-tone-2",
+	"\U0001f450\U0001f3fc": "open_hands::skin-tone-3",
+	"\U0001f450\U0001f3fd": "open_hands::skin-tone-4",
+	"\U0001f450\U0001f3fe": "open_hands::skin-tone-5",
+	"\U0001f450\U0001f3ff": "open_hands::skin-tone-6",
+	"\U0001f451":           "crown",
+	"\U0001f452":           "womans_hat",
+	"\U0001f453":           "eyeglasses",
+	"\U0001f454":           "necktie",
+	"\U0001f455":           "shirt",
+	"\U0001f456":           "jeans",
+	"\U0001f457":           "dress",
+	"\U0001f458":           "kimono",
+	"\U0001f459":           "bikini",
+	"\U0001f45a":           "womans_clothes",
+	"\U0001f45b":           "purse",
+	"\U0001f45c":           "handbag",
+	"\U0001f45d":           "pouch",
+	"\U0001f45e":           "mans_shoe",
+	"\U0001f45f":           "athletic_shoe",
+	"\U0001f460":           "high_heel",
+	"\U0001f461":           "sandal",
+	"\U0001f462":           "boot",
+	"\U0001f463":           "footprints",
+	"\U0001f464":           "bust_in_silhouette",
+	"\U0001f465":           "busts_in_silhouette",
+	"\U0001f466":           "boy",
+	"\U0001f466\U0001f3fb": "boy::skin-tone-2",
+	"\U0001f466\U0001f3fc": "boy::skin-tone-3",
+	"\U0001f466\U0001f3fd": "boy::skin-tone-4",
+	"\U0001f466\U0001f3fe": "boy::skin-tone-5",
+	"\U0001f466\U0001f3ff": "boy::skin-tone-6",
+	"\U0001f467":           "girl",
+	"\U0001f467\U0001f3fb": "girl::skin-tone-2",
+	"\U0001f467\U0001f3fc": "girl::skin-tone-3",
+	"\U0001f467\U0001f3fd": "girl::skin-tone-4",
+	"\U0001f467\U0001f3fe": "girl::skin-tone-5",
+	"\U0001f467\U0001f3ff": "girl::skin-tone-6",
+	"\U0001f468":           "man",
+	"\U0001f468\u200d\u2764\ufe0f\u200d\U0001f468":                 "man-heart-man",
+	"\U0001f468\u200d\u2764\ufe0f\u200d\U0001f48b\u200d\U0001f468": "man-kiss-man",
+	"\U0001f468\u200d\U0001f468\u200d\U0001f466":                   "man-man-boy",
+	"\U0001f468\u200d\U0001f468\u200d\U0001f466\u200d\U0001f466":   "man-man-boy-boy",
+	"\U0001f468\u200d\U0001f468\u200d\U0001f467":                   "man-man-girl",
+	"\U0001f468\u200d\U0001f468\u200d\U0001f467\u200d\U0001f466":   "man-man-girl-boy",
+	"\U0001f468\u200d\U0001f468\u200d\U0001f467\u200d\U0001f467":   "man-man-girl-girl",
+	"\U0001f468\u200d\U0001f469\u200d\U0001f466":                   "man-woman-boy",
+	"\U0001f468\u200d\U0001f469\u200d\U0001f466\u200d\U0001f466":   "man-woman-boy-boy",
+	"\U0001f468\u200d\U0001f469\u200d\U0001f467":                   "man-woman-girl",
+	"\U0001f468\u200d\U0001f469\u200d\U0001f467\u200d\U0001f466":   "man-woman-girl-boy",
+	"\U0001f468\u200d\U0001f469\u200d\U0001f467\u200d\U0001f467":   "man-woman-girl-girl",
+	"\U0001f468\U0001f3fb":                                         "man::skin-tone-2",
+	"\U0001f468\U0001f3fc":                                         "man::skin-tone-3",
+	"\U0001f468\U0001f3fd":                                         "man::skin-tone-4",
+	"\U0001f468\U0001f3fe":                                         "man::skin-tone-5",
+	"\U0001f468\U0001f3ff":                                         "man::skin-tone-6",
+	"\U0001f469":                                                   "woman",
+	"\U0001f469\u200d\u2764\ufe0f\u200d\U0001f469":                 "woman-heart-woman",
+	"\U0001f469\u200d\u2764\ufe0f\u200d\U0001f48b\u200d\U0001f469": "woman-kiss-woman",
+	"\U0001f469\u200d\U0001f469\u200d\U0001f466":                   "woman-woman-boy",
+	"\U0001f469\u200d\U0001f469\u200d\U0001f466\u200d\U0001f466":   "woman-woman-boy-boy",
+	"\U0001f469\u200d\U0001f469\u200d\U0001f467":                   "woman-woman-girl",
+	"\U0001f469\u200d\U0001f469\u200d\U0001f467\u200d\U0001f466":   "woman-woman-girl-boy",
+	"\U0001f469\u200d\U0001f469\u200d\U0001f467\u200d\U0001f467":   "woman-woman-girl-girl",
+	"\U0001f469\U0001f3fb":                                         "woman::skin-tone-2",
+	"\U0001f469\U0001f3fc":                                         "woman::skin-tone-3",
+	"\U0001f469\U0001f3fd":                                         "woman::skin-tone-4",
+	"\U0001f469\U0001f3fe":                                         "woman::skin-tone-5",
+	"\U0001f469\U0001f3ff":                                         "woman::skin-tone-6",
+	"\U0001f46a":                                                   "family",
+	"\U0001f46b":                                                   "couple",
+	"\U0001f46c":                                                   "two_men_holding_hands",
+	"\U0001f46d":                                                   "two_women_holding_hands",
+	"\U0001f46e":                                                   "cop",
+	"\U0001f46e\U0001f3fb":                                         "cop::skin-tone-2",
+	"\U0001f46e\U0001f3fc":                                         "cop::skin-tone-3",
+	"\U0001f46e\U0001f3fd":                                         "cop::skin-tone-4",
+	"\U0001f46e\U0001f3fe":                                         "cop::skin-tone-5",
+	"\U0001f46e\U0001f3ff":                                         "cop::skin-tone-6",
+	"\U0001f46f":                                                   "dancers",
+	"\U0001f470":                                                   "bride_with_veil",
+	"\U0001f470\U0001f3fb":                                         "bride_with_veil::skin-tone-2",
+	"\U0001f470\U0001f3fc":                                         "bride_with_veil::skin-tone-3",
+	"\U0001f470\U0001f3fd":                                         "bride_with_veil::skin-tone-4",
+	"\U0001f470\U0001f3fe":                                         "bride_with_veil::skin-tone-5",
+	"\U0001f470\U0001f3ff":                                         "bride_with_veil::skin-tone-6",
+	"\U0001f471":                                                   "person_with_blond_hair",
+	"\U0001f471\U0001f3fb":                                         "person_with_blond_hair::skin-tone-2",
+	"\U0001f471\U0001f3fc":                                         "person_with_blond_hair::skin-tone-3",
+	"\U0001f471\U0001f3fd":                                         "person_with_blond_hair::skin-tone-4",
+	"\U0001f471\U0001f3fe":                                         "person_with_blond_hair::skin-tone-5",
+	"\U0001f471\U0001f3ff":                                         "person_with_blond_hair::skin-tone-6",
+	"\U0001f472":                                                   "man_with_gua_pi_mao",
+	"\U0001f472\U0001f3fb":                                         "man_with_gua_pi_mao::skin-tone-2",
+	"\U0001f472\U0001f3fc":                                         "man_with_gua_pi_mao::skin-tone-3",
+	"\U0001f472\U0001f3fd":                                         "man_with_gua_pi_mao::skin-tone-4",
+	"\U0001f472\U0001f3fe":                                         "man_with_gua_pi_mao::skin-tone-5",
+	"\U0001f472\U0001f3ff":                                         "man_with_gua_pi_mao::skin-tone-6",
+	"\U0001f473":                                                   "man_with_turban",
+	"\U0001f473\U0001f3fb":                                         "man_with_turban::skin-tone-2",
+	"\U0001f473\U0001f3fc":                                         "man_with_turban::skin-tone-3",
+	"\U0001f473\U0001f3fd":                                         "man_with_turban::skin-tone-4",
+	"\U0001f473\U0001f3fe":                                         "man_with_turban::skin-tone-5",
+	"\U0001f473\U0001f3ff":                                         "man_with_turban::skin-tone-6",
+	"\U0001f474":                                                   "older_man",
+	"\U0001f474\U0001f3fb":                                         "older_man::skin-tone-2",
+	"\U0001f474\U0001f3fc":                                         "older_man::skin-tone-3",
+	"\U0001f474\U0001f3fd":                                         "older_man::skin-tone-4",
+	"\U0001f474\U0001f3fe":                                         "older_man::skin-tone-5",
+	"\U0001f474\U0001f3ff":                                         "older_man::skin-tone-6",
+	"\U0001f475":                                                   "older_woman",
+	"\U0001f475\U0001f3fb":                                         "older_woman::skin-tone-2",
+	"\U0001f475\U0001f3fc":                                         "older_woman::skin-tone-3",
+	"\U0001f475\U0001f3fd":                                         "older_woman::skin-tone-4",
+	"\U0001f475\U0001f3fe":                                         "older_woman::skin-tone-5",
+	"\U0001f475\U0001f3ff":                                         "older_woman::skin-tone-6",
+	"\U0001f476":                                                   "baby",
+	"\U0001f476\U0001f3fb":                                         "baby::skin-tone-2",
+	"\U0001f476\U0001f3fc":                                         "baby::skin-tone-3",
+	"\U0001f476\U0001f3fd":                                         "baby::skin-tone-4",
+	"\U0001f476\U0001f3fe":                                         "baby::skin-tone-5",
+	"\U0001f476\U0001f3ff":                                         "baby::skin-tone-6",
+	"\U0001f477":                                                   "construction_worker",
+	"\U0001f477\U0001f3fb":                                         "construction_worker::skin-tone-2",
+	"\U0001f477\U0001f3fc":                                         "construction_worker::skin-tone-3",
+	"\U0001f477\U0001f3fd":                                         "construction_worker::skin-tone-4",
+	"\U0001f477\U0001f3fe":                                         "construction_worker::skin-tone-5",
+	"\U0001f477\U0001f3ff":                                         "construction_worker::skin-tone-6",
+	"\U0001f478":                                                   "princess",
+	"\U0001f478\U0001f3fb":                                         "princess::skin-tone-2",
+	"\U0001f478\U0001f3fc":                                         "princess::skin-tone-3",
+	"\U0001f478\U0001f3fd":                                         "princess::skin-tone-4",
+	"\U0001f478\U0001f3fe":                                         "princess::skin-tone-5",
+	"\U0001f478\U0001f3ff":                                         "princess::skin-tone-6",
+	"\U0001f479":                                                   "japanese_ogre",
+	"\U0001f47a":                                                   "japanese_goblin",
+	"\U0001f47b":                                                   "ghost",
+	"\U0001f47c":                                                   "angel",
+	"\U0001f47c\U0001f3fb":                                         "angel::skin-tone-2",
+	"\U0001f47c\U0001f3fc":                                         "angel::skin-tone-3",
+	"\U0001f47c\U0001f3fd":                                         "angel::skin-tone-4",
+	"\U0001f47c\U0001f3fe":                                         "angel::skin-tone-5",
+	"\U0001f47c\U0001f3ff":                                         "angel::skin-tone-6",
+	"\U0001f47d":                                                   "alien",
+	"\U0001f47e":                                                   "space_invader",
+	"\U0001f47f":                                                   "imp",
+	"\U0001f480":                                                   "skull",
+	"\U0001f481":                                                   "information_desk_person",
+	"\U0001f481\U0001f3fb":                                         "information_desk_person::skin-tone-2",
+	"\U0001f481\U0001f3fc":                                         "information_desk_person::skin-tone-3",
+	"\U0001f481\U0001f3fd":                                         "information_desk_person::skin-tone-4",
+	"\U0001f481\U0001f3fe":                                         "information_desk_person::skin-tone-5",
+	"\U0001f481\U0001f3ff":                                         "information_desk_person::skin-tone-6",
+	"\U0001f482":                                                   "guardsman",
+	"\U0001f482\U0001f3fb":                                         "guardsman::skin-tone-2",
+	"\U0001f482\U0001f3fc":                                         "guardsman::skin-tone-3",
+	"\U0001f482\U0001f3fd":                                         "guardsman::skin-tone-4",
+	"\U0001f482\U0001f3fe":                                         "guardsman::skin-tone-5",
+	"\U0001f482\U0001f3ff":                                         "guardsman::skin-tone-6",
+	"\U0001f483":                                                   "dancer",
+	"\U0001f483\U0001f3fb":                                         "dancer::skin-tone-2",
+	"\U0001f483\U0001f3fc":                                         "dancer::skin-tone-3",
+	"\U0001f483\U0001f3fd":                                         "dancer::skin-tone-4",
+	"\U0001f483\U0001f3fe":                                         "dancer::skin-tone-5",
+	"\U0001f483\U0001f3ff":                                         "dancer::skin-tone-6",
+	"\U0001f484":                                                   "lipstick",
+	"\U0001f485":                                                   "nail_care",
+	"\U0001f485\U0001f3fb":                                         "nail_care::skin-tone-2",
+	"\U0001f485\U0001f3fc":                                         "nail_care::skin-tone-3",
+	"\U0001f485\U0001f3fd":                                         "nail_care::skin-tone-4",
+	"\U0001f485\U0001f3fe":                                         "nail_care::skin-tone-5",
+	"\U0001f485\U0001f3ff":                                         "nail_care::skin-tone-6",
+	"\U0001f486":                                                   "massage",
+	"\U0001f486\U0001f3fb":                                         "massage::skin-tone-2",
+	"\U0001f486\U0001f3fc":                                         "massage::skin-tone-3",
+	"\U0001f486\U0001f3fd":                                         "massage::skin-tone-4",
+	"\U0001f486\U0001f3fe":                                         "massage::skin-tone-5",
+	"\U0001f486\U0001f3ff":                                         "massage::skin-tone-6",
+	"\U0001f487":                                                   "haircut",
+	"\U0001f487\U0001f3fb":                                         "haircut::skin-tone-2",
+	"\U0001f487\U0001f3fc":                                         "haircut::skin-tone-3",
+	"\U0001f487\U0001f3fd":                                         "haircut::skin-tone-4",
+	"\U0001f487\U0001f3fe":                                         "haircut::skin-tone-5",
+	"\U0001f487\U0001f3ff":                                         "haircut::skin-tone-6",
+	"\U0001f488":                                                   "barber",
+	"\U0001f489":                                                   "syringe",
+	"\U0001f48a":                                                   "pill",
+	"\U0001f48b":                                                   "kiss",
+	"\U0001f48c":                                                   "love_letter",
+	"\U0001f48d":                                                   "ring",
+	"\U0001f48e":                                                   "gem",
+	"\U0001f48f":                                                   "couplekiss",
+	"\U0001f490":                                                   "bouquet",
+	"\U0001f491":                                                   "couple_with_heart",
+	"\U0001f492":                                                   "wedding",
+	"\U0001f493":                                                   "heartbeat",
+	"\U0001f494":                                                   "broken_heart",
+	"\U0001f495":                                                   "two_hearts",
+	"\U0001f496":                                                   "sparkling_heart",
+	"\U0001f497":                                                   "heartpulse",
+	"\U0001f498":                                                   "cupid",
+	"\U0001f499":                                                   "blue_heart",
+	"\U0001f49a":                                                   "green_heart",
+	"\U0001f49b":                                                   "yellow_heart",
+	"\U0001f49c":                                                   "purple_heart",
+	"\U0001f49d":                                                   "gift_heart",
+	"\U0001f49e":                                                   "revolving_hearts",
+	"\U0001f49f":                                                   "heart_decoration",
+	"\U0001f4a0":                                                   "diamond_shape_with_a_dot_inside",
+	"\U0001f4a1":                                                   "bulb",
+	"\U0001f4a2":                                                   "anger",
+	"\U0001f4a3":                                                   "bomb",
+	"\U0001f4a4":                                                   "zzz",
+	"\U0001f4a5":                                                   "boom",
+	"\U0001f4a6":                                                   "sweat_drops",
+	"\U0001f4a7":                                                   "droplet",
+	"\U0001f4a8":                                                   "dash",
+	"\U0001f4a9":                                                   "hankey",
+	"\U0001f4aa":                                                   "muscle",
+	"\U0001f4aa\U0001f3fb":                                         "muscle::skin-tone-2",
+	"\U0001f4aa\U0001f3fc":                                         "muscle::skin-tone-3",
+	"\U0001f4aa\U0001f3fd":                                         "muscle::skin-tone-4",
+	"\U0001f4aa\U0001f3fe":                                         "muscle::skin-tone-5",
+	"\U0001f4aa\U0001f3ff":                                         "muscle::skin-tone-6",
+	"\U0001f4ab":                                                   "dizzy",
+	"\U0001f4ac":                                                   "speech_balloon",
+	"\U0001f4ad":                                                   "thought_balloon",
+	"\U0001f4ae":                                                   "white_flower",
+	"\U0001f4af":                                                   "100",
+	"\U0001f4b0":                                                   "moneybag",
+	"\U0001f4b1":                                                   "currency_exchange",
+	"\U0001f4b2":                                                   "heavy_dollar_sign",
+	"\U0001f4b3":                                                   "credit_card",
+	"\U0001f4b4":                                                   "yen",
+	"\U0001f4b5":                                                   "dollar",
+	"\U0001f4b6":                                                   "euro",
+	"\U0001f4b7":                                                   "pound",
+	"\U0001f4b8":                                                   "money_with_wings",
+	"\U0001f4b9":                                                   "chart",
+	"\U0001f4ba":                                                   "seat",
+	"\U0001f4bb":                                                   "computer",
+	"\U0001f4bc":                                                   "briefcase",
+	"\U0001f4bd":                                                   "minidisc",
+	"\U0001f4be":                                                   "floppy_disk",
+	"\U0001f4bf":                                                   "cd",
+	"\U0001f4c0":                                                   "dvd",
+	"\U0001f4c1":                                                   "file_folder",
+	"\U0001f4c2":                                                   "open_file_folder",
+	"\U0001f4c3":                                                   "page_with_curl",
+	"\U0001f4c4":                                                   "page_facing_up",
+	"\U0001f4c5":                                                   "date",
+	"\U0001f4c6":                                                   "calendar",
+	"\U0001f4c7":                                                   "card_index",
+	"\U0001f4c8":                                                   "chart_with_upwards_trend",
+	"\U0001f4c9":                                                   "chart_with_downwards_trend",
+	"\U0001f4ca":                                                   "bar_chart",
+	"\U0001f4cb":                                                   "clipboard",
+	"\U0001f4cc":                                                   "pushpin",
+	"\U0001f4cd":                                                   "round_pushpin",
+	"\U0001f4ce":                                                   "paperclip",
+	"\U0001f4cf":                                                   "straight_ruler",
+	"\U0001f4d0":                                                   "triangular_ruler",
+	"\U0001f4d1":                                                   "bookmark_tabs",
+	"\U0001f4d2":                                                   "ledger",
+	"\U0001f4d3":                                                   "notebook",
+	"\U0001f4d4":                                                   "notebook_with_decorative_cover",
+	"\U0001f4d5":                                                   "closed_book",
+	"\U0001f4d6":                                                   "book",
+	"\U0001f4d7":                                                   "green_book",
+	"\U0001f4d8":                                                   "blue_book",
+	"\U0001f4d9":                                                   "orange_book",
+	"\U0001f4da":                                                   "books",
+	"\U0001f4db":                                                   "name_badge",
+	"\U0001f4dc":                                                   "scroll",
+	"\U0001f4dd":                                                   "memo",
+	"\U0001f4de":                                                   "telephone_receiver",
+	"\U0001f4df":                                                   "pager",
+	"\U0001f4e0":                                                   "fax",
+	"\U0001f4e1":                                                   "satellite",
+	"\U0001f4e2":                                                   "loudspeaker",
+	"\U0001f4e3":                                                   "mega",
+	"\U0001f4e4":                                                   "outbox_tray",
+	"\U0001f4e5":                                                   "inbox_tray",
+	"\U0001f4e6":                                                   "package",
+	"\U0001f4e7":                                                   "e-mail",
+	"\U0001f4e8":                                                   "incoming_envelope",
+	"\U0001f4e9":                                                   "envelope_with_arrow",
+	"\U0001f4ea":                                                   "mailbox_closed",
+	"\U0001f4eb":                                                   "mailbox",
+	"\U0001f4ec":                                                   "mailbox_with_mail",
+	"\U0001f4ed":                                                   "mailbox_with_no_mail",
+	"\U0001f4ee":                                                   "postbox",
+	"\U0001f4ef":                                                   "postal_horn",
+	"\U0001f4f0":                                                   "newspaper",
+	"\U0001f4f1":                                                   "iphone",
+	"\U0001f4f2":                                                   "calling",
+	"\U0001f4f3":                                                   "vibration_mode",
+	"\U0001f4f4":                                                   "mobile_phone_off",
+	"\U0001f4f5":                                                   "no_mobile_phones",
+	"\U0001f4f6":                                                   "signal_strength",
+	"\U0001f4f7":                                                   "camera",
+	"\U0001f4f9":                                                   "video_camera",
+	"\U0001f4fa":                                                   "tv",
+	"\U0001f4fb":                                                   "radio",
+	"\U0001f4fc":                                                   "vhs",
+	"\U0001f500":                                                   "twisted_rightwards_arrows",
+	"\U0001f501":                                                   "repeat",
+	"\U0001f502":                                                   "repeat_one",
+	"\U0001f503":                                                   "arrows_clockwise",
+	"\U0001f504":                                                   "arrows_counterclockwise",
+	"\U0001f505":                                                   "low_brightness",
+	"\U0001f506":                                                   "high_brightness",
+	"\U0001f507":                                                   "mute",
+	"\U0001f508":                                                   "speaker",
+	"\U0001f509":                                                   "sound",
+	"\U0001f50a":                                                   "loud_sound",
+	"\U0001f50b":                                                   "battery",
+	"\U0001f50c":                                                   "electric_plug",
+	"\U0001f50d":                                                   "mag",
+	"\U0001f50e":                                                   "mag_right",
+	"\U0001f50f":                                                   "lock_with_ink_pen",
+	"\U0001f510":                                                   "closed_lock_with_key",
+	"\U0001f511":                                                   "key",
+	"\U0001f512":                                                   "lock",
+	"\U0001f513":                                                   "unlock",
+	"\U0001f514":                                                   "bell",
+	"\U0001f515":                                                   "no_bell",
+	"\U0001f516":                                                   "bookmark",
+	"\U0001f517":                                                   "link",
+	"\U0001f518":                                                   "radio_button",
+	"\U0001f519":                                                   "back",
+	"\U0001f51a":                                                   "end",
+	"\U0001f51b":                                                   "on",
+	"\U0001f51c":                                                   "soon",
+	"\U0001f51d":                                                   "top",
+	"\U0001f51e":                                                   "underage",
+	"\U0001f51f":                                                   "keycap_ten",
+	"\U0001f520":                                                   "capital_abcd",
+	"\U0001f521":                                                   "abcd",
+	"\U0001f522":                                                   "1234",
+	"\U0001f523":                                                   "symbols",
+	"\U0001f524":                                                   "abc",
+	"\U0001f525":                                                   "fire",
+	"\U0001f526":                                                   "flashlight",
+	"\U0001f527":                                                   "wrench",
+	"\U0001f528":                                                   "hammer",
+	"\U0001f529":                                                   "nut_and_bolt",
+	"\U0001f52a":                                                   "hocho",
+	"\U0001f52b":                                                   "gun",
+	"\U0001f52c":                                                   "microscope",
+	"\U0001f52d":                                                   "telescope",
+	"\U0001f52e":                                                   "crystal_ball",
+	"\U0001f52f":                                                   "six_pointed_star",
+	"\U0001f530":                                                   "beginner",
+	"\U0001f531":                                                   "trident",
+	"\U0001f532":                                                   "black_square_button",
+	"\U0001f533":                                                   "white_square_button",
+	"\U0001f534":                                                   "red_circle",
+	"\U0001f535":                                                   "large_blue_circle",
+	"\U0001f536":                                                   "large_orange_diamond",
+	"\U0001f537":                                                   "large_blue_diamond",
+	"\U0001f538":                                                   "small_orange_diamond",
+	"\U0001f539":                                                   "small_blue_diamond",
+	"\U0001f53a":                                                   "small_red_triangle",
+	"\U0001f53b":                                                   "small_red_triangle_down",
+	"\U0001f53c":                                                   "arrow_up_small",
+	"\U0001f53d":                                                   "arrow_down_small",
+	"\U0001f550":                                                   "clock1",
+	"\U0001f551":                                                   "clock2",
+	"\U0001f552":                                                   "clock3",
+	"\U0001f553":                                                   "clock4",
+	"\U0001f554":                                                   "clock5",
+	"\U0001f555":                                                   "clock6",
+	"\U0001f556":                                                   "clock7",
+	"\U0001f557":                                                   "clock8",
+	"\U0001f558":                                                   "clock9",
+	"\U0001f559":                                                   "clock10",
+	"\U0001f55a":                                                   "clock11",
+	"\U0001f55b":                                                   "clock12",
+	"\U0001f55c":                                                   "clock130",
+	"\U0001f55d":                                                   "clock230",
+	"\U0001f55e":                                                   "clock330",
+	"\U0001f55f":                                                   "clock430",
+	"\U0001f560":                                                   "clock530",
+	"\U0001f561":                                                   "clock630",
+	"\U0001f562":                                                   "clock730",
+	"\U0001f563":                                                   "clock830",
+	"\U0001f564":                                                   "clock930",
+	"\U0001f565":                                                   "clock1030",
+	"\U0001f566":                                                   "clock1130",
+	"\U0001f567":                                                   "clock1230",
+	"\U0001f596":                                                   "spock-hand",
+	"\U0001f596\U0001f3fb":                                         "spock-hand::skin-tone-2",
+	"\U0001f596\U0001f3fc":                                         "spock-hand::skin-tone-3",
+	"\U0001f596\U0001f3fd":                                         "spock-hand::skin-tone-4",
+	"\U0001f596\U0001f3fe":                                         "spock-hand::skin-tone-5",
+	"\U0001f596\U0001f3ff":                                         "spock-hand::skin-tone-6",
+	"\U0001f5fb":                                                   "mount_fuji",
+	"\U0001f5fc":                                                   "tokyo_tower",
+	"\U0001f5fd":                                                   "statue_of_liberty",
+	"\U0001f5fe":                                                   "japan",
+	"\U0001f5ff":                                                   "moyai",
+	"\U0001f600":                                                   "grinning",
+	"\U0001f601":                                                   "grin",
+	"\U0001f602":                                                   "joy",
+	"\U0001f603":                                                   "smiley",
+	"\U0001f604":                                                   "smile",
+	"\U0001f605":                                                   "sweat_smile",
+	"\U0001f606":                                                   "laughing",
+	"\U0001f607":                                                   "innocent",
+	"\U0001f608":                                                   "smiling_imp",
+	"\U0001f609":                                                   "wink",
+	"\U0001f60a":                                                   "blush",
+	"\U0001f60b":                                                   "yum",
+	"\U0001f60c":                                                   "relieved",
+	"\U0001f60d":                                                   "heart_eyes",
+	"\U0001f60e":                                                   "sunglasses",
+	"\U0001f60f":                                                   "smirk",
+	"\U0001f610":                                                   "neutral_face",
+	"\U0001f611":                                                   "expressionless",
+	"\U0001f612":                                                   "unamused",
+	"\U0001f613":                                                   "sweat",
+	"\U0001f614":                                                   "pensive",
+	"\U0001f615":                                                   "confused",
+	"\U0001f616":                                                   "confounded",
+	"\U0001f617":                                                   "kissing",
+	"\U0001f618":                                                   "kissing_heart",
+	"\U0001f619":                                                   "kissing_smiling_eyes",
+	"\U0001f61a":                                                   "kissing_closed_eyes",
+	"\U0001f61b":                                                   "stuck_out_tongue",
+	"\U0001f61c":                                                   "stuck_out_tongue_winking_eye",
+	"\U0001f61d":                                                   "stuck_out_tongue_closed_eyes",
+	"\U0001f61e":                                                   "disappointed",
+	"\U0001f61f":                                                   "worried",
+	"\U0001f620":                                                   "angry",
+	"\U0001f621":                                                   "rage",
+	"\U0001f622":                                                   "cry",
+	"\U0001f623":                                                   "persevere",
+	"\U0001f624":                                                   "triumph",
+	"\U0001f625":                                                   "disappointed_relieved",
+	"\U0001f626":                                                   "frowning",
+	"\U0001f627":                                                   "anguished",
+	"\U0001f628":                                                   "fearful",
+	"\U0001f629":                                                   "weary",
+	"\U0001f62a":                                                   "sleepy",
+	"\U0001f62b":                                                   "tired_face",
+	"\U0001f62c":                                                   "grimacing",
+	"\U0001f62d":                                                   "sob",
+	"\U0001f62e":                                                   "open_mouth",
+	"\U0001f62f":                                                   "hushed",
+	"\U0001f630":                                                   "cold_sweat",
+	"\U0001f631":                                                   "scream",
+	"\U0001f632":                                                   "astonished",
+	"\U0001f633":                                                   "flushed",
+	"\U0001f634":                                                   "sleeping",
+	"\U0001f635":                                                   "dizzy_face",
+	"\U0001f636":                                                   "no_mouth",
+	"\U0001f637":                                                   "mask",
+	"\U0001f638":                                                   "smile_cat",
+	"\U0001f639":                                                   "joy_cat",
+	"\U0001f63a":                                                   "smiley_cat",
+	"\U0001f63b":                                                   "heart_eyes_cat",
+	"\U0001f63c":                                                   "smirk_cat",
+	"\U0001f63d":                                                   "kissing_cat",
+	"\U0001f63e":                                                   "pouting_cat",
+	"\U0001f63f":                                                   "crying_cat_face",
+	"\U0001f640":                                                   "scream_cat",
+	"\U0001f645":                                                   "no_good",
+	"\U0001f645\U0001f3fb":                                         "no_good::skin-tone-2",
+	"\U0001f645\U0001f3fc":                                         "no_good::skin-tone-3",
+	"\U0001f645\U0001f3fd":                                         "no_good::skin-tone-4",
+	"\U0001f645\U0001f3fe":                                         "no_good::skin-tone-5",
+	"\U0001f645\U0001f3ff":                                         "no_good::skin-tone-6",
+	"\U0001f646":                                                   "ok_woman",
+	"\U0001f646\U0001f3fb":                                         "ok_woman::skin-tone-2",
+	"\U0001f646\U0001f3fc":                                         "ok_woman::skin-tone-3",
+	"\U0001f646\U0001f3fd":                                         "ok_woman::skin-tone-4",
+	"\U0001f646\U0001f3fe":                                         "ok_woman::skin-tone-5",
+	"\U0001f646\U0001f3ff":                                         "ok_woman::skin-tone-6",
+	"\U0001f647":                                                   "bow",
+	"\U0001f647\U0001f3fb":                                         "bow::skin-tone-2",
+	"\U0001f647\U0001f3fc":                                         "bow::skin-tone-3",
+	"\U0001f647\U0001f3fd":                                         "bow::skin-tone-4",
+	"\U0001f647\U0001f3fe":                                         "bow::skin-tone-5",
+	"\U0001f647\U0001f3ff":                                         "bow::skin-tone-6",
+	"\U0001f648":                                                   "see_no_evil",
+	"\U0001f649":                                                   "hear_no_evil",
+	"\U0001f64a":                                                   "speak_no_evil",
+	"\U0001f64b":                                                   "raising_hand",
+	"\U0001f64b\U0001f3fb":                                         "raising_hand::skin-tone-2",
+	"\U0001f64b\U0001f3fc":                                         "raising_hand::skin-tone-3",
+	"\U0001f64b\U0001f3fd":                                         "raising_hand::skin-tone-4",
+	"\U0001f64b\U0001f3fe":                                         "raising_hand::skin-tone-5",
+	"\U0001f64b\U0001f3ff":                                         "raising_hand::skin-tone-6",
+	"\U0001f64c":                                                   "raised_hands",
+	"\U0001f64c\U0001f3fb":                                         "raised_hands::skin-tone-2",
+	"\U0001f64c\U0001f3fc":                                         "raised_hands::skin-tone-3",
+	"\U0001f64c\U0001f3fd":                                         "raised_hands::skin-tone-4",
+	"\U0001f64c\U0001f3fe":                                         "raised_hands::skin-tone-5",
+	"\U0001f64c\U0001f3ff":                                         "raised_hands::skin-tone-6",
+	"\U0001f64d":                                                   "person_frowning",
+	"\U0001f64d\U0001f3fb":                                         "person_frowning::skin-tone-2",
+	"\U0001f64d\U0001f3fc":                                         "person_frowning::skin-tone-3",
+	"\U0001f64d\U0001f3fd":                                         "person_frowning::skin-tone-4",
+	"\U0001f64d\U0001f3fe":                                         "person_frowning::skin-tone-5",
+	"\U0001f64d\U0001f3ff":                                         "person_frowning::skin-tone-6",
+	"\U0001f64e":                                                   "person_with_pouting_face",
+	"\U0001f64e\U0001f3fb":                                         "person_with_pouting_face::skin-tone-2",
+	"\U0001f64e\U0001f3fc":                                         "person_with_pouting_face::skin-tone-3",
+	"\U0001f64e\U0001f3fd":                                         "person_with_pouting_face::skin-tone-4",
+	"\U0001f64e\U0001f3fe":                                         "person_with_pouting_face::skin-tone-5",
+	"\U0001f64e\U0001f3ff":                                         "person_with_pouting_face::skin-tone-6",
+	"\U0001f64f":                                                   "pray",
+	"\U0001f64f\U0001f3fb":                                         "pray::skin-tone-2",
+	"\U0001f64f\U0001f3fc":                                         "pray::skin-tone-3",
+	"\U0001f64f\U0001f3fd":                                         "pray::skin-tone-4",
+	"\U0001f64f\U0001f3fe":                                         "pray::skin-tone-5",
+	"\U0001f64f\U0001f3ff":                                         "pray::skin-tone-6",
+	"\U0001f680":                                                   "rocket",
+	"\U0001f681":                                                   "helicopter",
+	"\U0001f682":                                                   "steam_locomotive",
+	"\U0001f683":                                                   "railway_car",
+	"\U0001f684":                                                   "bullettrain_side",
+	"\U0001f685":                                                   "bullettrain_front",
+	"\U0001f686":                                                   "train2",
+	"\U0001f687":                                                   "metro",
+	"\U0001f688":                                                   "light_rail",
+	"\U0001f689":                                                   "station",
+	"\U0001f68a":                                                   "tram",
+	"\U0001f68b":                                                   "train",
+	"\U0001f68c":                                                   "bus",
+	"\U0001f68d":                                                   "oncoming_bus",
+	"\U0001f68e":                                                   "trolleybus",
+	"\U0001f68f":                                                   "busstop",
+	"\U0001f690":                                                   "minibus",
+	"\U0001f691":                                                   "ambulance",
+	"\U0001f692":                                                   "fire_engine",
+	"\U0001f693":                                                   "police_car",
+	"\U0001f694":                                                   "oncoming_police_car",
+	"\U0001f695":                                                   "taxi",
+	"\U0001f696":                                                   "oncoming_taxi",
+	"\U0001f697":                                                   "car",
+	"\U0001f698":                                                   "oncoming_automobile",
+	"\U0001f699":                                                   "blue_car",
+	"\U0001f69a":                                                   "truck",
+	"\U0001f69b":                                                   "articulated_lorry",
+	"\U0001f69c":                                                   "tractor",
+	"\U0001f69d":                                                   "monorail",
+	"\U0001f69e":                                                   "mountain_railway",
+	"\U0001f69f":                                                   "suspension_railway",
+	"\U0001f6a0":                                                   "mountain_cableway",
+	"\U0001f6a1":                                                   "aerial_tramway",
+	"\U0001f6a2":                                                   "ship",
+	"\U0001f6a3":                                                   "rowboat",
+	"\U0001f6a3\U0001f3fb":                                         "rowboat::skin-tone-2",
+	"\U0001f6a3\U0001f3fc":                                         "rowboat::skin-tone-3",
+	"\U0001f6a3\U0001f3fd":                                         "rowboat::skin-tone-4",
+	"\U0001f6a3\U0001f3fe":                                         "rowboat::skin-tone-5",
+	"\U0001f6a3\U0001f3ff":                                         "rowboat::skin-tone-6",
+	"\U0001f6a4":                                                   "speedboat",
+	"\U0001f6a5":                                                   "traffic_light",
+	"\U0001f6a6":                                                   "vertical_traffic_light",
+	"\U0001f6a7":                                                   "construction",
+	"\U0001f6a8":                                                   "rotating_light",
+	"\U0001f6a9":                                                   "triangular_flag_on_post",
+	"\U0001f6aa":                                                   "door",
+	"\U0001f6ab":                                                   "no_entry_sign",
+	"\U0001f6ac":                                                   "smoking",
+	"\U0001f6ad":                                                   "no_smoking",
+	"\U0001f6ae":                                                   "put_litter_in_its_place",
+	"\U0001f6af":                                                   "do_not_litter",
+	"\U0001f6b0":                                                   "potable_water",
+	"\U0001f6b1":                                                   "non-potable_water",
+	"\U0001f6b2":                                                   "bike",
+	"\U0001f6b3":                                                   "no_bicycles",
+	"\U0001f6b4":                                                   "bicyclist",
+	"\U0001f6b4\U0001f3fb":                                         "bicyclist::skin-tone-2",
+	"\U0001f6b4\U0001f3fc":                                         "bicyclist::skin-tone-3",
+	"\U0001f6b4\U0001f3fd":                                         "bicyclist::skin-tone-4",
+	"\U0001f6b4\U0001f3fe":                                         "bicyclist::skin-tone-5",
+	"\U0001f6b4\U0001f3ff":                                         "bicyclist::skin-tone-6",
+	"\U0001f6b5":                                                   "mountain_bicyclist",
+	"\U0001f6b5\U0001f3fb":                                         "mountain_bicyclist::skin-tone-2",
+	"\U0001f6b5\U0001f3fc":                                         "mountain_bicyclist::skin-tone-3",
+	"\U0001f6b5\U0001f3fd":                                         "mountain_bicyclist::skin-tone-4",
+	"\U0001f6b5\U0001f3fe":                                         "mountain_bicyclist::skin-tone-5",
+	"\U0001f6b5\U0001f3ff":                                         "mountain_bicyclist::skin-tone-6",
+	"\U0001f6b6":                                                   "walking",
+	"\U0001f6b6\U0001f3fb":                                         "walking::skin-tone-2",
+	"\U0001f6b6\U0001f3fc":                                         "walking::skin-tone-3",
+	"\U0001f6b6\U0001f3fd":                                         "walking::skin-tone-4",
+	"\U0001f6b6\U0001f3fe":                                         "walking::skin-tone-5",
+	"\U0001f6b6\U0001f3ff":                                         "walking::skin-tone-6",
+	"\U0001f6b7":                                                   "no_pedestrians",
+	"\U0001f6b8":                                                   "children_crossing",
+	"\U0001f6b9":                                                   "mens",
+	"\U0001f6ba":                                                   "womens",
+	"\U0001f6bb":                                                   "restroom",
+	"\U0001f6bc":                                                   "baby_symbol",
+	"\U0001f6bd":                                                   "toilet",
+	"\U0001f6be":                                                   "wc",
+	"\U0001f6bf":                                                   "shower",
+	"\U0001f6c0":                                                   "bath",
+	"\U0001f6c0\U0001f3fb":                                         "bath::skin-tone-2",
+	"\U0001f6c0\U0001f3fc":                                         "bath::skin-tone-3",
+	"\U0001f6c0\U0001f3fd":                                         "bath::skin-tone-4",
+	"\U0001f6c0\U0001f3fe":                                         "bath::skin-tone-5",
+	"\U0001f6c0\U0001f3ff":                                         "bath::skin-tone-6",
+	"\U0001f6c1":                                                   "bathtub",
+	"\U0001f6c2":                                                   "passport_control",
+	"\U0001f6c3":                                                   "customs",
+	"\U0001f6c4":                                                   "baggage_claim",
+	"\U0001f6c5":                                                   "left_luggage",
+}
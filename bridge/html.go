@@ -0,0 +1,225 @@
+package bridge
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	htmlWhitespace = regexp.MustCompile(`[ \t\r\n]+`)
+	mrkdwnMarkers  = map[atom.Atom]string{
+		atom.B:      "*",
+		atom.Strong: "*",
+		atom.I:      "_",
+		atom.Em:     "_",
+		atom.S:      "~",
+		atom.Del:    "~",
+		atom.Strike: "~",
+	}
+	htmlBlocks = map[atom.Atom]bool{
+		atom.P: true, atom.Div: true, atom.Pre: true, atom.Blockquote: true,
+		atom.Ul: true, atom.Ol: true, atom.Li: true, atom.Hr: true,
+		atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	}
+)
+
+// htmlToMrkdwn converts the HTML of a Matrix message to slack's mrkdwn, escaping
+// the text for slack. resolve returns the slack reference for the target of a
+// matrix.to link, or "" if there isn't one, in which case the link's text is
+// kept.
+func htmlToMrkdwn(formatted string, resolve func(target string) string) (string, error) {
+	context := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := html.ParseFragment(strings.NewReader(formatted), context)
+	if err != nil {
+		return "", err
+	}
+	for _, n := range nodes {
+		context.AppendChild(n)
+	}
+	c := &mrkdwnConverter{resolve: resolve, markers: make(map[string]bool)}
+	return strings.Trim(c.children(context), " \n"), nil
+}
+
+type mrkdwnConverter struct {
+	resolve func(target string) string
+	// The markers of the formatting which applies at the current node, which
+	// slack can't nest inside themselves.
+	markers map[string]bool
+	pre     bool
+}
+
+func (c *mrkdwnConverter) children(n *html.Node) string {
+	out := ""
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		s := c.node(child)
+		switch {
+		case child.Type == html.ElementNode && htmlBlocks[child.DataAtom]:
+			if out != "" && !strings.HasSuffix(out, "\n") {
+				out += "\n"
+			}
+			if s = strings.Trim(s, " \n"); s != "" {
+				out += s + "\n"
+			}
+			if child.DataAtom == atom.P && child.NextSibling != nil {
+				out += "\n"
+			}
+		case !c.pre && strings.HasSuffix(out, "\n"):
+			out += strings.TrimLeft(s, " ")
+		default:
+			out += s
+		}
+	}
+	return out
+}
+
+func (c *mrkdwnConverter) node(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return c.text(n.Data)
+	case html.ElementNode:
+	default:
+		return ""
+	}
+	if marker, ok := mrkdwnMarkers[n.DataAtom]; ok {
+		return c.format(n, marker)
+	}
+	switch n.DataAtom {
+	case atom.Br:
+		return "\n"
+	case atom.Hr:
+		return "———"
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		return c.format(n, "*")
+	case atom.Code:
+		if c.pre {
+			return c.children(n)
+		}
+		return "`" + c.rawText(n) + "`"
+	case atom.Pre:
+		return "```\n" + strings.Trim(c.rawText(n), "\n") + "\n```"
+	case atom.Blockquote:
+		lines := strings.Split(strings.Trim(c.children(n), "\n"), "\n")
+		return "> " + strings.Join(lines, "\n> ")
+	case atom.Ul, atom.Ol:
+		return c.list(n)
+	case atom.A:
+		return c.link(n)
+	case atom.Img:
+		return c.text(attr(n, "alt"))
+	}
+	if n.Data == "mx-reply" {
+		return ""
+	}
+	return c.children(n)
+}
+
+// format wraps the text of n in marker. Slack only sees markers next to
+// non-space characters, so spaces at either end are moved outside them.
+func (c *mrkdwnConverter) format(n *html.Node, marker string) string {
+	if c.markers[marker] {
+		return c.children(n)
+	}
+	c.markers[marker] = true
+	s := c.children(n)
+	delete(c.markers, marker)
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return s
+	}
+	start := strings.Index(s, trimmed)
+	return s[:start] + marker + trimmed + marker + s[start+len(trimmed):]
+}
+
+func (c *mrkdwnConverter) list(n *html.Node) string {
+	number := 0
+	if n.DataAtom == atom.Ol {
+		number = 1
+		if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+			number = start
+		}
+	}
+	var items []string
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode || child.DataAtom != atom.Li {
+			continue
+		}
+		bullet := "• "
+		if number != 0 {
+			bullet = strconv.Itoa(number) + ". "
+			number++
+		}
+		lines := strings.Split(strings.Trim(c.children(child), "\n"), "\n")
+		items = append(items, bullet+strings.Join(lines, "\n    "))
+	}
+	return strings.Join(items, "\n")
+}
+
+func (c *mrkdwnConverter) link(n *html.Node) string {
+	text := c.children(n)
+	href := attr(n, "href")
+	if match := matrixToURL.FindStringSubmatch(href); match != nil && strings.HasPrefix(href, match[0]) {
+		if target, err := url.PathUnescape(match[1]); err == nil {
+			if reference := c.resolve(target); reference != "" {
+				return reference
+			}
+		}
+		return text
+	}
+	if !mrkdwnSafeURL.MatchString(href) {
+		return text
+	}
+	href = matrixToSlack(strings.Replace(href, "|", "%7C", -1))
+	if text == "" || text == href || "mailto:"+text == href {
+		return "<" + href + ">"
+	}
+	return "<" + href + "|" + text + ">"
+}
+
+// text escapes text for slack, collapsing whitespace as HTML does, and
+// replacing bare matrix.to links with the slack references they resolve to.
+func (c *mrkdwnConverter) text(text string) string {
+	if c.pre {
+		return matrixToSlack(text)
+	}
+	if strings.TrimSpace(text) == "" && strings.Contains(text, "\n") {
+		return ""
+	}
+	text = htmlWhitespace.ReplaceAllString(text, " ")
+	out := ""
+	last := 0
+	for _, match := range matrixToURL.FindAllStringSubmatchIndex(text, -1) {
+		target, err := url.PathUnescape(text[match[2]:match[3]])
+		if err != nil {
+			continue
+		}
+		if reference := c.resolve(target); reference != "" {
+			out += matrixToSlack(text[last:match[0]]) + reference
+			last = match[1]
+		}
+	}
+	return out + matrixToSlack(text[last:])
+}
+
+// rawText returns the escaped text of code, in which slack doesn't format
+// anything.
+func (c *mrkdwnConverter) rawText(n *html.Node) string {
+	pre := c.pre
+	c.pre = true
+	s := c.children(n)
+	c.pre = pre
+	return s
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
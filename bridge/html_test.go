@@ -0,0 +1,38 @@
+package bridge
+
+import "testing"
+
+func TestHTMLToMrkdwn(t *testing.T) {
+	for _, tc := range []struct {
+		formatted string
+		want      string
+	}{
+		{" plain &amp;\n<span>simple</span> &lt;b&gt;", "plain &amp; simple &lt;b&gt;"},
+		{"<strong>bold</strong> <em>italic</em> <del>struck</del> <u>under</u>", "*bold* _italic_ ~struck~ under"},
+		{"<b>bold <i>and italic</i> and <b>still bold</b></b>", "*bold _and italic_ and still bold*"},
+		{"<strong>spaced </strong>out", "*spaced* out"},
+		{"<h1>Heading</h1>\n<p>First</p>\n<p>Second<br>line</p>\n", "*Heading*\nFirst\n\nSecond\nline"},
+		{"run <code>a &lt; b *c*</code>", "run `a &lt; b *c*`"},
+		{"<pre><code class=\"language-go\">if a &lt; b {\n\tc()\n}\n</code></pre>\n<p>after</p>", "```\nif a &lt; b {\n\tc()\n}\n```\nafter"},
+		{"<blockquote>\n<p>quoted<br>lines</p>\n</blockquote>\n<p>reply</p>", "> quoted\n> lines\nreply"},
+		{"<ul>\n<li>one</li>\n<li>two<ul><li>nested</li></ul></li>\n</ul>", "• one\n• two\n    • nested"},
+		{"<ol start=\"3\"><li>three</li><li><strong>four</strong></li></ol>", "3. three\n4. *four*"},
+		{`<a href="https://example.com/?a=1&amp;b=2">the <em>site</em></a> <a href="https://example.com">https://example.com</a> <a href="javascript:alert(1)">x</a>`, "<https://example.com/?a=1&amp;b=2|the _site_> <https://example.com> x"},
+		{`<a href="https://matrix.to/#/@nancy:st.andrews">Nancy</a> and <a href="https://matrix.to/#/@vivian:st.andrews">Vivian</a> in https://matrix.to/#/#cantina:st.andrews`, "<@U34> and Vivian in <#CANTINA>"},
+		{`<mx-reply><blockquote>original</blockquote></mx-reply>reply`, "reply"},
+		{`<img src="mxc://st.andrews/abc" alt=":party:"> time`, ":party: time"},
+	} {
+		got, err := htmlToMrkdwn(tc.formatted, func(target string) string {
+			return map[string]string{
+				"@nancy:st.andrews":   "<@U34>",
+				"#cantina:st.andrews": "<#CANTINA>",
+			}[target]
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("htmlToMrkdwn(%q): want %q got %q", tc.formatted, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package bridge
+
+import (
+	"log"
+	"sync"
+
+	"github.com/matrix-org/slackbridge/store"
+)
+
+func NewMessageMap(s store.Store) *MessageMap {
+	return &MessageMap{store: s}
+}
+
+// MessageMap remembers which Slack messages and Matrix events are the same
+// message, so that changes to one can be bridged to the other.
+type MessageMap struct {
+	store store.Store
+
+	// reactionsMu is held while a reaction is bridged. Every listener in a
+	// channel hears about each slack reaction, including the ones we add from
+	// Matrix, so checking whether it was already bridged must not race with
+	// recording that it has been.
+	reactionsMu sync.Mutex
+}
+
+func (m *MessageMap) Add(slackChannel, slackTS, matrixRoomID, matrixEventID, matrixSender string) {
+	if slackTS == "" || matrixEventID == "" {
+		return
+	}
+	if err := m.store.AddMessage(store.Message{
+		SlackChannelID: slackChannel,
+		SlackTS:        slackTS,
+		MatrixRoomID:   matrixRoomID,
+		MatrixEventID:  matrixEventID,
+		MatrixSender:   matrixSender,
+	}); err != nil {
+		log.Printf("Error saving message mapping: %v", err)
+	}
+}
+
+// MatrixForSlack returns the Matrix events which the slack message was bridged
+// to or from, oldest first.
+func (m *MessageMap) MatrixForSlack(slackChannel, slackTS string) []store.Message {
+	messages, err := m.store.MessagesForSlack(slackChannel, slackTS)
+	if err != nil {
+		log.Printf("Error looking up matrix events for slack message %q: %v", slackTS, err)
+		return nil
+	}
+	return messages
+}
+
+// SlackForMatrix returns the Slack message which the matrix event was bridged
+// to or from, or nil if there is none.
+func (m *MessageMap) SlackForMatrix(matrixRoomID, matrixEventID string) *store.Message {
+	message, err := m.store.MessageForMatrix(matrixRoomID, matrixEventID)
+	if err != nil {
+		log.Printf("Error looking up slack message for matrix event %q: %v", matrixEventID, err)
+		return nil
+	}
+	return message
+}
+
+func (m *MessageMap) AddReaction(reaction store.Reaction) {
+	if reaction.MatrixEventID == "" {
+		return
+	}
+	if err := m.store.AddReaction(reaction); err != nil {
+		log.Printf("Error saving reaction mapping: %v", err)
+	}
+}
+
+// Reaction returns the bridged copy of the reaction name which slackUserID
+// added to a slack message, or nil if there is none.
+func (m *MessageMap) Reaction(slackChannel, slackTS, slackUserID, name string) *store.Reaction {
+	reaction, err := m.store.ReactionForSlack(slackChannel, slackTS, slackUserID, name)
+	if err != nil {
+		log.Printf("Error looking up reaction %q to slack message %q: %v", name, slackTS, err)
+		return nil
+	}
+	return reaction
+}
+
+// ReactionForMatrix returns the slack reaction which the matrix annotation was
+// bridged to or from, or nil if there is none.
+func (m *MessageMap) ReactionForMatrix(matrixRoomID, matrixEventID string) *store.Reaction {
+	reaction, err := m.store.ReactionForMatrix(matrixRoomID, matrixEventID)
+	if err != nil {
+		log.Printf("Error looking up slack reaction for matrix event %q: %v", matrixEventID, err)
+		return nil
+	}
+	return reaction
+}
+
+func (m *MessageMap) RemoveReaction(reaction *store.Reaction) {
+	if err := m.store.RemoveReaction(reaction.MatrixRoomID, reaction.MatrixEventID); err != nil {
+		log.Printf("Error removing reaction mapping: %v", err)
+	}
+}
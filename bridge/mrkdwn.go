@@ -0,0 +1,213 @@
+package bridge
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	mrkdwnCodeBlock = regexp.MustCompile("(?s)```(.+?)```")
+	mrkdwnListItem  = regexp.MustCompile(`^(?:[•◦▪-]|(\d+)\.) `)
+	mrkdwnSafeURL   = regexp.MustCompile(`^(?i:https?|mailto):`)
+	mrkdwnTags      = map[byte]string{'*': "strong", '_': "em", '~': "del"}
+	slackUnescaper  = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&")
+)
+
+// mrkdwnToHTML converts slack's mrkdwn markup to the HTML which Matrix clients
+// understand. All text is escaped, so the only tags in the result are the ones
+// it adds and the ones in the HTML which reference returns for each <...>
+// reference to a user, channel or link.
+func mrkdwnToHTML(slack string, reference func(ref string) string) string {
+	out := ""
+	last := 0
+	for _, match := range mrkdwnCodeBlock.FindAllStringSubmatchIndex(slack, -1) {
+		out += mrkdwnBlocks(strings.TrimSuffix(slack[last:match[0]], "\n"), reference)
+		code := strings.TrimSuffix(strings.TrimPrefix(slack[match[2]:match[3]], "\n"), "\n")
+		out += "<pre><code>" + mrkdwnCode(code, reference) + "</code></pre>"
+		last = match[1]
+		if strings.HasPrefix(slack[last:], "\n") {
+			last++
+		}
+	}
+	return out + mrkdwnBlocks(slack[last:], reference)
+}
+
+// mrkdwnBlocks converts lines of mrkdwn without code blocks, grouping quoted
+// lines into blockquotes and lines which start with bullets or numbers into
+// lists.
+func mrkdwnBlocks(slack string, reference func(ref string) string) string {
+	if slack == "" {
+		return ""
+	}
+	out, kind, start := "", "", ""
+	var group []string
+	flush := func() {
+		switch kind {
+		case "p":
+			out += strings.Join(group, "<br>")
+		case "blockquote":
+			out += "<blockquote>" + strings.Join(group, "<br>") + "</blockquote>"
+		case "ul", "ol":
+			out += "<" + kind + start + "><li>" + strings.Join(group, "</li><li>") + "</li></" + kind + ">"
+		}
+		kind, group = "", nil
+	}
+	add := func(lineKind, line string) {
+		if lineKind != kind {
+			flush()
+			kind = lineKind
+		}
+		group = append(group, mrkdwnInline(line, reference))
+	}
+	lines := strings.Split(slack, "\n")
+	for i, line := range lines {
+		if rest, ok := trimQuote(line, 3); ok {
+			add("blockquote", rest)
+			for _, line := range lines[i+1:] {
+				add("blockquote", line)
+			}
+			break
+		}
+		if rest, ok := trimQuote(line, 1); ok {
+			add("blockquote", rest)
+			continue
+		}
+		match := mrkdwnListItem.FindStringSubmatch(line)
+		switch {
+		case match == nil:
+			add("p", line)
+		case match[1] == "":
+			add("ul", line[len(match[0]):])
+		default:
+			if kind != "ol" {
+				flush()
+				start = ""
+				if match[1] != "1" {
+					start = ` start="` + match[1] + `"`
+				}
+			}
+			add("ol", line[len(match[0]):])
+		}
+	}
+	flush()
+	return out
+}
+
+// trimQuote removes n quote markers, and the space after them, from the start
+// of line. Slack escapes the markers in message text, but not in blocks.
+func trimQuote(line string, n int) (string, bool) {
+	for _, marker := range []string{"&gt;", ">"} {
+		if prefix := strings.Repeat(marker, n); strings.HasPrefix(line, prefix) {
+			return strings.TrimPrefix(line[len(prefix):], " "), true
+		}
+	}
+	return line, false
+}
+
+// mrkdwnInline converts the bold, italic, strikethrough and code spans in a
+// line of mrkdwn. Like slack, it only treats markers as formatting where they
+// can't be part of a word, so snake_case_names stay as they are.
+func mrkdwnInline(slack string, reference func(ref string) string) string {
+	out, text := "", 0
+	for i := 0; i < len(slack); {
+		switch c := slack[i]; {
+		case c == '<':
+			if end := strings.IndexByte(slack[i:], '>'); end != -1 {
+				out += mrkdwnText(slack[text:i]) + reference(slack[i:i+end+1])
+				i += end + 1
+				text = i
+				continue
+			}
+		case c == '`':
+			if end := strings.IndexByte(slack[i+1:], '`'); end > 0 {
+				out += mrkdwnText(slack[text:i]) + "<code>" + mrkdwnCode(slack[i+1:i+1+end], reference) + "</code>"
+				i += end + 2
+				text = i
+				continue
+			}
+		case mrkdwnTags[c] != "" && mrkdwnOpens(slack, i):
+			if end := mrkdwnCloser(slack, i); end != -1 {
+				tag := mrkdwnTags[c]
+				out += mrkdwnText(slack[text:i]) + "<" + tag + ">" + mrkdwnInline(slack[i+1:end], reference) + "</" + tag + ">"
+				i = end + 1
+				text = i
+				continue
+			}
+		}
+		i++
+	}
+	return out + mrkdwnText(slack[text:])
+}
+
+func mrkdwnOpens(slack string, i int) bool {
+	if i+1 >= len(slack) || slack[i+1] == ' ' {
+		return false
+	}
+	r, _ := utf8.DecodeLastRuneInString(slack[:i])
+	return i == 0 || !isWordRune(r)
+}
+
+// mrkdwnCloser returns the index of the marker which closes the one at i, or
+// -1 if there isn't one. References and code can't be split by formatting, so
+// markers inside them are skipped.
+func mrkdwnCloser(slack string, i int) int {
+	for j := i + 1; j < len(slack); j++ {
+		switch c := slack[j]; {
+		case c == '<' || c == '`':
+			closing := byte('>')
+			if c == '`' {
+				closing = '`'
+			}
+			if end := strings.IndexByte(slack[j+1:], closing); end != -1 {
+				j += end + 1
+			}
+		case c == slack[i] && j > i+1 && slack[j-1] != ' ':
+			r, _ := utf8.DecodeRuneInString(slack[j+1:])
+			if j+1 == len(slack) || !isWordRune(r) {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func mrkdwnText(slack string) string {
+	return html.EscapeString(slackToMatrix(slack))
+}
+
+// mrkdwnCode converts the text of a code span or block, in which slack doesn't
+// format anything or replace emoji. References are shown as plain text.
+func mrkdwnCode(slack string, reference func(ref string) string) string {
+	out := ""
+	for {
+		start := strings.IndexByte(slack, '<')
+		if start == -1 {
+			break
+		}
+		end := strings.IndexByte(slack[start:], '>')
+		if end == -1 {
+			break
+		}
+		end += start + 1
+		out += html.EscapeString(slackUnescaper.Replace(slack[:start])) + htmlTag.ReplaceAllString(reference(slack[start:end]), "")
+		slack = slack[end:]
+	}
+	return out + html.EscapeString(slackUnescaper.Replace(slack))
+}
+
+// slackLinkHTML returns the HTML for a slack link like <url|caption>. Other
+// markup is converted to the same text as slackToMatrix would give.
+func slackLinkHTML(ref string) string {
+	inner := ref[1 : len(ref)-1]
+	if i := strings.Index(inner, "|"); i != -1 && mrkdwnSafeURL.MatchString(inner[:i]) {
+		return `<a href="` + html.EscapeString(slackUnescaper.Replace(inner[:i])) + `">` + html.EscapeString(slackToMatrix(inner[i+1:])) + "</a>"
+	}
+	return html.EscapeString(slackToMatrix(ref))
+}
@@ -0,0 +1,50 @@
+package bridge
+
+import "testing"
+
+func TestMrkdwnToHTML(t *testing.T) {
+	for _, tc := range []struct {
+		slack string
+		want  string
+	}{
+		{"plain &amp; simple :wink:", "plain &amp; simple 😉"},
+		{"*bold* _italic_ ~struck~", "<strong>bold</strong> <em>italic</em> <del>struck</del>"},
+		{"*bold _and italic_*", "<strong>bold <em>and italic</em></strong>"},
+		{"snake_case_name and 2*3*4", "snake_case_name and 2*3*4"},
+		{"* not bold *", "* not bold *"},
+		{"run `rm -rf *` :wink:", "run <code>rm -rf *</code> 😉"},
+		{"before\n```\nif a &lt; b {\n\t*x* = 1\n}\n```\nafter", "before<pre><code>if a &lt; b {\n\t*x* = 1\n}</code></pre>after"},
+		{"&gt; quoted\n&gt; *lines*\nreply", "<blockquote>quoted<br><strong>lines</strong></blockquote>reply"},
+		{"intro\n&gt;&gt;&gt; all\nof this", "intro<blockquote>all<br>of this</blockquote>"},
+		{"• one\n• two\n3. three\n4. four", "<ul><li>one</li><li>two</li></ul><ol start=\"3\"><li>three</li><li>four</li></ol>"},
+		{"<https://example.com/?a=1&amp;b=2|*the* site> <javascript:alert(1)|x>", `<a href="https://example.com/?a=1&amp;b=2">*the* site</a> x ( javascript:alert(1) )`},
+		{"&lt;b&gt;not html&lt;/b&gt; \"quoted\"", "&lt;b&gt;not html&lt;/b&gt; &#34;quoted&#34;"},
+		{"*<@U34|nancy_b>*", `<strong><a href="https://matrix.to/#/@nancy:st.andrews">Nancy</a></strong>`},
+		{"`<@U34>`", "<code>Nancy</code>"},
+	} {
+		got := mrkdwnToHTML(tc.slack, func(ref string) string {
+			if ref == "<@U34|nancy_b>" || ref == "<@U34>" {
+				return matrixLinkHTML("@nancy:st.andrews", "Nancy")
+			}
+			return slackLinkHTML(ref)
+		})
+		if got != tc.want {
+			t.Errorf("mrkdwnToHTML(%q): want %q got %q", tc.slack, tc.want, got)
+		}
+	}
+}
+
+func TestSlackToMatrixWithLinks_Formatting(t *testing.T) {
+	noUser := func(string, string) *matrixMention { return nil }
+	noChannel := func(string) string { return "" }
+	body, formatted, _ := slackToMatrixWithLinks("*Take* more chances", noUser, noChannel)
+	if want := "*Take* more chances"; body != want {
+		t.Errorf("body: want %q got %q", want, body)
+	}
+	if want := "<strong>Take</strong> more chances"; formatted != want {
+		t.Errorf("formatted: want %q got %q", want, formatted)
+	}
+	if _, formatted, _ := slackToMatrixWithLinks("Take more chances &amp;\ndance :wink:", noUser, noChannel); formatted != "" {
+		t.Errorf("formatted: want no HTML for plain text, got %q", formatted)
+	}
+}
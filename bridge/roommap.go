@@ -1,51 +1,38 @@
 package bridge
 
 import (
-	"database/sql"
-	"fmt"
 	"log"
 	"strconv"
 	"sync"
 
 	"github.com/matrix-org/slackbridge/matrix"
 	"github.com/matrix-org/slackbridge/slack"
+	"github.com/matrix-org/slackbridge/store"
 )
 
-func NewRoomMap(db *sql.DB) (*RoomMap, error) {
+func NewRoomMap(s store.Store) (*RoomMap, error) {
 	m := &RoomMap{
 		matrixToSlack: make(map[string]string),
 		slackToMatrix: make(map[string]*matrix.Room),
 		rows:          make(map[string]*entry),
+		store:         s,
+	}
 
-		/*
-			CREATE TABLE IF NOT EXISTS rooms(
-			id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
-			slack_channel_id TEXT,
-			matrix_room_id TEXT,
-			last_slack_timestamp TEXT,
-			last_matrix_stream_token TEXT)
-		*/
-		db: db,
+	token, err := s.LastMatrixStreamToken()
+	if err != nil {
+		return nil, err
 	}
+	m.lastMatrixStreamToken = token
 
-	rows, err := db.Query("SELECT id, slack_channel_id, matrix_room_id FROM rooms ORDER BY id ASC")
+	rooms, err := s.Rooms()
 	if err != nil {
 		return nil, err
 	}
-	for rows.Next() {
-		var id int32
-		var slack string
-		var matrixID string
-		if err := rows.Scan(&id, &slack, &matrixID); err != nil {
+	for _, room := range rooms {
+		matrixRoom := matrix.NewRoom(room.MatrixRoomID)
+		if err := m.Link(matrixRoom, room.SlackChannelID); err != nil {
 			return nil, err
 		}
-		matrixRoom := matrix.NewRoom(matrixID)
-		if err := m.Link(matrixRoom, slack); err != nil {
-			return nil, err
-		}
-	}
-	if rows.Err() != nil {
-		return nil, rows.Err()
 	}
 	return m, nil
 }
@@ -68,6 +55,16 @@ func (m *RoomMap) SlackForMatrix(matrix string) string {
 	return m.matrixToSlack[matrix]
 }
 
+func (m *RoomMap) SlackChannels() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	channels := make([]string, 0, len(m.slackToMatrix))
+	for slack := range m.slackToMatrix {
+		channels = append(channels, slack)
+	}
+	return channels
+}
+
 func (m *RoomMap) Link(matrix *matrix.Room, slack string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -80,25 +77,94 @@ func (m *RoomMap) Link(matrix *matrix.Room, slack string) error {
 		}
 		m.rows[matrix.ID] = row
 	}
-	var lastMatrixStreamToken sql.NullString
-	dbRow := m.db.QueryRow(`SELECT id, slack_channel_id, matrix_room_id, last_slack_timestamp, last_matrix_stream_token FROM rooms WHERE slack_channel_id == $1 AND matrix_room_id == $2`, slack, matrix.ID)
-	scanErr := dbRow.Scan(&row.id, &row.SlackChannelID, &row.MatrixRoom.ID, &row.LastSlackTimestampS, &lastMatrixStreamToken)
-	if scanErr == sql.ErrNoRows {
+	row.SlackChannelID = slack
+	stored, err := m.store.Room(slack, matrix.ID)
+	if err != nil {
+		return err
+	}
+	if stored == nil {
 		log.Printf("Writing matrix room %q to table", matrix)
-		if _, err := m.db.Exec(`INSERT INTO rooms (slack_channel_id, matrix_room_id) VALUES ($1, $2)`, slack, matrix.ID); err != nil {
-			return fmt.Errorf("error writing to db: %v", err)
+		if err := m.store.AddRoom(slack, matrix.ID); err != nil {
+			return err
 		}
-	} else if scanErr != nil {
-		return fmt.Errorf("error reading from db: %v", scanErr)
 	} else {
-		if lastMatrixStreamToken.Valid {
-			row.MatrixRoom.LastStreamToken = lastMatrixStreamToken.String
-		}
+		row.LastSlackTimestampS = stored.LastSlackTimestamp
 		log.Printf("Loaded row: %v", row)
 	}
 	return nil
 }
 
+// Unlink removes the link between matrixRoomID and its slack channel, if
+// there is one.
+func (m *RoomMap) Unlink(matrixRoomID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	slack, ok := m.matrixToSlack[matrixRoomID]
+	if !ok {
+		return nil
+	}
+	if err := m.store.RemoveRoom(slack, matrixRoomID); err != nil {
+		return err
+	}
+	delete(m.matrixToSlack, matrixRoomID)
+	if matrix := m.slackToMatrix[slack]; matrix != nil && matrix.ID == matrixRoomID {
+		delete(m.slackToMatrix, slack)
+	}
+	delete(m.rows, matrixRoomID)
+	return nil
+}
+
+// Links returns a map of matrix room ID -> slack channel ID for every linked
+// room.
+func (m *RoomMap) Links() map[string]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	links := make(map[string]string, len(m.matrixToSlack))
+	for matrix, slack := range m.matrixToSlack {
+		links[matrix] = slack
+	}
+	return links
+}
+
+// LastSlackTimestamp returns the timestamp of the last message seen in the
+// slack channel, or "" if none has been seen.
+func (m *RoomMap) LastSlackTimestamp(slack string) string {
+	m.mu.RLock()
+	matrix := m.slackToMatrix[slack]
+	if matrix == nil {
+		m.mu.RUnlock()
+		return ""
+	}
+	row, ok := m.rows[matrix.ID]
+	m.mu.RUnlock()
+	if !ok {
+		return ""
+	}
+	row.mu.RLock()
+	defer row.mu.RUnlock()
+	return row.LastSlackTimestampS
+}
+
+// LastMatrixStreamToken returns the Matrix stream token which events were last
+// handled up to, or "" if none has been saved.
+func (m *RoomMap) LastMatrixStreamToken() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lastMatrixStreamToken
+}
+
+// SaveMatrixStreamToken records that all Matrix events up to token have been
+// handled. There is one event stream for all rooms.
+func (m *RoomMap) SaveMatrixStreamToken(token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.store.SetLastMatrixStreamToken(token); err != nil {
+		return err
+	}
+	m.lastMatrixStreamToken = token
+	return nil
+}
+
 func (m *RoomMap) ShouldNotify(message *slack.Message) bool {
 	log.Printf("Got call to shouldNotify for: %v", message)
 	matrix := m.MatrixForSlack(message.Channel)
@@ -119,10 +185,10 @@ func (m *RoomMap) ShouldNotify(message *slack.Message) bool {
 		return false
 	}
 
-	if _, err := m.db.Exec(`UPDATE rooms SET last_slack_timestamp = $1 WHERE slack_channel_id == $2 AND matrix_room_id == $3`, message.TS, message.Channel, matrix.ID); err != nil {
+	if err := m.store.SetLastSlackTimestamp(message.Channel, matrix.ID, message.TS); err != nil {
 		log.Printf("Error updating DB for new Slack message: %v", err)
 	}
-	row.LastSlackTimestampS = sql.NullString{message.TS, true}
+	row.LastSlackTimestampS = message.TS
 	return true
 }
 
@@ -130,7 +196,9 @@ type RoomMap struct {
 	mu            sync.RWMutex
 	matrixToSlack map[string]string
 	slackToMatrix map[string]*matrix.Room
-	db            *sql.DB
+	store         store.Store
+
+	lastMatrixStreamToken string
 
 	// matrix room ID -> mutex
 	rows map[string]*entry
@@ -138,17 +206,16 @@ type RoomMap struct {
 
 type entry struct {
 	mu                  sync.RWMutex
-	id                  int32
 	SlackChannelID      string
-	LastSlackTimestampS sql.NullString
+	LastSlackTimestampS string
 	MatrixRoom          *matrix.Room
 }
 
 func (e *entry) LastSlackTimestamp() float64 {
-	if !e.LastSlackTimestampS.Valid {
+	if e.LastSlackTimestampS == "" {
 		return 0
 	}
 	// We write this data, so if we have a parse error, the value is nil, so will parse to 0
-	f, _ := strconv.ParseFloat(e.LastSlackTimestampS.String, 64)
+	f, _ := strconv.ParseFloat(e.LastSlackTimestampS, 64)
 	return f
 }
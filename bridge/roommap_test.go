@@ -1,9 +1,9 @@
 package bridge
 
 import (
-	"database/sql"
 	"io/ioutil"
 	"path"
+	"reflect"
 	"testing"
 
 	"github.com/matrix-org/slackbridge/matrix"
@@ -13,7 +13,7 @@ import (
 )
 
 func TestSlackMessageFilter(t *testing.T) {
-	rooms, err := NewRoomMap(makeDB(t))
+	rooms, err := NewRoomMap(makeStore(t))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -52,7 +52,7 @@ func TestRoomMapLoadsConfig(t *testing.T) {
 		t.Fatal(err)
 	}
 	file := path.Join(dir, "sqlite3.db")
-	db := makeDBAt(t, file)
+	db := makeStoreAt(t, file)
 	rooms, err := NewRoomMap(db)
 	if err != nil {
 		t.Fatal(err)
@@ -62,15 +62,76 @@ func TestRoomMapLoadsConfig(t *testing.T) {
 	rooms.Link(matrix.NewRoom(matrixRoomID), slack)
 	db.Close()
 
-	db, err = sql.Open("sqlite3", file)
+	db = makeStoreAt(t, file)
+	rooms, err = NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rooms.SlackForMatrix(matrixRoomID); got != slack {
+		t.Errorf("want %q got %q", slack, got)
+	}
+}
+
+func TestRoomMapSavesMatrixStreamToken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := path.Join(dir, "sqlite3.db")
+	db := makeStoreAt(t, file)
+	rooms, err := NewRoomMap(db)
 	if err != nil {
 		t.Fatal(err)
 	}
+	if got := rooms.LastMatrixStreamToken(); got != "" {
+		t.Errorf("want no token got %q", got)
+	}
+	// No rooms are linked yet, but the token is still saved.
+	if err := rooms.SaveMatrixStreamToken("s72_1"); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	db = makeStoreAt(t, file)
 	rooms, err = NewRoomMap(db)
 	if err != nil {
 		t.Fatal(err)
 	}
-	if got := rooms.SlackForMatrix(matrixRoomID); got != slack {
-		t.Errorf("want %q got %q", slack, got)
+	if got := rooms.LastMatrixStreamToken(); got != "s72_1" {
+		t.Errorf("want %q got %q", "s72_1", got)
+	}
+	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "CANTINA")
+	if err := rooms.SaveMatrixStreamToken("s72_2"); err != nil {
+		t.Fatal(err)
+	}
+	if got := rooms.LastMatrixStreamToken(); got != "s72_2" {
+		t.Errorf("want %q got %q", "s72_2", got)
+	}
+}
+
+func TestRoomMapUnlink(t *testing.T) {
+	db := makeStore(t)
+	rooms, err := NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rooms.Link(matrix.NewRoom("!abc123:matrix.org"), "CANTINA")
+	rooms.Link(matrix.NewRoom("!def456:matrix.org"), "BOWLINGALLEY")
+	if err := rooms.Unlink("!abc123:matrix.org"); err != nil {
+		t.Fatal(err)
+	}
+	if got := rooms.SlackForMatrix("!abc123:matrix.org"); got != "" {
+		t.Errorf("SlackForMatrix: want %q got %q", "", got)
+	}
+	if got := rooms.MatrixForSlack("CANTINA"); got != nil {
+		t.Errorf("MatrixForSlack: want nil got %v", got)
+	}
+
+	rooms, err = NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := map[string]string{"!def456:matrix.org": "BOWLINGALLEY"}, rooms.Links(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Links after reload: want %v got %v", want, got)
 	}
 }
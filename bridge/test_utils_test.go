@@ -2,13 +2,16 @@ package bridge
 
 import (
 	"database/sql"
+	"fmt"
 	"io/ioutil"
 	"net/http"
-	"path"
 	"strings"
 	"testing"
 
 	"github.com/matrix-org/slackbridge/matrix"
+	"github.com/matrix-org/slackbridge/store"
+
+	_ "github.com/mattn/go-sqlite3"
 )
 
 type call struct {
@@ -20,19 +23,51 @@ type MockMatrixClient struct {
 	calls []call
 }
 
-func (m *MockMatrixClient) SendText(roomID, text string) error {
-	m.calls = append(m.calls, call{"SendText", []interface{}{roomID, text}})
-	return nil
+func (m *MockMatrixClient) SendText(roomID, text, formattedBody string) (string, error) {
+	m.calls = append(m.calls, call{"SendText", []interface{}{roomID, text, formattedBody}})
+	return m.eventID(), nil
 }
 
-func (m *MockMatrixClient) SendEmote(roomID, emote string) error {
-	m.calls = append(m.calls, call{"SendEmote", []interface{}{roomID, emote}})
-	return nil
+func (m *MockMatrixClient) SendEmote(roomID, emote, formattedBody string) (string, error) {
+	m.calls = append(m.calls, call{"SendEmote", []interface{}{roomID, emote, formattedBody}})
+	return m.eventID(), nil
 }
 
-func (m *MockMatrixClient) SendImage(roomID, text string, image *matrix.Image) error {
+func (m *MockMatrixClient) SendImage(roomID, text string, image *matrix.Image) (string, error) {
 	m.calls = append(m.calls, call{"SendImage", []interface{}{roomID, text, *image}})
-	return nil
+	return m.eventID(), nil
+}
+
+func (m *MockMatrixClient) SendMessage(roomID string, content *matrix.TextMessageContent) (string, error) {
+	m.calls = append(m.calls, call{"SendMessage", []interface{}{roomID, content}})
+	return m.eventID(), nil
+}
+
+func (m *MockMatrixClient) SendReaction(roomID, eventID, key string) (string, error) {
+	m.calls = append(m.calls, call{"SendReaction", []interface{}{roomID, eventID, key}})
+	return m.eventID(), nil
+}
+
+func (m *MockMatrixClient) Redact(roomID, eventID string) (string, error) {
+	m.calls = append(m.calls, call{"Redact", []interface{}{roomID, eventID}})
+	return m.eventID(), nil
+}
+
+func (m *MockMatrixClient) PowerLevels(roomID string) (*matrix.PowerLevels, error) {
+	return &matrix.PowerLevels{}, nil
+}
+
+func (m *MockMatrixClient) CanonicalAlias(roomID string) (string, error) {
+	return "", nil
+}
+
+func (m *MockMatrixClient) ResolveAlias(alias string) (string, error) {
+	return "", nil
+}
+
+// eventID returns a fake ID for the event sent by the last call.
+func (m *MockMatrixClient) eventID() string {
+	return fmt.Sprintf("$event%d", len(m.calls))
 }
 
 func (m *MockMatrixClient) JoinRoom(roomID string) error {
@@ -54,27 +89,57 @@ func (m *MockMatrixClient) Invite(roomID, userID string) error {
 }
 
 func (m *MockMatrixClient) AccessToken() string {
-	return ""
+	return "matrix_access_token"
 }
 
 func (m *MockMatrixClient) Homeserver() string {
-	return ""
+	return "https://matrix.org"
 }
 
 type MockSlackClient struct {
 	calls []call
 }
 
-func (m *MockSlackClient) SendText(channelID, text string) error {
+func (m *MockSlackClient) SendText(channelID, text string) (string, error) {
 	m.calls = append(m.calls, call{"SendText", []interface{}{channelID, text}})
-	return nil
+	return m.ts(), nil
 }
 
-func (m *MockSlackClient) SendImage(channelID, fallbackText, imageURL string) error {
+func (m *MockSlackClient) SendThreadText(channelID, threadTS, text string) (string, error) {
+	m.calls = append(m.calls, call{"SendThreadText", []interface{}{channelID, threadTS, text}})
+	return m.ts(), nil
+}
+
+func (m *MockSlackClient) SendImage(channelID, fallbackText, imageURL string) (string, error) {
 	m.calls = append(m.calls, call{"SendImage", []interface{}{channelID, fallbackText, imageURL}})
+	return m.ts(), nil
+}
+
+func (m *MockSlackClient) UpdateText(channelID, ts, text string) error {
+	m.calls = append(m.calls, call{"UpdateText", []interface{}{channelID, ts, text}})
+	return nil
+}
+
+func (m *MockSlackClient) Delete(channelID, ts string) error {
+	m.calls = append(m.calls, call{"Delete", []interface{}{channelID, ts}})
 	return nil
 }
 
+func (m *MockSlackClient) AddReaction(channelID, ts, name string) error {
+	m.calls = append(m.calls, call{"AddReaction", []interface{}{channelID, ts, name}})
+	return nil
+}
+
+func (m *MockSlackClient) RemoveReaction(channelID, ts, name string) error {
+	m.calls = append(m.calls, call{"RemoveReaction", []interface{}{channelID, ts, name}})
+	return nil
+}
+
+// ts returns a fake timestamp for the message sent by the last call.
+func (m *MockSlackClient) ts() string {
+	return fmt.Sprintf("%d.000", len(m.calls))
+}
+
 func (m *MockSlackClient) AccessToken() string {
 	return "slack_access_token"
 }
@@ -94,37 +159,17 @@ func (r *spyRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 	}, nil
 }
 
-func makeDB(t *testing.T) *sql.DB {
-	dir, err := ioutil.TempDir("", "testdb")
-	if err != nil {
-		t.Fatal(err)
-	}
-	return makeDBAt(t, path.Join(dir, "sqlite3.db"))
+func makeStore(t *testing.T) store.Store {
+	return store.NewMemory()
 }
 
-func makeDBAt(t *testing.T, path string) *sql.DB {
+func makeStoreAt(t *testing.T, path string) store.Store {
 	db, err := sql.Open("sqlite3", path)
 	if err != nil {
 		t.Fatalf("Could not open database: %v", err)
 	}
-	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS rooms(
-id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
-slack_channel_id TEXT,
-matrix_room_id TEXT,
-last_slack_timestamp TEXT,
-last_matrix_stream_token TEXT
-)`); err != nil {
-		t.Fatal(err)
-	}
-	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS users(
-id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
-slack_user_id TEXT,
-slack_access_token TEXT,
-matrix_user_id TEXT,
-matrix_access_token TEXT,
-matrix_homeserver TEXT
-)`); err != nil {
+	if err := store.Migrate(db, "sqlite3"); err != nil {
 		t.Fatal(err)
 	}
-	return db
+	return store.NewSQL(db)
 }
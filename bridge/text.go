@@ -1,8 +1,12 @@
 package bridge
 
 import (
+	"html"
+	"net/url"
 	"regexp"
 	"strings"
+
+	"github.com/matrix-org/slackbridge/matrix"
 )
 
 func matrixToSlack(matrix string) string {
@@ -29,10 +33,99 @@ func slackToMatrix(slack string) string {
 	return s
 }
 
-func replaceLinksAndSuch(slack string) string {
-	// TODO: <@USER>
-	// TODO: <#CHANNEL>
+var slackReference = regexp.MustCompile(`<(?:([@#])([A-Z0-9]+)|!(here|channel|everyone))(?:\|([^>]*))?>`)
+
+type matrixMention struct {
+	UserID      string
+	DisplayName string
+}
+
+// slackToMatrixWithLinks converts slack text like slackToMatrix, replacing
+// mentions of the users which resolveUser finds with their display names,
+// references to channels with their #names, and pings of the whole channel with
+// @room. If the text has any formatting, or any users or channels are bridged,
+// it also returns the text as HTML with pills and links to the Matrix rooms
+// which resolveChannel finds. mentions is nil if nobody is mentioned.
+func slackToMatrixWithLinks(slack string, resolveUser func(slackUserID, label string) *matrixMention, resolveChannel func(slackChannelID string) string) (body, formatted string, mentions *matrix.Mentions) {
+	var userIDs []string
+	room := false
+	references := make(map[string]string)
+	last := 0
+	for _, match := range slackReference.FindAllStringSubmatchIndex(slack, -1) {
+		body += slackToMatrix(slack[last:match[0]])
+		last = match[1]
+		reference := slack[match[0]:match[1]]
+
+		if match[6] != -1 {
+			body += "@room"
+			references[reference] = "@room"
+			room = true
+			continue
+		}
+		kind, id, label := slack[match[2]:match[3]], slack[match[4]:match[5]], ""
+		if match[8] != -1 {
+			label = slack[match[8]:match[9]]
+		}
+		name := label
+		if name == "" {
+			name = id
+		}
+		if kind == "#" {
+			text := "#" + name
+			body += text
+			if roomLink := resolveChannel(id); roomLink != "" {
+				references[reference] = matrixLinkHTML(roomLink, text)
+			} else {
+				references[reference] = html.EscapeString(text)
+			}
+			continue
+		}
+		mention := resolveUser(id, label)
+		if mention == nil {
+			body += "@" + name
+			references[reference] = html.EscapeString("@" + name)
+			continue
+		}
+		body += mention.DisplayName
+		references[reference] = matrixLinkHTML(mention.UserID, mention.DisplayName)
+		if !contains(userIDs, mention.UserID) {
+			userIDs = append(userIDs, mention.UserID)
+		}
+	}
+	body += slackToMatrix(slack[last:])
+	formatted = mrkdwnToHTML(slack, func(ref string) string {
+		if h, ok := references[ref]; ok {
+			return h
+		}
+		return slackLinkHTML(ref)
+	})
+	if formatted == textToHTML(body) {
+		formatted = ""
+	}
+	if len(userIDs) > 0 || room {
+		mentions = &matrix.Mentions{UserIDs: userIDs, Room: room}
+	}
+	return body, formatted, mentions
+}
+
+func matrixLinkHTML(id, text string) string {
+	return `<a href="` + html.EscapeString(matrixToLink(id)) + `">` + html.EscapeString(text) + "</a>"
+}
+
+func textToHTML(text string) string {
+	return strings.Replace(html.EscapeString(text), "\n", "<br>", -1)
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
 
+func replaceLinksAndSuch(slack string) string {
 	find := regexp.MustCompile("<[^>\x00]*>")
 	for {
 		match := find.FindString(slack)
@@ -73,3 +166,144 @@ func replaceEmoji(s string) string {
 	}
 	return s
 }
+
+// Slack names skin tone variants of an emoji like "+1::skin-tone-2".
+var skinTones = map[string]string{
+	"skin-tone-2": "\U0001F3FB",
+	"skin-tone-3": "\U0001F3FC",
+	"skin-tone-4": "\U0001F3FD",
+	"skin-tone-5": "\U0001F3FE",
+	"skin-tone-6": "\U0001F3FF",
+}
+
+// slackReactionToMatrix returns the key of the Matrix annotation for a Slack
+// reaction name. Custom emoji have no Unicode equivalent, so they are sent as
+// their :name: text.
+func slackReactionToMatrix(name string) string {
+	base, tone := name, ""
+	if i := strings.Index(name, "::"); i != -1 {
+		base, tone = name[:i], name[i+2:]
+	}
+	emojum, ok := emoji[":"+base+":"]
+	if !ok {
+		return ":" + name + ":"
+	}
+	return emojum + skinTones[tone]
+}
+
+// matrixReactionToSlack returns the Slack name of the emoji in the key of a
+// Matrix annotation, or false if Slack has no name for it. Keys like :name:
+// are assumed to be custom emoji which slackReactionToMatrix couldn't convert.
+func matrixReactionToSlack(key string) (string, bool) {
+	if name, ok := emojiShortcodes[key]; ok {
+		return name, true
+	}
+	if name, ok := emojiShortcodes[strings.Replace(key, "\uFE0F", "", -1)]; ok {
+		return name, true
+	}
+	if matched, _ := regexp.MatchString(`^:[^:\s]+:$`, key); matched {
+		return key[1 : len(key)-1], true
+	}
+	return "", false
+}
+
+var (
+	matrixReplyQuote = regexp.MustCompile(`(?s)<mx-reply>.*</mx-reply>`)
+	matrixToAnchor   = regexp.MustCompile(`(?s)<a href="https://matrix\.to/#/([^"/?]+)[^"]*">(.*?)</a>`)
+	matrixToURL      = regexp.MustCompile(`https://matrix\.to/#/([!#@%][^\s/?"<>]+)`)
+	htmlTag          = regexp.MustCompile(`<[^>]*>`)
+)
+
+// matrixLink is a link to a Matrix user, room or alias in a message.
+type matrixLink struct {
+	Target string
+	// What stands for the link in the message's plain body.
+	Text string
+}
+
+// matrixLinks returns the matrix.to links in a message: those in formatted,
+// like pills, and bare ones in body.
+func matrixLinks(body, formatted string) []matrixLink {
+	var links []matrixLink
+	formatted = matrixReplyQuote.ReplaceAllString(formatted, "")
+	for _, match := range matrixToAnchor.FindAllStringSubmatch(formatted, -1) {
+		if target, err := url.PathUnescape(match[1]); err == nil {
+			links = append(links, matrixLink{target, html.UnescapeString(htmlTag.ReplaceAllString(match[2], ""))})
+		}
+	}
+	for _, match := range matrixToURL.FindAllStringSubmatch(body, -1) {
+		if target, err := url.PathUnescape(match[1]); err == nil {
+			links = append(links, matrixLink{target, match[0]})
+		}
+	}
+	return links
+}
+
+// replaceMatrixLinks replaces the text of each of links in s, which is already
+// escaped for slack, with the slack reference which resolve finds for its
+// target, if any. It falls back to replacing the target itself.
+func replaceMatrixLinks(s string, links []matrixLink, resolve func(target string) string) string {
+	for _, link := range links {
+		reference := resolve(link.Target)
+		if reference == "" {
+			continue
+		}
+		for _, text := range []string{link.Text, link.Target} {
+			if text = matrixToSlack(text); text != "" && strings.Contains(s, text) {
+				s = strings.Replace(s, text, reference, 1)
+				break
+			}
+		}
+	}
+	return s
+}
+
+// splitReplyFallback separates the quote of the original event, which Matrix
+// clients put at the start of a reply's body, from the reply itself. A reply
+// which starts with a quote of its own has no fallback unless formattedBody
+// has one too, or the quote starts with the original's sender.
+func splitReplyFallback(body, formattedBody string) (quote []string, reply string) {
+	lines := strings.Split(body, "\n")
+	if !strings.Contains(formattedBody, "<mx-reply>") && !replyFallbackStart.MatchString(lines[0]) {
+		return nil, body
+	}
+	i := 0
+	for ; i < len(lines) && strings.HasPrefix(lines[i], ">"); i++ {
+		quote = append(quote, strings.TrimPrefix(lines[i][1:], " "))
+	}
+	if i == 0 {
+		return nil, body
+	}
+	if i < len(lines) && lines[i] == "" {
+		i++
+	}
+	return quote, strings.Join(lines[i:], "\n")
+}
+
+var (
+	replyFallbackStart  = regexp.MustCompile(`^> (\* )?<@[^>]+>`)
+	replyFallbackSender = regexp.MustCompile(`^(\* )?<([^>]+)> ?`)
+)
+
+// slackQuote renders the quote from a Matrix reply fallback as a Slack quote
+// whose first line links to the original event.
+func slackQuote(permalink string, quote []string) string {
+	label, first := "In reply to", ""
+	if len(quote) > 0 {
+		first, quote = quote[0], quote[1:]
+		if match := replyFallbackSender.FindStringSubmatch(first); match != nil {
+			label = match[2]
+			first = match[1] + first[len(match[0]):]
+		}
+	}
+	s := strings.TrimRight("> <"+permalink+"|"+matrixToSlack(label)+"> "+matrixToSlack(first), " ") + "\n"
+	for _, line := range quote {
+		s += "> " + matrixToSlack(line) + "\n"
+	}
+	return s
+}
+
+// matrixToLink returns a matrix.to link to a room, or to an event in it.
+func matrixToLink(ids ...string) string {
+	return "https://matrix.to/#/" + strings.Join(ids, "/")
+}
@@ -1,6 +1,11 @@
 package bridge
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+
+	"github.com/matrix-org/slackbridge/matrix"
+)
 
 func TestMatrixToSlack_EscapesSpecialCharacters(t *testing.T) {
 	matrix := "<special & characters>"
@@ -59,3 +64,113 @@ func testSlackToMatrix(t *testing.T, slack, matrix string) {
 		t.Errorf("slackToMatrix(%s): want %q got %q", slack, matrix, got)
 	}
 }
+
+func TestSlackReactionToMatrix(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		want string
+	}{
+		{"wink", "😉"},
+		{"+1::skin-tone-2", "👍🏻"},
+		{"+1::skin-tone-6", "👍🏿"},
+		{"godzillavodka", ":godzillavodka:"},
+	} {
+		if got := slackReactionToMatrix(tc.name); got != tc.want {
+			t.Errorf("slackReactionToMatrix(%q): want %q got %q", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestMatrixReactionToSlack(t *testing.T) {
+	for _, tc := range []struct {
+		key    string
+		want   string
+		wantOK bool
+	}{
+		{"😉", "wink", true},
+		{"👍🏻", "+1::skin-tone-2", true},
+		{"\u2764\ufe0f", "heart", true},
+		{":godzillavodka:", "godzillavodka", true},
+		{"lol", "", false},
+	} {
+		if got, ok := matrixReactionToSlack(tc.key); got != tc.want || ok != tc.wantOK {
+			t.Errorf("matrixReactionToSlack(%q): want %q, %v got %q, %v", tc.key, tc.want, tc.wantOK, got, ok)
+		}
+	}
+}
+
+func TestSplitReplyFallback(t *testing.T) {
+	quote, reply := splitReplyFallback("> <@nancy:st.andrews> Take more chances\n> Dance more dances\n\nOK", "")
+	if want := []string{"<@nancy:st.andrews> Take more chances", "Dance more dances"}; !reflect.DeepEqual(quote, want) {
+		t.Errorf("quote: want %q got %q", want, quote)
+	}
+	if reply != "OK" {
+		t.Errorf("reply: want %q got %q", "OK", reply)
+	}
+	quote, reply = splitReplyFallback("> Take more chances\n\nOK", "<mx-reply><blockquote>Take more chances</blockquote></mx-reply>OK")
+	if want := []string{"Take more chances"}; !reflect.DeepEqual(quote, want) || reply != "OK" {
+		t.Errorf("formatted fallback: want %q, %q got %q, %q", want, "OK", quote, reply)
+	}
+	for _, body := range []string{"No quote", "> Take more chances\n\nOK"} {
+		if quote, reply := splitReplyFallback(body, ""); quote != nil || reply != body {
+			t.Errorf("want nil, %q got %q, %q", body, quote, reply)
+		}
+	}
+}
+
+func TestSlackQuote(t *testing.T) {
+	got := slackQuote("https://matrix.to/#/!abc123:matrix.org/$original", []string{"* <@nancy:st.andrews> takes <more> chances", "and dances"})
+	want := "> <https://matrix.to/#/!abc123:matrix.org/$original|@nancy:st.andrews> * takes &lt;more&gt; chances\n> and dances\n"
+	if got != want {
+		t.Errorf("want %q got %q", want, got)
+	}
+	if got, want := slackQuote("https://matrix.to/#/!abc123:matrix.org/$original", nil), "> <https://matrix.to/#/!abc123:matrix.org/$original|In reply to>\n"; got != want {
+		t.Errorf("want %q got %q", want, got)
+	}
+}
+
+func TestMatrixToSlackWithLinks(t *testing.T) {
+	body := "Nancy & co & Vivian & @sean:st.andrews in https://matrix.to/#/%23cantina:st.andrews"
+	formatted := `<mx-reply><a href="https://matrix.to/#/@sean:st.andrews">Sean</a></mx-reply>` +
+		`<a href="https://matrix.to/#/%40nancy:st.andrews">Nancy &amp; co</a> &amp; <a href="https://matrix.to/#/@vivian:st.andrews">Vivian</a>`
+	links := matrixLinks(body, formatted)
+	want := []matrixLink{
+		{"@nancy:st.andrews", "Nancy & co"},
+		{"@vivian:st.andrews", "Vivian"},
+		{"#cantina:st.andrews", "https://matrix.to/#/%23cantina:st.andrews"},
+	}
+	if !reflect.DeepEqual(links, want) {
+		t.Fatalf("matrixLinks: want %v got %v", want, links)
+	}
+	links = append(links, matrixLink{Target: "@sean:st.andrews"})
+	got := replaceMatrixLinks(matrixToSlack(body), links, func(target string) string {
+		return map[string]string{
+			"@nancy:st.andrews":   "<@U34>",
+			"@sean:st.andrews":    "<@U35>",
+			"#cantina:st.andrews": "<#CANTINA>",
+		}[target]
+	})
+	if want := "<@U34> &amp; Vivian &amp; <@U35> in <#CANTINA>"; got != want {
+		t.Errorf("replaceMatrixLinks: want %q got %q", want, got)
+	}
+}
+
+func TestSlackToMatrixWithLinks(t *testing.T) {
+	body, formatted, mentions := slackToMatrixWithLinks("<!here|@here> <@U34> in <#CANTINA|cantina> & <#BOWLINGALLEY|bowling>", func(slackUserID, label string) *matrixMention {
+		return nil
+	}, func(slackChannelID string) string {
+		if slackChannelID == "CANTINA" {
+			return "#cantina:st.andrews"
+		}
+		return ""
+	})
+	if want := "@room @U34 in #cantina & #bowling"; body != want {
+		t.Errorf("body: want %q got %q", want, body)
+	}
+	if want := `@room @U34 in <a href="https://matrix.to/#/#cantina:st.andrews">#cantina</a> &amp; #bowling`; formatted != want {
+		t.Errorf("formatted: want %q got %q", want, formatted)
+	}
+	if want := (&matrix.Mentions{Room: true}); !reflect.DeepEqual(mentions, want) {
+		t.Errorf("mentions: want %v got %v", want, mentions)
+	}
+}
@@ -1,8 +1,6 @@
 package bridge
 
 import (
-	"database/sql"
-	"fmt"
 	"log"
 	"net/http"
 	"sync"
@@ -10,52 +8,46 @@ import (
 	"github.com/matrix-org/slackbridge/common"
 	"github.com/matrix-org/slackbridge/matrix"
 	"github.com/matrix-org/slackbridge/slack"
+	"github.com/matrix-org/slackbridge/store"
 )
 
-func NewUserMap(db *sql.DB, httpClient http.Client, rooms *RoomMap, matrixEchoSuppresser *common.EchoSuppresser) (*UserMap, error) {
+func NewUserMap(s store.Store, httpClient http.Client, rooms *RoomMap, matrixEchoSuppresser *common.EchoSuppresser) (*UserMap, error) {
 	m := &UserMap{
 		matrixToSlack:        make(map[string]*slack.User),
 		slackToMatrix:        make(map[string]*matrix.User),
-		db:                   db,
+		ghostToSlack:         make(map[string]string),
+		store:                s,
 		matrixEchoSuppresser: matrixEchoSuppresser,
 	}
 
-	rows, err := db.Query("SELECT id, slack_user_id, slack_access_token, matrix_user_id, matrix_access_token, matrix_homeserver FROM users ORDER BY id ASC")
+	users, err := s.Users()
 	if err != nil {
 		return nil, err
 	}
-	for rows.Next() {
-		var id int32
-		var slackID, matrixID string
-		var slackToken, matrixToken, matrixHomeserver sql.NullString
-		if err := rows.Scan(&id, &slackID, &slackToken, &matrixID, &matrixToken, &matrixHomeserver); err != nil {
-			return nil, err
-		}
-		if !matrixToken.Valid {
+	for _, user := range users {
+		matrixID, slackID := user.MatrixUserID, user.SlackUserID
+		if user.MatrixAccessToken == "" {
 			log.Printf("Skipping user matrix:%q = slack:%q because no matrix token", matrixID, slackID)
 			continue
 		}
-		if !matrixHomeserver.Valid {
+		if user.MatrixHomeserver == "" {
 			log.Printf("Skipping user matrix:%q = slack:%q because no matrix homeserver", matrixID, slackID)
 			continue
 		}
-		if !slackToken.Valid {
+		if user.SlackAccessToken == "" {
 			log.Printf("Skipping user matrix:%q = slack:%q because no slack token", matrixID, slackID)
 			continue
 		}
-		matrixClient := matrix.NewClient(matrixToken.String, httpClient, matrixHomeserver.String, m.matrixEchoSuppresser)
+		matrixClient := matrix.NewClient(user.MatrixAccessToken, httpClient, user.MatrixHomeserver, m.matrixEchoSuppresser)
 		matrixUser := matrix.NewUser(matrixID, matrixClient)
 
-		slackClient := slack.NewClient(slackToken.String, httpClient, rooms.ShouldNotify)
+		slackClient := slack.NewClient(user.SlackAccessToken, httpClient, rooms.ShouldNotify)
 		slackUser := &slack.User{slackID, slackClient}
 
 		if err := m.Link(matrixUser, slackUser); err != nil {
 			return nil, err
 		}
 	}
-	if rows.Err() != nil {
-		return nil, rows.Err()
-	}
 	return m, nil
 }
 
@@ -71,33 +63,92 @@ func (u *UserMap) SlackForMatrix(matrixUser string) *slack.User {
 	return u.matrixToSlack[matrixUser]
 }
 
+func (u *UserMap) SlackUsers() []*slack.User {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	users := make([]*slack.User, 0, len(u.matrixToSlack))
+	for _, user := range u.matrixToSlack {
+		users = append(users, user)
+	}
+	return users
+}
+
 func (u *UserMap) Link(m *matrix.User, s *slack.User) error {
 	u.mu.Lock()
 	defer u.mu.Unlock()
 	u.matrixToSlack[m.UserID] = s
 	u.slackToMatrix[s.UserID] = m
 
-	var count int32
-	dbRow := u.db.QueryRow(`SELECT COUNT(id) FROM users WHERE slack_user_id == $1 AND matrix_user_id == $2`, s.UserID, m.UserID)
-	scanErr := dbRow.Scan(&count)
-	if scanErr == sql.ErrNoRows {
-		log.Printf("Count query returned 0 rows - don't know what to do so skipping")
-		return fmt.Errorf("count returned 0 rows")
-	} else if scanErr != nil {
-		return fmt.Errorf("error reading from db: %v", scanErr)
+	exists, err := u.store.HasUser(s.UserID, m.UserID)
+	if err != nil {
+		return err
 	}
-	if count == 0 {
-		if _, err := u.db.Exec(`INSERT INTO users (slack_user_id, slack_access_token, matrix_user_id, matrix_access_token, matrix_homeserver) VALUES ($1, $2, $3, $4, $5)`, s.UserID, s.Client.AccessToken(), m.UserID, m.Client.AccessToken(), m.Client.Homeserver()); err != nil {
-			return fmt.Errorf("error writing to db: %v", err)
+	if !exists {
+		if err := u.store.AddUser(store.User{
+			SlackUserID:       s.UserID,
+			SlackAccessToken:  s.Client.AccessToken(),
+			MatrixUserID:      m.UserID,
+			MatrixAccessToken: m.Client.AccessToken(),
+			MatrixHomeserver:  m.Client.Homeserver(),
+		}); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+// Unlink removes the link between matrixUserID and its slack user, if there
+// is one, returning the slack user which was unlinked.
+func (u *UserMap) Unlink(matrixUserID string) (*slack.User, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	s, ok := u.matrixToSlack[matrixUserID]
+	if !ok {
+		return nil, nil
+	}
+	if err := u.store.RemoveUser(s.UserID, matrixUserID); err != nil {
+		return nil, err
+	}
+	delete(u.matrixToSlack, matrixUserID)
+	if m := u.slackToMatrix[s.UserID]; m != nil && m.UserID == matrixUserID {
+		delete(u.slackToMatrix, s.UserID)
+	}
+	return s, nil
+}
+
+// Links returns a map of matrix user ID -> slack user ID for every linked
+// user.
+func (u *UserMap) Links() map[string]string {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	links := make(map[string]string, len(u.matrixToSlack))
+	for matrixID, s := range u.matrixToSlack {
+		links[matrixID] = s.UserID
+	}
+	return links
+}
+
+// AddGhost records that ghostID is the Matrix user which the bridge controls
+// on behalf of the unlinked slack user slackUserID.
+func (u *UserMap) AddGhost(ghostID, slackUserID string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.ghostToSlack[ghostID] = slackUserID
+}
+
+// SlackForGhost returns the ID of the slack user which ghostID speaks for, or
+// "" if we don't know of it.
+func (u *UserMap) SlackForGhost(ghostID string) string {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.ghostToSlack[ghostID]
+}
+
 type UserMap struct {
 	mu                   sync.RWMutex
 	matrixToSlack        map[string]*slack.User
 	slackToMatrix        map[string]*matrix.User
+	ghostToSlack         map[string]string
 	matrixEchoSuppresser *common.EchoSuppresser
-	db                   *sql.DB
+	store                store.Store
 }
@@ -1,7 +1,6 @@
 package bridge
 
 import (
-	"database/sql"
 	"io/ioutil"
 	"net/http"
 	"path"
@@ -19,7 +18,7 @@ func TestUserMapLoadsConfig(t *testing.T) {
 		t.Fatal(err)
 	}
 	file := path.Join(dir, "sqlite3.db")
-	db := makeDBAt(t, file)
+	db := makeStoreAt(t, file)
 	rooms, err := NewRoomMap(db)
 	if err != nil {
 		t.Fatal(err)
@@ -34,10 +33,7 @@ func TestUserMapLoadsConfig(t *testing.T) {
 	users.Link(matrixUser, &slack.User{slackID, &MockSlackClient{}})
 	db.Close()
 
-	db, err = sql.Open("sqlite3", file)
-	if err != nil {
-		t.Fatal(err)
-	}
+	db = makeStoreAt(t, file)
 	rooms, err = NewRoomMap(db)
 	if err != nil {
 		t.Fatal(err)
@@ -54,3 +50,34 @@ func TestUserMapLoadsConfig(t *testing.T) {
 		t.Errorf("want %q got %q", slackID, got.UserID)
 	}
 }
+
+func TestUserMapUnlink(t *testing.T) {
+	db := makeStore(t)
+	rooms, err := NewRoomMap(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users, err := NewUserMap(db, http.Client{}, rooms, common.NewEchoSuppresser())
+	if err != nil {
+		t.Fatal(err)
+	}
+	slackUser := &slack.User{"U34", &MockSlackClient{}}
+	users.Link(matrix.NewUser("@nancy:st.andrews", &MockMatrixClient{}), slackUser)
+
+	got, err := users.Unlink("@nancy:st.andrews")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != slackUser {
+		t.Errorf("Unlink: want %v got %v", slackUser, got)
+	}
+	if got := users.MatrixForSlack("U34"); got != nil {
+		t.Errorf("MatrixForSlack: want nil got %v", got)
+	}
+	if has, err := db.HasUser("U34", "@nancy:st.andrews"); err != nil || has {
+		t.Errorf("HasUser: want false, nil got %v, %v", has, err)
+	}
+	if got, err := users.Unlink("@nancy:st.andrews"); err != nil || got != nil {
+		t.Errorf("Unlink again: want nil, nil got %v, %v", got, err)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/matrix-org/slackbridge/bridge"
+
+	"gopkg.in/yaml.v2"
+)
+
+const defaultSlackCatchUpLimit = 100
+
+type config struct {
+	HomeserverURL  string `yaml:"homeserver_url"`
+	HomeserverName string `yaml:"homeserver_name"`
+	ASToken        string `yaml:"as_token"`
+	HSToken        string `yaml:"hs_token"`
+	UserPrefix     string `yaml:"user_prefix"`
+	DatabaseDriver string `yaml:"database_driver"`
+	DatabasePath   string `yaml:"database_path"`
+	ListenAddress  string `yaml:"listen_address"`
+	AdminToken     string `yaml:"admin_token"`
+
+	SlackAppToken      string `yaml:"slack_app_token"`
+	SlackSigningSecret string `yaml:"slack_signing_secret"`
+	SlackBotToken      string `yaml:"slack_bot_token"`
+	// Unset means defaultSlackCatchUpLimit, as 0 means no limit.
+	SlackCatchUpLimit *int `yaml:"slack_catch_up_limit"`
+}
+
+func loadConfig(path string) (*config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %v", err)
+	}
+	var c config
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("error parsing config file: %v", err)
+	}
+	if c.HomeserverURL == "" {
+		return nil, fmt.Errorf("missing homeserver_url")
+	}
+	if c.HomeserverName == "" {
+		return nil, fmt.Errorf("missing homeserver_name")
+	}
+	if c.ASToken == "" {
+		return nil, fmt.Errorf("missing as_token")
+	}
+	if c.UserPrefix == "" {
+		return nil, fmt.Errorf("missing user_prefix")
+	}
+	if c.HSToken != "" && c.ListenAddress == "" {
+		return nil, fmt.Errorf("hs_token requires listen_address")
+	}
+	if c.AdminToken != "" && c.ListenAddress == "" {
+		return nil, fmt.Errorf("admin_token requires listen_address")
+	}
+	if c.SlackAppToken != "" && c.SlackBotToken == "" {
+		return nil, fmt.Errorf("slack_app_token requires slack_bot_token")
+	}
+	if c.SlackSigningSecret != "" && (c.SlackBotToken == "" || c.ListenAddress == "") {
+		return nil, fmt.Errorf("slack_signing_secret requires slack_bot_token and listen_address")
+	}
+	if c.DatabasePath == "" {
+		return nil, fmt.Errorf("missing database_path")
+	}
+	switch c.DatabaseDriver {
+	case "":
+		c.DatabaseDriver = "sqlite3"
+	case "sqlite3", "postgres":
+	default:
+		return nil, fmt.Errorf("unknown database_driver %q", c.DatabaseDriver)
+	}
+	if c.SlackCatchUpLimit == nil {
+		limit := defaultSlackCatchUpLimit
+		c.SlackCatchUpLimit = &limit
+	} else if *c.SlackCatchUpLimit < 0 {
+		return nil, fmt.Errorf("negative slack_catch_up_limit %d", *c.SlackCatchUpLimit)
+	}
+	return &c, nil
+}
+
+func (c *config) bridgeConfig() bridge.Config {
+	return bridge.Config{
+		MatrixASAccessToken: c.ASToken,
+		UserPrefix:          c.UserPrefix,
+		HomeserverBaseURL:   c.HomeserverURL,
+		HomeserverName:      c.HomeserverName,
+		SlackCatchUpLimit:   *c.SlackCatchUpLimit,
+	}
+}
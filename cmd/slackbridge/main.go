@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/matrix-org/slackbridge/bridge"
+	"github.com/matrix-org/slackbridge/common"
+	"github.com/matrix-org/slackbridge/matrix"
+	"github.com/matrix-org/slackbridge/slack"
+	"github.com/matrix-org/slackbridge/store"
+
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var configPath = flag.String("config", "config.yaml", "Path to the bridge config file")
+
+type slackListener interface {
+	Listen(cancel chan struct{}) error
+	OnMessage(h func(slack.Message))
+	OnReaction(h func(slack.Reaction))
+	OnConnectionState(h func(slack.ConnectionState))
+}
+
+func openStore(driver, path string) (store.Store, error) {
+	db, err := sql.Open(driver, path)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Migrate(db, driver); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store.NewSQL(db), nil
+}
+
+func main() {
+	flag.Parse()
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Error loading config: %v", err)
+	}
+
+	st, err := openStore(cfg.DatabaseDriver, cfg.DatabasePath)
+	if err != nil {
+		log.Fatalf("Error opening database: %v", err)
+	}
+	defer st.Close()
+
+	// Listen relies on being able to cancel in-flight requests on shutdown,
+	// which needs a concrete *http.Transport.
+	httpClient := http.Client{
+		Transport: &http.Transport{Proxy: http.ProxyFromEnvironment},
+	}
+	echoSuppresser := common.NewEchoSuppresser()
+
+	rooms, err := bridge.NewRoomMap(st)
+	if err != nil {
+		log.Fatalf("Error loading rooms: %v", err)
+	}
+	users, err := bridge.NewUserMap(st, httpClient, rooms, echoSuppresser)
+	if err != nil {
+		log.Fatalf("Error loading users: %v", err)
+	}
+
+	b := &bridge.Bridge{
+		UserMap:              users,
+		RoomMap:              rooms,
+		MessageMap:           bridge.NewMessageMap(st),
+		SlackRoomMembers:     slack.NewRoomMembers(),
+		MatrixUsers:          matrix.NewUsers(),
+		Client:               httpClient,
+		MatrixEchoSuppresser: echoSuppresser,
+		Config:               cfg.bridgeConfig(),
+	}
+
+	cancel := make(chan struct{})
+
+	mux := http.NewServeMux()
+
+	if cfg.HSToken != "" {
+		appService := matrix.NewAppService(cfg.HSToken, echoSuppresser)
+		appService.OnRoomMessage(b.OnMatrixRoomMessage)
+		appService.OnRoomMember(b.OnMatrixRoomMember)
+		appService.OnRedaction(b.OnMatrixRedaction)
+		appService.OnReaction(b.OnMatrixReaction)
+		mux.Handle("/transactions/", appService)
+		mux.Handle("/_matrix/app/v1/transactions/", appService)
+	} else {
+		matrixClient := matrix.NewClient(cfg.ASToken, httpClient, cfg.HomeserverURL, echoSuppresser)
+		matrixClient.OnRoomMessage(b.OnMatrixRoomMessage)
+		matrixClient.OnRoomMember(b.OnMatrixRoomMember)
+		matrixClient.OnRedaction(b.OnMatrixRedaction)
+		matrixClient.OnReaction(b.OnMatrixReaction)
+		matrixClient.ResumeFrom(rooms.LastMatrixStreamToken())
+		matrixClient.OnStreamToken(func(token string) {
+			if err := rooms.SaveMatrixStreamToken(token); err != nil {
+				log.Printf("Error saving matrix stream token: %v", err)
+			}
+		})
+		go matrixClient.Listen(cancel)
+	}
+
+	// In Socket Mode or with the Events API, one app receives the events for
+	// every channel it is in, and its bot token is preferred for reading and
+	// posting. Otherwise, we listen to RTM as each linked user.
+	var slackUsers []*slack.User
+	var eventsHandler *slack.EventsHandler
+	if cfg.SlackAppToken != "" {
+		client := slack.NewSocketModeClient(cfg.SlackBotToken, cfg.SlackAppToken, httpClient, rooms.ShouldNotify)
+		slackUsers = append(slackUsers, &slack.User{UserID: "", Client: client})
+	} else if cfg.SlackSigningSecret != "" {
+		// The Events API pushes to us, so there's nothing to listen to, but
+		// messages must be sent through the same client to suppress echoes.
+		client := slack.NewClient(cfg.SlackBotToken, httpClient, rooms.ShouldNotify)
+		client.OnMessage(b.OnSlackMessage)
+		client.OnReaction(b.OnSlackReaction)
+		eventsHandler = slack.NewEventsHandler(cfg.SlackSigningSecret, client)
+		mux.Handle("/slack/events", eventsHandler)
+		slackUsers = append(slackUsers, &slack.User{UserID: "", Client: client})
+	}
+	slackUsers = append(slackUsers, users.SlackUsers()...)
+
+	// We don't know which channels each user is in, so assume they can read
+	// and post to all of them; Slack will tell us if they can't.
+	var slackUsersMu sync.Mutex
+	for _, user := range slackUsers {
+		for _, channel := range rooms.SlackChannels() {
+			b.SlackRoomMembers.Add(channel, user)
+		}
+	}
+
+	b.CatchUpSlack()
+
+	// Each listener stops when we shut down, or when its user is unlinked.
+	unlinked := make(map[*slack.User]chan struct{})
+	listen := func(user *slack.User) {
+		listener, ok := user.Client.(slackListener)
+		if !ok {
+			log.Printf("Not listening for slack user %q: client can't listen", user.UserID)
+			return
+		}
+		stop := make(chan struct{})
+		unlinked[user] = stop
+		listenerCancel := make(chan struct{})
+		go func() {
+			select {
+			case <-cancel:
+			case <-stop:
+			}
+			close(listenerCancel)
+		}()
+		listener.OnMessage(b.OnSlackMessage)
+		listener.OnReaction(b.OnSlackReaction)
+		listener.OnConnectionState(catchUpOnReconnect(b, user.UserID))
+		go func(userID string) {
+			if err := listener.Listen(listenerCancel); err != nil {
+				log.Printf("Error listening to slack as %q: %v", userID, err)
+			}
+		}(user.UserID)
+	}
+	listenToUsers := cfg.SlackAppToken == "" && cfg.SlackSigningSecret == ""
+
+	listeners := slackUsers
+	if cfg.SlackAppToken != "" {
+		listeners = slackUsers[:1]
+	} else if cfg.SlackSigningSecret != "" {
+		listeners = nil
+	}
+	for _, user := range listeners {
+		listen(user)
+	}
+
+	if cfg.AdminToken != "" {
+		admin := bridge.NewAdminHandler(cfg.AdminToken, b)
+		admin.OnRoomLinked(func(channel string) {
+			slackUsersMu.Lock()
+			defer slackUsersMu.Unlock()
+			for _, user := range slackUsers {
+				b.SlackRoomMembers.Add(channel, user)
+			}
+		})
+		admin.OnUserLinked(func(user *slack.User) {
+			slackUsersMu.Lock()
+			defer slackUsersMu.Unlock()
+			slackUsers = append(slackUsers, user)
+			for _, channel := range rooms.SlackChannels() {
+				b.SlackRoomMembers.Add(channel, user)
+			}
+			if listenToUsers {
+				listen(user)
+			}
+		})
+		admin.OnUserUnlinked(func(user *slack.User) {
+			slackUsersMu.Lock()
+			defer slackUsersMu.Unlock()
+			for i, u := range slackUsers {
+				if u == user {
+					slackUsers = append(slackUsers[:i], slackUsers[i+1:]...)
+					break
+				}
+			}
+			b.SlackRoomMembers.Remove(user)
+			if stop, ok := unlinked[user]; ok {
+				close(stop)
+				delete(unlinked, user)
+			}
+		})
+		mux.Handle("/admin/", admin)
+	}
+
+	server := &http.Server{
+		Addr:    cfg.ListenAddress,
+		Handler: mux,
+	}
+	if cfg.ListenAddress != "" {
+		go func() {
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("Error serving HTTP: %v", err)
+			}
+		}()
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	s := <-signals
+	log.Printf("Got %v, shutting down", s)
+
+	close(cancel)
+	ctx, done := context.WithTimeout(context.Background(), 5*time.Second)
+	defer done()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Error shutting down HTTP server: %v", err)
+	}
+	if eventsHandler != nil {
+		eventsHandler.Wait()
+	}
+}
+
+// catchUpOnReconnect returns a connection state handler which replays missed
+// Slack messages whenever the connection comes back after being lost. The
+// initial catch up happens before listening starts.
+func catchUpOnReconnect(b *bridge.Bridge, userID string) func(slack.ConnectionState) {
+	var connectedBefore bool
+	return func(s slack.ConnectionState) {
+		log.Printf("Slack connection for %q is %v", userID, s)
+		if s != slack.Connected {
+			return
+		}
+		if connectedBefore {
+			b.CatchUpSlack()
+		}
+		connectedBefore = true
+	}
+}
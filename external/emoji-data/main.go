@@ -2,14 +2,28 @@ package main
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 )
 
+// Slack names skin tone variants of an emoji like "+1::skin-tone-2".
+var skinTones = map[string]string{
+	"1F3FB": "skin-tone-2",
+	"1F3FC": "skin-tone-3",
+	"1F3FD": "skin-tone-4",
+	"1F3FE": "skin-tone-5",
+	"1F3FF": "skin-tone-6",
+}
+
+var shortcodes = flag.Bool("shortcodes", false, "Generate the table from emoji to slack short names instead of the table of emoji")
+
 func main() {
+	flag.Parse()
 	f, err := os.Open("emoji.json")
 	if err != nil {
 		log.Fatal(err)
@@ -21,6 +35,11 @@ func main() {
 		log.Fatal(err)
 	}
 
+	if *shortcodes {
+		printShortcodes(allEmoji)
+		return
+	}
+
 	fmt.Println("// This file was generated by external/emoji-data/main.go - do not edit it manually")
 	fmt.Println("package bridge")
 	fmt.Println("")
@@ -46,7 +65,58 @@ func main() {
 	fmt.Println("}")
 }
 
+// printShortcodes prints a table from every form of every emoji, including
+// variation sequences and skin tones, to its slack name. Run it through gofmt.
+func printShortcodes(allEmoji []emojiEntry) {
+	names := make(map[string]string)
+	for _, e := range allEmoji {
+		names[codePoints(e.Unified)] = e.ShortName
+		for _, v := range e.Variations {
+			names[codePoints(v)] = e.ShortName
+		}
+		for unified := range e.SkinVariations {
+			parts := strings.Split(unified, "-")
+			tone, ok := skinTones[parts[len(parts)-1]]
+			if !ok {
+				log.Fatalf("Unknown skin tone in %q", unified)
+			}
+			names[codePoints(unified)] = e.ShortName + "::" + tone
+		}
+	}
+	var keys []string
+	for k := range names {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fmt.Println("// This file was generated by external/emoji-data/main.go -shortcodes - do not edit it manually")
+	fmt.Println("package bridge")
+	fmt.Println("")
+
+	fmt.Println("var emojiShortcodes = map[string]string{")
+	for _, k := range keys {
+		fmt.Printf("\t%+q: %q,\n", k, names[k])
+	}
+	fmt.Println("}")
+}
+
+// codePoints returns the string of code points written like "261D-1F3FB".
+func codePoints(unified string) string {
+	var s []rune
+	for _, part := range strings.Split(unified, "-") {
+		i, err := strconv.ParseInt(part, 16, 32)
+		if err != nil {
+			log.Fatalf("Error parsing emoji rune: %v", err)
+		}
+		s = append(s, rune(i))
+	}
+	return string(s)
+}
+
 type emojiEntry struct {
-	ShortNames []string `json:"short_names"`
-	Unified    string   `json:"unified"`
+	ShortName      string                     `json:"short_name"`
+	ShortNames     []string                   `json:"short_names"`
+	Unified        string                     `json:"unified"`
+	Variations     []string                   `json:"variations"`
+	SkinVariations map[string]json.RawMessage `json:"skin_variations"`
 }
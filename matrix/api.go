@@ -1,13 +1,21 @@
 package matrix
 
 type Client interface {
-	SendText(roomID, text string) error
-	SendImage(roomID, text string, image *Image) error
-	SendEmote(matrixRoom, emote string) error
+	// Send methods return the ID of the event they sent.
+	// formattedBody is the message as HTML, or "" if it has no formatting.
+	SendText(roomID, text, formattedBody string) (string, error)
+	SendImage(roomID, text string, image *Image) (string, error)
+	SendEmote(matrixRoom, emote, formattedBody string) (string, error)
+	SendMessage(roomID string, content *TextMessageContent) (string, error)
+	SendReaction(roomID, eventID, key string) (string, error)
+	Redact(roomID, eventID string) (string, error)
 	JoinRoom(roomID string) error
 	ListRooms() (map[string]bool, error)
 	GetRoomMembers(roomID string) (map[string]UserInfo, error)
 	Invite(roomID, userID string) error
+	PowerLevels(roomID string) (*PowerLevels, error)
+	CanonicalAlias(roomID string) (string, error)
+	ResolveAlias(alias string) (string, error)
 
 	Homeserver() string
 	AccessToken() string
@@ -0,0 +1,122 @@
+package matrix
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/matrix-org/slackbridge/common"
+)
+
+// How many transaction IDs to remember for deduplicating retried pushes.
+const seenTransactionsSize = 1000
+
+// NewAppService returns an http.Handler which receives the events a homeserver
+// pushes to an application service. Requests must carry hsToken, the token the
+// homeserver was configured to send in the application service registration.
+func NewAppService(hsToken string, echoSuppresser *common.EchoSuppresser) *AppService {
+	return &AppService{
+		hsToken:        hsToken,
+		echoSuppresser: echoSuppresser,
+		seen:           make(map[string]bool),
+	}
+}
+
+type AppService struct {
+	hsToken        string
+	echoSuppresser *common.EchoSuppresser
+
+	handlers
+
+	txnMu     sync.Mutex
+	seen      map[string]bool
+	seenOrder []string
+}
+
+func (a *AppService) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	txnID, ok := transactionID(req.URL.Path)
+	if !ok {
+		writeError(w, http.StatusNotFound, "M_UNRECOGNIZED", "Unrecognized request")
+		return
+	}
+	if req.Method != "PUT" {
+		writeError(w, http.StatusMethodNotAllowed, "M_UNRECOGNIZED", "Transactions must be PUT")
+		return
+	}
+	token := req.URL.Query().Get("access_token")
+	if auth := req.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		token = auth[len("Bearer "):]
+	}
+	if token == "" {
+		writeError(w, http.StatusUnauthorized, "M_UNAUTHORIZED", "Missing access token")
+		return
+	}
+	if token != a.hsToken {
+		writeError(w, http.StatusForbidden, "M_FORBIDDEN", "Bad access token")
+		return
+	}
+
+	// Transactions are processed one at a time so that a retry of a
+	// transaction which is still being processed isn't delivered twice.
+	a.txnMu.Lock()
+	defer a.txnMu.Unlock()
+	if a.seen[txnID] {
+		log.Printf("Skipping already processed transaction %q", txnID)
+		io.WriteString(w, "{}")
+		return
+	}
+
+	var t transaction
+	if err := json.NewDecoder(req.Body).Decode(&t); err != nil {
+		writeError(w, http.StatusBadRequest, "M_NOT_JSON", "Error decoding transaction")
+		return
+	}
+	a.echoSuppresser.Wait()
+	for _, raw := range t.Events {
+		a.dispatch(raw, a.echoSuppresser)
+	}
+	a.markSeen_Locked(txnID)
+	io.WriteString(w, "{}")
+}
+
+func (a *AppService) markSeen_Locked(txnID string) {
+	a.seen[txnID] = true
+	a.seenOrder = append(a.seenOrder, txnID)
+	if len(a.seenOrder) > seenTransactionsSize {
+		delete(a.seen, a.seenOrder[0])
+		a.seenOrder = a.seenOrder[1:]
+	}
+}
+
+// transactionID extracts the transaction ID from both the legacy and the
+// versioned transaction paths.
+func transactionID(path string) (string, bool) {
+	for _, prefix := range []string{"/_matrix/app/v1/transactions/", "/transactions/"} {
+		if strings.HasPrefix(path, prefix) {
+			txnID := path[len(prefix):]
+			if txnID == "" || strings.Contains(txnID, "/") {
+				return "", false
+			}
+			return txnID, true
+		}
+	}
+	return "", false
+}
+
+func writeError(w http.ResponseWriter, code int, errcode, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(errorResponse{errcode, message})
+}
+
+type transaction struct {
+	Events []json.RawMessage `json:"events"`
+}
+
+type errorResponse struct {
+	Errcode string `json:"errcode"`
+	Error   string `json:"error"`
+}
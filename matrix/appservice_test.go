@@ -0,0 +1,111 @@
+package matrix
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matrix-org/slackbridge/common"
+)
+
+const transactionBody = `{
+	"events": [{
+	  "content": {
+	    "body": "I'm a firewoman",
+	    "msgtype": "m.text"
+	  },
+	  "room_id": "!cantina:london",
+	  "type": "m.room.message",
+	  "sender": "@nancy:london",
+	  "event_id": "abc123:some.server"
+	}, {
+	  "content": {
+	    "membership": "join",
+	    "displayname": "ME!"
+	  },
+	  "room_id": "!cantina:london",
+	  "type": "m.room.member",
+	  "state_key": "@nancy:london",
+	  "sender": "@nancy:london",
+	  "event_id": "def456:some.server"
+	}]
+}`
+
+func TestAppServiceDeliversEvents(t *testing.T) {
+	as := NewAppService("hs_secret", common.NewEchoSuppresser())
+	var messages, members int
+	as.OnRoomMessage(func(m RoomMessage) {
+		if m.RoomID != "!cantina:london" {
+			t.Errorf("RoomID: want %q got %q", "!cantina:london", m.RoomID)
+		}
+		if m.UserID != "@nancy:london" {
+			t.Errorf("UserID: want %q got %q", "@nancy:london", m.UserID)
+		}
+		messages++
+	})
+	as.OnRoomMember(func(m RoomMemberEvent) {
+		if m.Content.DisplayName != "ME!" {
+			t.Errorf("DisplayName: want %q got %q", "ME!", m.Content.DisplayName)
+		}
+		if m.UserID != "@nancy:london" {
+			t.Errorf("UserID: want %q got %q", "@nancy:london", m.UserID)
+		}
+		members++
+	})
+
+	if code := putTransaction(as, "/transactions/1?access_token=hs_secret"); code != 200 {
+		t.Fatalf("Status: want %d got %d", 200, code)
+	}
+	if messages != 1 || members != 1 {
+		t.Errorf("Want 1 message and 1 member event, got %d and %d", messages, members)
+	}
+}
+
+func TestAppServiceDedupesTransactions(t *testing.T) {
+	as := NewAppService("hs_secret", common.NewEchoSuppresser())
+	var messages int
+	as.OnRoomMessage(func(m RoomMessage) {
+		messages++
+	})
+
+	putTransaction(as, "/_matrix/app/v1/transactions/1?access_token=hs_secret")
+	putTransaction(as, "/_matrix/app/v1/transactions/1?access_token=hs_secret")
+	if messages != 1 {
+		t.Errorf("Want 1 message after retried transaction, got %d", messages)
+	}
+	putTransaction(as, "/_matrix/app/v1/transactions/2?access_token=hs_secret")
+	if messages != 2 {
+		t.Errorf("Want 2 messages after new transaction, got %d", messages)
+	}
+}
+
+func TestAppServiceSuppressesEcho(t *testing.T) {
+	echoSuppresser := common.NewEchoSuppresser()
+	echoSuppresser.Sent("abc123:some.server")
+	as := NewAppService("hs_secret", echoSuppresser)
+	as.OnRoomMessage(func(m RoomMessage) {
+		t.Errorf("Should not have been called")
+	})
+	putTransaction(as, "/transactions/1?access_token=hs_secret")
+}
+
+func TestAppServiceRejectsBadToken(t *testing.T) {
+	as := NewAppService("hs_secret", common.NewEchoSuppresser())
+	as.OnRoomMessage(func(m RoomMessage) {
+		t.Errorf("Should not have been called")
+	})
+	if code := putTransaction(as, "/transactions/1?access_token=wrong"); code != 403 {
+		t.Errorf("Status: want %d got %d", 403, code)
+	}
+	if code := putTransaction(as, "/transactions/1"); code != 401 {
+		t.Errorf("Status: want %d got %d", 401, code)
+	}
+}
+
+func putTransaction(h http.Handler, url string) int {
+	req := httptest.NewRequest("PUT", url, strings.NewReader(transactionBody))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w.Code
+}
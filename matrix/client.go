@@ -8,9 +8,8 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
-	"strconv"
+	"net/url"
 	"strings"
-	"sync"
 
 	"github.com/matrix-org/slackbridge/common"
 )
@@ -45,9 +44,10 @@ type client struct {
 	urlBase        string
 	echoSuppresser *common.EchoSuppresser
 
-	mu                  sync.Mutex
-	roomMessageHandlers []func(RoomMessage)
-	roomMemberHandlers  []func(RoomMemberEvent)
+	handlers
+
+	from                string
+	streamTokenHandlers []func(string)
 }
 
 func (c *client) Homeserver() string {
@@ -58,9 +58,23 @@ func (c *client) AccessToken() string {
 	return c.accessToken
 }
 
+// ResumeFrom makes Listen start from the given stream token, rather than only
+// returning events which happen after it starts.
+func (c *client) ResumeFrom(token string) {
+	c.from = token
+}
+
+// OnStreamToken registers a handler which is called with the stream token to
+// resume from, once all events before it have been handled.
+func (c *client) OnStreamToken(h func(string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.streamTokenHandlers = append(c.streamTokenHandlers, h)
+}
+
 func (c *client) Listen(cancel chan struct{}) {
 	ch := make(chan *http.Response)
-	var last string
+	last := c.from
 	for {
 		qs := c.querystring()
 		if last != "" {
@@ -78,7 +92,14 @@ func (c *client) Listen(cancel chan struct{}) {
 				continue
 			}
 			c.echoSuppresser.Wait()
-			last = c.parseResponse(resp.Body)
+			end := c.parseResponse(resp.Body)
+			if end == "" || end == last {
+				continue
+			}
+			last = end
+			for _, h := range c.streamTokenHandlers {
+				h(last)
+			}
 		case <-cancel:
 			if transport, ok := (c.client.Transport).(*http.Transport); ok {
 				transport.CancelRequest(req)
@@ -97,44 +118,7 @@ func (c *client) parseResponse(body io.ReadCloser) string {
 		return ""
 	}
 	for _, raw := range er.Chunk {
-		log.Printf("Got matrix event: %s", string(raw))
-		var t typedThing
-		if err := json.Unmarshal(raw, &t); err != nil {
-			log.Printf("Error finding type: %v", err)
-			continue
-		}
-		switch t.Type {
-		case "m.room.message":
-			var roomMessage RoomMessage
-			if err := json.Unmarshal(raw, &roomMessage); err != nil {
-				log.Printf("Error decoding inner json: %v", err)
-				continue
-			}
-			if c.echoSuppresser.WasSent(roomMessage.EventID) {
-				log.Printf("Skipping filtered message: %v", roomMessage)
-				continue
-			}
-			if len(c.roomMessageHandlers) == 0 {
-				log.Printf("No listeners for room message events")
-			}
-			for _, h := range c.roomMessageHandlers {
-				h(roomMessage)
-			}
-		case "m.room.member":
-			var roomMember RoomMemberEvent
-			if err := json.Unmarshal(raw, &roomMember); err != nil {
-				log.Printf("Error decoding inner json: %v", err)
-				continue
-			}
-			if len(c.roomMemberHandlers) == 0 {
-				log.Printf("No listeners for room member events")
-			}
-			for _, h := range c.roomMemberHandlers {
-				h(roomMember)
-			}
-		default:
-			log.Printf("Ignoring unknown event %q", string(raw))
-		}
+		c.dispatch(raw, c.echoSuppresser)
 	}
 	return er.End
 }
@@ -152,36 +136,14 @@ type eventsReply struct {
 	End   string            `json:"end"`
 }
 
-type typedThing struct {
-	Type string `json:"type"`
+func (c *client) SendText(roomID, text, formattedBody string) (string, error) {
+	return c.SendMessage(roomID, newTextMessageContent("m.text", text, formattedBody))
 }
 
-func (c *client) OnRoomMessage(h func(RoomMessage)) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.roomMessageHandlers = append(c.roomMessageHandlers, h)
-}
-
-func (c *client) OnRoomMember(h func(RoomMemberEvent)) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.roomMemberHandlers = append(c.roomMemberHandlers, h)
-}
-
-func (c *client) SendText(roomID, text string) error {
-	message := &TextMessageContent{
-		Body:    text,
-		MsgType: "m.text",
-	}
-
-	_, err := c.postEvent(roomID, message)
-	return err
-}
-
-func (c *client) SendImage(roomID, text string, image *Image) error {
+func (c *client) SendImage(roomID, text string, image *Image) (string, error) {
 	imageURL, err := c.uploadImage(image)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	message := &ImageMessageContent{
@@ -191,50 +153,82 @@ func (c *client) SendImage(roomID, text string, image *Image) error {
 		Info:    image.Info,
 	}
 
-	_, err = c.postEvent(roomID, message)
-	return err
+	return c.postEvent(roomID, "m.room.message", message)
 }
 
-func (c *client) SendEmote(roomID, emote string) error {
-	message := &TextMessageContent{
-		Body:    emote,
-		MsgType: "m.emote",
-	}
+func (c *client) SendEmote(roomID, emote, formattedBody string) (string, error) {
+	return c.SendMessage(roomID, newTextMessageContent("m.emote", emote, formattedBody))
+}
+
+func (c *client) SendMessage(roomID string, content *TextMessageContent) (string, error) {
+	return c.postEvent(roomID, "m.room.message", content)
+}
 
-	_, err := c.postEvent(roomID, message)
-	return err
+// SendReaction annotates the event eventID with key, which is usually an
+// emoji.
+func (c *client) SendReaction(roomID, eventID, key string) (string, error) {
+	return c.postEvent(roomID, "m.reaction", &ReactionContent{
+		RelatesTo: &RelatesTo{
+			RelType: "m.annotation",
+			EventID: eventID,
+			Key:     key,
+		},
+	})
 }
 
+// maxImageSize is the largest image which we fetch to upload, which is
+// Synapse's default upload limit.
+const maxImageSize = 50 << 20
+
+// uploadImage fetches image to upload it to the homeserver. The URL usually
+// comes from someone else's message, so we only fetch images over https, and
+// only upload what is served as an image no bigger than maxImageSize.
 func (c *client) uploadImage(image *Image) (string, error) {
-	resp, err := c.client.Get(image.URL)
+	if u, err := url.Parse(image.URL); err != nil || u.Scheme != "https" {
+		return "", fmt.Errorf("refusing to fetch image from non-https URL %q", image.URL)
+	}
+	fetcher := c.client
+	fetcher.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if req.URL.Scheme != "https" {
+			return fmt.Errorf("refusing to follow redirect to non-https URL %q", req.URL)
+		}
+		if len(via) >= 10 {
+			return fmt.Errorf("stopped after %d redirects", len(via))
+		}
+		return nil
+	}
+	resp, err := fetcher.Get(image.URL)
 	if err != nil {
 		return "", err
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != 200 {
 		return "", fmt.Errorf("bad response from image GET: %s", resp.Status)
 	}
-	defer resp.Body.Close()
-	req, err := http.NewRequest("POST", c.urlBase+"/_matrix/media/v1/upload"+c.querystring(), resp.Body)
+	contentType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "image/") {
+		return "", fmt.Errorf("refusing to upload %q from %q: not an image", contentType, image.URL)
+	}
+	if resp.ContentLength > maxImageSize {
+		return "", fmt.Errorf("refusing to upload image of %d bytes from %q", resp.ContentLength, image.URL)
+	}
+	// Not every server says how big the image is, so we read it all before
+	// uploading it.
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return "", fmt.Errorf("error reading image: %v", err)
+	}
+	if len(body) > maxImageSize {
+		return "", fmt.Errorf("refusing to upload image of more than %d bytes from %q", maxImageSize, image.URL)
+	}
+	req, err := http.NewRequest("POST", c.urlBase+"/_matrix/media/v1/upload"+c.querystring(), bytes.NewReader(body))
 	if err != nil {
 		return "", fmt.Errorf("error creating http request: %v", err)
 	}
-
-	var contentType string
 	if image.Info != nil && image.Info.MIMEType != "" {
 		contentType = image.Info.MIMEType
-	} else {
-		contentType = resp.Header.Get("Content-Type")
 	}
 	req.Header.Set("Content-Type", contentType)
-	if image.Info != nil && image.Info.Size > 0 {
-		req.ContentLength = image.Info.Size
-	} else {
-		length, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
-		if err != nil {
-			return "", fmt.Errorf("error parsing content-length header: %v", err)
-		}
-		req.ContentLength = length
-	}
 
 	uploadResp, err := c.client.Do(req)
 	if err != nil {
@@ -257,7 +251,7 @@ type uploadResponse struct {
 	ContentURI string `json:"content_uri"`
 }
 
-func (c *client) postEvent(roomID string, event interface{}) (*http.Response, error) {
+func (c *client) postEvent(roomID, eventType string, event interface{}) (string, error) {
 	r, w := io.Pipe()
 	go func() {
 		enc := json.NewEncoder(w)
@@ -268,27 +262,52 @@ func (c *client) postEvent(roomID string, event interface{}) (*http.Response, er
 	c.echoSuppresser.StartSending()
 	defer c.echoSuppresser.DoneSending()
 
-	url := c.urlBase + pathPrefix + "/rooms/" + roomID + "/send/m.room.message" + c.querystring()
+	url := c.urlBase + pathPrefix + "/rooms/" + roomID + "/send/" + eventType + c.querystring()
 	resp, err := c.client.Post(url, "application/json", r)
 	if err != nil {
-		return nil, fmt.Errorf("error from homeserver: %v", err)
+		return "", fmt.Errorf("error from homeserver: %v", err)
 	}
 	defer resp.Body.Close()
 	b, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return resp, fmt.Errorf("error reading response from homeserver: %v", err)
+		return "", fmt.Errorf("error reading response from homeserver: %v", err)
 	}
 	if resp.StatusCode != 200 {
-		return resp, fmt.Errorf("error from homeserver: %d: %s", resp.StatusCode, string(b))
+		return "", fmt.Errorf("error from homeserver: %d: %s", resp.StatusCode, string(b))
 	}
 	var e eventSendResponse
 	if err := json.Unmarshal(b, &e); err != nil {
 		log.Printf("Error unmarshaling event send response: %v (%s)", err, string(b))
-	} else {
-		log.Printf("Sent matrix event with ID: %s", e.EventID)
-		c.echoSuppresser.Sent(e.EventID)
+		return "", nil
 	}
-	return resp, nil
+	log.Printf("Sent matrix event with ID: %s", e.EventID)
+	c.echoSuppresser.Sent(e.EventID)
+	return e.EventID, nil
+}
+
+func (c *client) Redact(roomID, eventID string) (string, error) {
+	c.echoSuppresser.StartSending()
+	defer c.echoSuppresser.DoneSending()
+
+	url := c.urlBase + pathPrefix + "/rooms/" + roomID + "/redact/" + eventID + c.querystring()
+	resp, err := c.client.Post(url, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		return "", fmt.Errorf("error from homeserver: %v", err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading response from homeserver: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("error from homeserver: %d: %s", resp.StatusCode, string(b))
+	}
+	var e eventSendResponse
+	if err := json.Unmarshal(b, &e); err != nil {
+		return "", fmt.Errorf("error unmarshaling redact response: %v", err)
+	}
+	c.echoSuppresser.Sent(e.EventID)
+	return e.EventID, nil
 }
 
 func (c *client) JoinRoom(roomID string) error {
@@ -404,6 +423,74 @@ func (c *client) GetRoomMembers(roomID string) (map[string]UserInfo, error) {
 
 }
 
+func (c *client) PowerLevels(roomID string) (*PowerLevels, error) {
+	url := c.urlBase + pathPrefix + "/rooms/" + roomID + "/state/m.room.power_levels" + c.querystring()
+	resp, err := c.client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("error from homeserver: %v", err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response from homeserver: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("error from homeserver: %d: %s", resp.StatusCode, string(b))
+	}
+	var p PowerLevels
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("error unmarshaling power levels: %v", err)
+	}
+	return &p, nil
+}
+
+// CanonicalAlias returns the canonical alias of roomID, or "" if it has none.
+func (c *client) CanonicalAlias(roomID string) (string, error) {
+	var content struct {
+		Alias string `json:"alias"`
+	}
+	if err := c.get(c.urlBase+pathPrefix+"/rooms/"+roomID+"/state/m.room.canonical_alias"+c.querystring(), &content); err != nil {
+		return "", err
+	}
+	return content.Alias, nil
+}
+
+// ResolveAlias returns the ID of the room which alias points to, or "" if it
+// doesn't point anywhere.
+func (c *client) ResolveAlias(alias string) (string, error) {
+	var r struct {
+		RoomID string `json:"room_id"`
+	}
+	if err := c.get(c.urlBase+pathPrefix+"/directory/room/"+url.PathEscape(alias)+c.querystring(), &r); err != nil {
+		return "", err
+	}
+	return r.RoomID, nil
+}
+
+// get unmarshals the JSON at url into v, leaving v alone if the homeserver
+// has nothing there.
+func (c *client) get(url string, v interface{}) error {
+	resp, err := c.client.Get(url)
+	if err != nil {
+		return fmt.Errorf("error from homeserver: %v", err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response from homeserver: %v", err)
+	}
+	if resp.StatusCode == 404 {
+		return nil
+	}
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("error from homeserver: %d: %s", resp.StatusCode, string(b))
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("error unmarshaling response from homeserver: %v", err)
+	}
+	return nil
+}
+
 func (c *client) querystring() string {
 	qs := "?access_token=" + c.accessToken
 	if c.asUser != "" {
@@ -3,9 +3,14 @@ package matrix
 import (
 	"encoding/json"
 	"io"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -30,7 +35,7 @@ func TestSendTextMessage(t *testing.T) {
 	}})
 	defer s.Close()
 	c := NewClient("6000000000peopleandyou", http.Client{}, s.URL, common.NewEchoSuppresser())
-	c.SendText("!undertheclock:waterloo.station", "quid pro quo")
+	c.SendText("!undertheclock:waterloo.station", "quid pro quo", "")
 	if got := atomic.LoadInt32(&called); got != 1 {
 		t.Fatalf("Didn't get expected HTTP request, got: %d", got)
 	}
@@ -54,12 +59,215 @@ func TestSendEmoteMessage(t *testing.T) {
 	}})
 	defer s.Close()
 	c := NewClient("6000000000peopleandyou", http.Client{}, s.URL, common.NewEchoSuppresser())
-	c.SendEmote("!undertheclock:waterloo.station", "puts the fire out")
+	c.SendEmote("!undertheclock:waterloo.station", "puts the fire out", "")
 	if got := atomic.LoadInt32(&called); got != 1 {
 		t.Fatalf("Didn't get expected HTTP request, got: %d", got)
 	}
 }
 
+func TestSendFormattedText(t *testing.T) {
+	var got map[string]interface{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := json.NewDecoder(req.Body).Decode(&got); err != nil {
+			t.Errorf("Error decoding json: %v", err)
+		}
+		io.WriteString(w, `{"event_id": "$text:waterloo.station"}`)
+	}))
+	defer s.Close()
+	c := NewClient("6000000000peopleandyou", http.Client{}, s.URL, common.NewEchoSuppresser())
+	if _, err := c.SendText("!undertheclock:waterloo.station", "*quid* pro quo", "<strong>quid</strong> pro quo"); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"body":           "*quid* pro quo",
+		"msgtype":        "m.text",
+		"format":         "org.matrix.custom.html",
+		"formatted_body": "<strong>quid</strong> pro quo",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v got %v", want, got)
+	}
+}
+
+func TestSendEdit(t *testing.T) {
+	var got map[string]interface{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := json.NewDecoder(req.Body).Decode(&got); err != nil {
+			t.Errorf("Error decoding json: %v", err)
+		}
+		io.WriteString(w, `{"event_id": "$edit:waterloo.station"}`)
+	}))
+	defer s.Close()
+	echoSuppresser := common.NewEchoSuppresser()
+	c := NewClient("6000000000peopleandyou", http.Client{}, s.URL, echoSuppresser)
+	eventID, err := c.SendMessage("!undertheclock:waterloo.station", NewEdit("$original:waterloo.station", &TextMessageContent{
+		Body:    "quid pro quo, Clarice",
+		MsgType: "m.text",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "$edit:waterloo.station"; eventID != want {
+		t.Errorf("event ID: want %q got %q", want, eventID)
+	}
+	if !echoSuppresser.WasSent(eventID) {
+		t.Errorf("want edit to be echo suppressed")
+	}
+	want := map[string]interface{}{
+		"body":    "* quid pro quo, Clarice",
+		"msgtype": "m.text",
+		"m.new_content": map[string]interface{}{
+			"body":    "quid pro quo, Clarice",
+			"msgtype": "m.text",
+		},
+		"m.relates_to": map[string]interface{}{
+			"rel_type": "m.replace",
+			"event_id": "$original:waterloo.station",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v got %v", want, got)
+	}
+}
+
+func TestSendReaction(t *testing.T) {
+	var path string
+	var got map[string]interface{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		path = req.URL.Path
+		if err := json.NewDecoder(req.Body).Decode(&got); err != nil {
+			t.Errorf("Error decoding json: %v", err)
+		}
+		io.WriteString(w, `{"event_id": "$reaction:waterloo.station"}`)
+	}))
+	defer s.Close()
+	c := NewClient("6000000000peopleandyou", http.Client{}, s.URL, common.NewEchoSuppresser())
+	eventID, err := c.SendReaction("!undertheclock:waterloo.station", "$original:waterloo.station", "👍")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "$reaction:waterloo.station"; eventID != want {
+		t.Errorf("event ID: want %q got %q", want, eventID)
+	}
+	if want := "/_matrix/client/api/v1/rooms/!undertheclock:waterloo.station/send/m.reaction"; path != want {
+		t.Errorf("path: want %q got %q", want, path)
+	}
+	want := map[string]interface{}{
+		"m.relates_to": map[string]interface{}{
+			"rel_type": "m.annotation",
+			"event_id": "$original:waterloo.station",
+			"key":      "👍",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v got %v", want, got)
+	}
+}
+
+func TestSendImage(t *testing.T) {
+	var uploaded, uploadedType string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graph.png", func(w http.ResponseWriter, req *http.Request) {
+		// Flushing before the end means there's no Content-Length.
+		w.Header().Set("Content-Type", "image/png")
+		io.WriteString(w, "not really ")
+		w.(http.Flusher).Flush()
+		io.WriteString(w, "a png")
+	})
+	mux.HandleFunc("/_matrix/media/v1/upload", func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		uploaded, uploadedType = string(body), req.Header.Get("Content-Type")
+		io.WriteString(w, `{"content_uri": "mxc://st.andrews/abc"}`)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		io.WriteString(w, `{"event_id": "$image"}`)
+	})
+	s := httptest.NewTLSServer(mux)
+	defer s.Close()
+
+	c := NewClient("6000000000peopleandyou", *s.Client(), s.URL, common.NewEchoSuppresser())
+	eventID, err := c.SendImage("!undertheclock:waterloo.station", "graph", &Image{URL: s.URL + "/graph.png"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eventID != "$image" {
+		t.Errorf("event ID: want %q got %q", "$image", eventID)
+	}
+	if uploaded != "not really a png" || uploadedType != "image/png" {
+		t.Errorf("uploaded: want %q, %q got %q, %q", "not really a png", "image/png", uploaded, uploadedType)
+	}
+}
+
+func TestSendImageRefusesUnsafeImages(t *testing.T) {
+	var uploads int32
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		io.WriteString(w, "from the intranet")
+	}))
+	defer plain.Close()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page.html", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, "<html></html>")
+	})
+	mux.HandleFunc("/huge.png", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("Content-Length", strconv.Itoa(maxImageSize+1))
+	})
+	mux.HandleFunc("/redirect.png", func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, plain.URL+"/graph.png", http.StatusFound)
+	})
+	mux.HandleFunc("/_matrix/media/v1/upload", func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&uploads, 1)
+		io.WriteString(w, `{"content_uri": "mxc://st.andrews/abc"}`)
+	})
+	s := httptest.NewTLSServer(mux)
+	defer s.Close()
+
+	c := NewClient("6000000000peopleandyou", *s.Client(), s.URL, common.NewEchoSuppresser())
+	for _, url := range []string{
+		plain.URL + "/graph.png",
+		s.URL + "/page.html",
+		s.URL + "/huge.png",
+		s.URL + "/redirect.png",
+	} {
+		if _, err := c.SendImage("!undertheclock:waterloo.station", "graph", &Image{URL: url}); err == nil || !strings.Contains(err.Error(), "refusing") {
+			t.Errorf("SendImage(%q): want refusal got %v", url, err)
+		}
+	}
+	if uploads != 0 {
+		t.Errorf("want no uploads got %d", uploads)
+	}
+}
+
+func TestAliases(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.EscapedPath() {
+		case "/_matrix/client/api/v1/rooms/!undertheclock:waterloo.station/state/m.room.canonical_alias":
+			io.WriteString(w, `{"alias": "#clock:waterloo.station"}`)
+		case "/_matrix/client/api/v1/directory/room/%23clock:waterloo.station":
+			io.WriteString(w, `{"room_id": "!undertheclock:waterloo.station"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"errcode": "M_NOT_FOUND"}`)
+		}
+	}))
+	defer s.Close()
+	c := NewClient("6000000000peopleandyou", http.Client{}, s.URL, common.NewEchoSuppresser())
+	if alias, err := c.CanonicalAlias("!undertheclock:waterloo.station"); err != nil || alias != "#clock:waterloo.station" {
+		t.Errorf("CanonicalAlias: want %q, nil got %q, %v", "#clock:waterloo.station", alias, err)
+	}
+	if alias, err := c.CanonicalAlias("!platform9:waterloo.station"); err != nil || alias != "" {
+		t.Errorf("CanonicalAlias without alias: want \"\", nil got %q, %v", alias, err)
+	}
+	if roomID, err := c.ResolveAlias("#clock:waterloo.station"); err != nil || roomID != "!undertheclock:waterloo.station" {
+		t.Errorf("ResolveAlias: want %q, nil got %q, %v", "!undertheclock:waterloo.station", roomID, err)
+	}
+	if roomID, err := c.ResolveAlias("#platform9:waterloo.station"); err != nil || roomID != "" {
+		t.Errorf("ResolveAlias unknown: want \"\", nil got %q, %v", roomID, err)
+	}
+}
+
 func TestListenOneRoomMessage(t *testing.T) {
 	listenTest(t, common.NewEchoSuppresser(), func(called chan struct{}) {
 		select {
@@ -172,6 +380,80 @@ func TestMemberEvent(t *testing.T) {
 	}
 }
 
+func TestRedactionEvent(t *testing.T) {
+	s := httptest.NewServer(&stubHandler{`{
+	"chunk": [{
+	  "content": {},
+	  "redacts": "abc123:some.server",
+	  "room_id": "!cantina:london",
+	  "type": "m.room.redaction",
+	  "user_id": "@nancy:london",
+	  "event_id": "def456:some.server"
+	}],
+	"start": "1",
+	"end": "1"
+}`})
+	defer s.Close()
+
+	called := make(chan Redaction, 1)
+	c := NewClient("6000000000peopleandyou", http.Client{}, s.URL, common.NewEchoSuppresser())
+	c.OnRedaction(func(r Redaction) {
+		called <- r
+	})
+	ch := make(chan struct{}, 1)
+	defer func() { ch <- struct{}{} }()
+	go c.Listen(ch)
+
+	select {
+	case r := <-called:
+		if r.Redacts != "abc123:some.server" || r.RoomID != "!cantina:london" || r.UserID != "@nancy:london" {
+			t.Errorf("Wrong redaction: %v", r)
+		}
+	case _ = <-time.After(50 * time.Millisecond):
+		t.Fatalf("Timed out waiting for event")
+	}
+}
+
+func TestReactionEvent(t *testing.T) {
+	s := httptest.NewServer(&stubHandler{`{
+	"chunk": [{
+	  "content": {
+	    "m.relates_to": {
+	      "rel_type": "m.annotation",
+	      "event_id": "abc123:some.server",
+	      "key": "🔥"
+	    }
+	  },
+	  "room_id": "!cantina:london",
+	  "type": "m.reaction",
+	  "user_id": "@nancy:london",
+	  "event_id": "def456:some.server"
+	}],
+	"start": "1",
+	"end": "1"
+}`})
+	defer s.Close()
+
+	called := make(chan Reaction, 1)
+	c := NewClient("6000000000peopleandyou", http.Client{}, s.URL, common.NewEchoSuppresser())
+	c.OnReaction(func(r Reaction) {
+		called <- r
+	})
+	ch := make(chan struct{}, 1)
+	defer func() { ch <- struct{}{} }()
+	go c.Listen(ch)
+
+	select {
+	case r := <-called:
+		want := RelatesTo{RelType: "m.annotation", EventID: "abc123:some.server", Key: "🔥"}
+		if r.Content.RelatesTo == nil || *r.Content.RelatesTo != want || r.RoomID != "!cantina:london" || r.UserID != "@nancy:london" {
+			t.Errorf("Wrong reaction: %v", r)
+		}
+	case _ = <-time.After(50 * time.Millisecond):
+		t.Fatalf("Timed out waiting for event")
+	}
+}
+
 type handler struct {
 	t      *testing.T
 	called *int32
@@ -193,3 +475,41 @@ type stubHandler struct {
 func (h *stubHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	io.WriteString(w, h.response)
 }
+
+func TestListenResumesFromStreamToken(t *testing.T) {
+	var from string
+	var mu sync.Mutex
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		if from == "" {
+			from = req.URL.Query().Get("from")
+		}
+		mu.Unlock()
+		io.WriteString(w, `{"chunk": [], "start": "s1", "end": "s2"}`)
+	}))
+	defer s.Close()
+
+	tokens := make(chan string, 1)
+	c := NewClient("6000000000peopleandyou", http.Client{}, s.URL, common.NewEchoSuppresser())
+	c.ResumeFrom("s1")
+	c.OnStreamToken(func(token string) {
+		tokens <- token
+	})
+	ch := make(chan struct{}, 1)
+	defer func() { ch <- struct{}{} }()
+	go c.Listen(ch)
+
+	select {
+	case got := <-tokens:
+		if got != "s2" {
+			t.Errorf("Stream token: want %q got %q", "s2", got)
+		}
+	case _ = <-time.After(50 * time.Millisecond):
+		t.Fatalf("Timed out waiting for stream token")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if from != "s1" {
+		t.Errorf("from: want %q got %q", "s1", from)
+	}
+}
@@ -13,6 +13,71 @@ type RoomMessage struct {
 type TextMessageContent struct {
 	Body    string `json:"body"`
 	MsgType string `json:"msgtype"`
+
+	Format        string    `json:"format,omitempty"`
+	FormattedBody string    `json:"formatted_body,omitempty"`
+	Mentions      *Mentions `json:"m.mentions,omitempty"`
+
+	NewContent *TextMessageContent `json:"m.new_content,omitempty"`
+	RelatesTo  *RelatesTo          `json:"m.relates_to,omitempty"`
+}
+
+// Mentions says who a message should notify.
+type Mentions struct {
+	UserIDs []string `json:"user_ids,omitempty"`
+	Room    bool     `json:"room,omitempty"`
+}
+
+type RelatesTo struct {
+	RelType string `json:"rel_type,omitempty"`
+	EventID string `json:"event_id,omitempty"`
+	// Set on annotations.
+	Key string `json:"key,omitempty"`
+
+	InReplyTo *InReplyTo `json:"m.in_reply_to,omitempty"`
+	// Set on thread events whose InReplyTo is only for clients which don't
+	// understand threads.
+	IsFallingBack bool `json:"is_falling_back,omitempty"`
+}
+
+type InReplyTo struct {
+	EventID string `json:"event_id"`
+}
+
+type ReactionContent struct {
+	RelatesTo *RelatesTo `json:"m.relates_to"`
+}
+
+// NewEdit returns content which replaces the event eventID with content,
+// falling back to showing the new content as an edit in clients which don't
+// understand edits.
+func NewEdit(eventID string, content *TextMessageContent) *TextMessageContent {
+	edit := &TextMessageContent{
+		Body:       "* " + content.Body,
+		MsgType:    content.MsgType,
+		NewContent: content,
+		RelatesTo: &RelatesTo{
+			RelType: "m.replace",
+			EventID: eventID,
+		},
+	}
+	if content.FormattedBody != "" {
+		edit.Format = content.Format
+		edit.FormattedBody = "* " + content.FormattedBody
+	}
+	return edit
+}
+
+func newTextMessageContent(msgType, body, formattedBody string) *TextMessageContent {
+	content := &TextMessageContent{
+		Body:    body,
+		MsgType: msgType,
+	}
+	if formattedBody != "" {
+		content.Format = "org.matrix.custom.html"
+		content.FormattedBody = formattedBody
+	}
+	return content
 }
 
 type ImageMessageContent struct {
@@ -53,3 +118,53 @@ type RoomMemberEvent struct {
 	RoomID   string   `json:"room_id"`
 	UserID   string   `json:"user_id"`
 }
+
+type Redaction struct {
+	Type    string `json:"type"`
+	Redacts string `json:"redacts"`
+	UserID  string `json:"user_id"`
+	RoomID  string `json:"room_id"`
+	EventID string `json:"event_id"`
+}
+
+type Reaction struct {
+	Type    string          `json:"type"`
+	Content ReactionContent `json:"content"`
+	UserID  string          `json:"user_id"`
+	RoomID  string          `json:"room_id"`
+	EventID string          `json:"event_id"`
+}
+
+type PowerLevels struct {
+	Users         map[string]int `json:"users"`
+	UsersDefault  int            `json:"users_default"`
+	Redact        *int           `json:"redact"`
+	Notifications struct {
+		Room *int `json:"room"`
+	} `json:"notifications"`
+}
+
+// UserLevel returns the power level of userID.
+func (p *PowerLevels) UserLevel(userID string) int {
+	if level, ok := p.Users[userID]; ok {
+		return level
+	}
+	return p.UsersDefault
+}
+
+// RedactLevel returns the power level needed to redact other users' events.
+func (p *PowerLevels) RedactLevel() int {
+	if p.Redact == nil {
+		return 50
+	}
+	return *p.Redact
+}
+
+// RoomNotificationLevel returns the power level needed to notify the whole
+// room with @room.
+func (p *PowerLevels) RoomNotificationLevel() int {
+	if p.Notifications.Room == nil {
+		return 50
+	}
+	return *p.Notifications.Room
+}
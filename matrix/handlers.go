@@ -0,0 +1,135 @@
+package matrix
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+
+	"github.com/matrix-org/slackbridge/common"
+)
+
+// handlers dispatches raw Matrix events to registered callbacks. It is shared
+// by everything which receives events from a homeserver.
+type handlers struct {
+	mu                  sync.Mutex
+	roomMessageHandlers []func(RoomMessage)
+	roomMemberHandlers  []func(RoomMemberEvent)
+	redactionHandlers   []func(Redaction)
+	reactionHandlers    []func(Reaction)
+}
+
+func (h *handlers) OnRoomMessage(f func(RoomMessage)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.roomMessageHandlers = append(h.roomMessageHandlers, f)
+}
+
+func (h *handlers) OnRoomMember(f func(RoomMemberEvent)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.roomMemberHandlers = append(h.roomMemberHandlers, f)
+}
+
+func (h *handlers) OnRedaction(f func(Redaction)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.redactionHandlers = append(h.redactionHandlers, f)
+}
+
+func (h *handlers) OnReaction(f func(Reaction)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.reactionHandlers = append(h.reactionHandlers, f)
+}
+
+func (h *handlers) dispatch(raw json.RawMessage, echoSuppresser *common.EchoSuppresser) {
+	log.Printf("Got matrix event: %s", string(raw))
+	var t typedThing
+	if err := json.Unmarshal(raw, &t); err != nil {
+		log.Printf("Error finding type: %v", err)
+		return
+	}
+	switch t.Type {
+	case "m.room.message":
+		var roomMessage RoomMessage
+		if err := json.Unmarshal(raw, &roomMessage); err != nil {
+			log.Printf("Error decoding inner json: %v", err)
+			return
+		}
+		roomMessage.UserID = t.sender()
+		if echoSuppresser.WasSent(roomMessage.EventID) {
+			log.Printf("Skipping filtered message: %v", roomMessage)
+			return
+		}
+		if len(h.roomMessageHandlers) == 0 {
+			log.Printf("No listeners for room message events")
+		}
+		for _, f := range h.roomMessageHandlers {
+			f(roomMessage)
+		}
+	case "m.room.member":
+		var roomMember RoomMemberEvent
+		if err := json.Unmarshal(raw, &roomMember); err != nil {
+			log.Printf("Error decoding inner json: %v", err)
+			return
+		}
+		roomMember.UserID = t.sender()
+		if len(h.roomMemberHandlers) == 0 {
+			log.Printf("No listeners for room member events")
+		}
+		for _, f := range h.roomMemberHandlers {
+			f(roomMember)
+		}
+	case "m.room.redaction":
+		var redaction Redaction
+		if err := json.Unmarshal(raw, &redaction); err != nil {
+			log.Printf("Error decoding inner json: %v", err)
+			return
+		}
+		redaction.UserID = t.sender()
+		if echoSuppresser.WasSent(redaction.EventID) {
+			log.Printf("Skipping filtered redaction: %v", redaction)
+			return
+		}
+		if len(h.redactionHandlers) == 0 {
+			log.Printf("No listeners for redaction events")
+		}
+		for _, f := range h.redactionHandlers {
+			f(redaction)
+		}
+	case "m.reaction":
+		var reaction Reaction
+		if err := json.Unmarshal(raw, &reaction); err != nil {
+			log.Printf("Error decoding inner json: %v", err)
+			return
+		}
+		reaction.UserID = t.sender()
+		if echoSuppresser.WasSent(reaction.EventID) {
+			log.Printf("Skipping filtered reaction: %v", reaction)
+			return
+		}
+		if len(h.reactionHandlers) == 0 {
+			log.Printf("No listeners for reaction events")
+		}
+		for _, f := range h.reactionHandlers {
+			f(reaction)
+		}
+	default:
+		log.Printf("Ignoring unknown event %q", string(raw))
+	}
+}
+
+// typedThing is what every event has in common. Events pushed to application
+// services say who sent them in sender, and the legacy event stream in user_id.
+type typedThing struct {
+	Type   string `json:"type"`
+	Sender string `json:"sender"`
+	UserID string `json:"user_id"`
+}
+
+func (t *typedThing) sender() string {
+	if t.Sender != "" {
+		return t.Sender
+	}
+	return t.UserID
+}
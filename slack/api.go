@@ -1,8 +1,14 @@
 package slack
 
 type Client interface {
-	SendText(channelID, text string) error
-	SendImage(channelID, fallbackText, url string) error
+	// Send methods return the timestamp of the message they sent.
+	SendText(channelID, text string) (string, error)
+	SendThreadText(channelID, threadTS, text string) (string, error)
+	SendImage(channelID, fallbackText, url string) (string, error)
+	UpdateText(channelID, ts, text string) error
+	Delete(channelID, ts string) error
+	AddReaction(channelID, ts, name string) error
+	RemoveReaction(channelID, ts, name string) error
 
 	AccessToken() string
 }
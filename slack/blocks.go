@@ -0,0 +1,83 @@
+package slack
+
+import "encoding/json"
+
+// Block is a Block Kit layout block. Only the fields the bridge shows are
+// decoded.
+type Block struct {
+	Type string `json:"type"`
+
+	// Set on section and header blocks.
+	Text      *TextObject   `json:"text"`
+	Fields    []*TextObject `json:"fields"`
+	Accessory *BlockElement `json:"accessory"`
+
+	// Set on context, actions and rich_text blocks.
+	Elements []*BlockElement `json:"elements"`
+
+	// Set on image blocks.
+	ImageURL string      `json:"image_url"`
+	AltText  string      `json:"alt_text"`
+	Title    *TextObject `json:"title"`
+}
+
+// BlockElement is an element of a block: text, an image or an interactive
+// element, or in rich_text blocks, a section, list or piece of text.
+type BlockElement struct {
+	Type  string      `json:"type"`
+	Text  *TextObject `json:"text"`
+	Style *BlockStyle `json:"style"`
+	URL   string      `json:"url"`
+
+	// Set on image elements.
+	ImageURL string `json:"image_url"`
+	AltText  string `json:"alt_text"`
+
+	// Set on select menus and other inputs.
+	Placeholder *TextObject `json:"placeholder"`
+
+	// Set on rich text sections, lists, quotes and preformatted text.
+	Elements []*BlockElement `json:"elements"`
+	Indent   int             `json:"indent"`
+	Offset   int             `json:"offset"`
+
+	// Set on rich text mentions and emoji.
+	UserID      string `json:"user_id"`
+	ChannelID   string `json:"channel_id"`
+	UsergroupID string `json:"usergroup_id"`
+	Range       string `json:"range"`
+	Name        string `json:"name"`
+}
+
+// TextObject is a mrkdwn or plain_text composition object. Rich text elements
+// have plain strings as their text, which are decoded into Text.
+type TextObject struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (t *TextObject) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &t.Text)
+	}
+	type textObject TextObject
+	return json.Unmarshal(data, (*textObject)(t))
+}
+
+// BlockStyle is the style of an element: a name like "bullet" or "ordered" for
+// rich text lists or "primary" for buttons, or flags for rich text.
+type BlockStyle struct {
+	Name   string `json:"-"`
+	Bold   bool   `json:"bold"`
+	Italic bool   `json:"italic"`
+	Strike bool   `json:"strike"`
+	Code   bool   `json:"code"`
+}
+
+func (s *BlockStyle) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &s.Name)
+	}
+	type blockStyle BlockStyle
+	return json.Unmarshal(data, (*blockStyle)(s))
+}
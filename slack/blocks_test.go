@@ -0,0 +1,40 @@
+package slack
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeBlocks(t *testing.T) {
+	var m Message
+	if err := json.Unmarshal([]byte(`{
+		"type": "message",
+		"text": "",
+		"blocks": [
+			{"type": "section", "text": {"type": "mrkdwn", "text": "*Deploy* finished"}},
+			{"type": "actions", "elements": [{"type": "button", "style": "primary", "text": {"type": "plain_text", "text": "Roll back"}}]},
+			{"type": "rich_text", "elements": [{"type": "rich_text_list", "style": "ordered", "elements": [
+				{"type": "rich_text_section", "elements": [{"type": "text", "text": "done", "style": {"bold": true}}]}
+			]}]}
+		]
+	}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	want := []*Block{
+		{Type: "section", Text: &TextObject{Type: "mrkdwn", Text: "*Deploy* finished"}},
+		{Type: "actions", Elements: []*BlockElement{
+			{Type: "button", Style: &BlockStyle{Name: "primary"}, Text: &TextObject{Type: "plain_text", Text: "Roll back"}},
+		}},
+		{Type: "rich_text", Elements: []*BlockElement{
+			{Type: "rich_text_list", Style: &BlockStyle{Name: "ordered"}, Elements: []*BlockElement{
+				{Type: "rich_text_section", Elements: []*BlockElement{
+					{Type: "text", Text: &TextObject{Text: "done"}, Style: &BlockStyle{Bold: true}},
+				}},
+			}},
+		}},
+	}
+	if !reflect.DeepEqual(m.Blocks, want) {
+		t.Errorf("want %v got %v", want, m.Blocks)
+	}
+}
@@ -6,7 +6,8 @@ import (
 )
 
 type event struct {
-	Type string
+	Type string `json:"type"`
+	URL  string `json:"url"`
 }
 
 type Hello struct {
@@ -21,7 +22,19 @@ type Message struct {
 	User    string `json:"user"`
 	Text    string `json:"text"`
 
+	// Set on replies in a thread, and on the thread's parent.
+	ThreadTS string `json:"thread_ts"`
+
 	File *File `json:"file"`
+
+	// Apps often post blocks or attachments with little or nothing in Text.
+	Blocks      []*Block      `json:"blocks"`
+	Attachments []*Attachment `json:"attachments"`
+
+	// Set on message_changed and message_deleted events.
+	Message         *Message `json:"message"`
+	PreviousMessage *Message `json:"previous_message"`
+	DeletedTS       string   `json:"deleted_ts"`
 }
 
 func (m *Message) Timestamp() float64 {
@@ -33,6 +46,19 @@ func (m *Message) Timestamp() float64 {
 	return f
 }
 
+type Reaction struct {
+	Type     string       `json:"type"`
+	User     string       `json:"user"`
+	Reaction string       `json:"reaction"`
+	Item     ReactionItem `json:"item"`
+}
+
+type ReactionItem struct {
+	Type    string `json:"type"`
+	Channel string `json:"channel"`
+	TS      string `json:"ts"`
+}
+
 type File struct {
 	MIMEType       string   `json:"mimetype"`
 	URL            string   `json:"url"`
@@ -47,3 +73,24 @@ type Comment struct {
 	Comment string `json:"comment"`
 	User    string `json:"user"`
 }
+
+// Attachment is a legacy message attachment, which integrations still use for
+// alerts and summaries.
+type Attachment struct {
+	Fallback  string             `json:"fallback"`
+	Color     string             `json:"color"`
+	Pretext   string             `json:"pretext"`
+	Title     string             `json:"title"`
+	TitleLink string             `json:"title_link"`
+	Text      string             `json:"text"`
+	Fields    []*AttachmentField `json:"fields"`
+	ImageURL  string             `json:"image_url"`
+	Footer    string             `json:"footer"`
+	Blocks    []*Block           `json:"blocks"`
+}
+
+type AttachmentField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
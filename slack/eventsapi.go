@@ -0,0 +1,168 @@
+package slack
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// Requests signed longer ago than this are rejected as possible replays.
+	maxRequestAge = 5 * time.Minute
+
+	// How many event IDs to remember for deduplicating retried events.
+	seenEventsSize = 1000
+)
+
+// NewEventsHandler returns an http.Handler which receives events pushed by the
+// Slack Events API. Requests are verified against signingSecret, and messages
+// are dispatched to c's handlers, filtered and echo suppressed exactly as if c
+// had received them over a websocket.
+func NewEventsHandler(signingSecret string, c *client) *EventsHandler {
+	return &EventsHandler{
+		signingSecret: signingSecret,
+		client:        c,
+		now:           time.Now,
+		seen:          make(map[string]bool),
+	}
+}
+
+type EventsHandler struct {
+	signingSecret string
+	client        *client
+	now           func() time.Time
+
+	mu          sync.Mutex
+	seen        map[string]bool
+	seenOrder   []string
+	queue       []json.RawMessage
+	dispatching bool
+	pending     sync.WaitGroup
+}
+
+func (h *EventsHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != "POST" {
+		http.Error(w, "Events must be POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, "Error reading body", http.StatusBadRequest)
+		return
+	}
+	if err := h.verify(req.Header, body); err != nil {
+		log.Printf("Rejecting slack event: %v", err)
+		http.Error(w, "Bad signature", http.StatusUnauthorized)
+		return
+	}
+
+	var r eventsAPIRequest
+	if err := json.Unmarshal(body, &r); err != nil {
+		http.Error(w, "Error decoding body", http.StatusBadRequest)
+		return
+	}
+	switch r.Type {
+	case "url_verification":
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, r.Challenge)
+		return
+	case "event_callback":
+	default:
+		log.Printf("Ignoring unknown events API request: %q", string(body))
+		return
+	}
+
+	if !h.markSeen(r.EventID) {
+		log.Printf("Skipping already seen event %q (retry %s)", r.EventID, req.Header.Get("X-Slack-Retry-Num"))
+		return
+	}
+	h.enqueue(r.Event)
+}
+
+// enqueue queues event to be dispatched after the response has been written,
+// as slack retries events which aren't acknowledged within three seconds.
+// Events are dispatched one at a time, in the order they arrived.
+func (h *EventsHandler) enqueue(event json.RawMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pending.Add(1)
+	h.queue = append(h.queue, event)
+	if !h.dispatching {
+		h.dispatching = true
+		go h.dispatchQueue()
+	}
+}
+
+func (h *EventsHandler) dispatchQueue() {
+	for {
+		h.mu.Lock()
+		if len(h.queue) == 0 {
+			h.dispatching = false
+			h.mu.Unlock()
+			return
+		}
+		event := h.queue[0]
+		h.queue = h.queue[1:]
+		h.mu.Unlock()
+		h.client.dispatchEvent(event)
+		h.pending.Done()
+	}
+}
+
+// Wait blocks until every queued event has been dispatched. Call it once the
+// server has stopped accepting requests, so that events which slack was told
+// we'd received aren't lost on shutdown.
+func (h *EventsHandler) Wait() {
+	h.pending.Wait()
+}
+
+func (h *EventsHandler) verify(header http.Header, body []byte) error {
+	ts := header.Get("X-Slack-Request-Timestamp")
+	secs, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("bad timestamp %q", ts)
+	}
+	if age := h.now().Sub(time.Unix(secs, 0)); age > maxRequestAge || age < -maxRequestAge {
+		return fmt.Errorf("timestamp %q too far from now", ts)
+	}
+	mac := hmac.New(sha256.New, []byte(h.signingSecret))
+	mac.Write([]byte("v0:" + ts + ":"))
+	mac.Write(body)
+	want := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(want), []byte(header.Get("X-Slack-Signature"))) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// markSeen records eventID as seen, returning false if it already had been.
+func (h *EventsHandler) markSeen(eventID string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.seen[eventID] {
+		return false
+	}
+	h.seen[eventID] = true
+	h.seenOrder = append(h.seenOrder, eventID)
+	if len(h.seenOrder) > seenEventsSize {
+		delete(h.seen, h.seenOrder[0])
+		h.seenOrder = h.seenOrder[1:]
+	}
+	return true
+}
+
+type eventsAPIRequest struct {
+	Type      string          `json:"type"`
+	Challenge string          `json:"challenge"`
+	EventID   string          `json:"event_id"`
+	Event     json.RawMessage `json:"event"`
+}
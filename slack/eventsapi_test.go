@@ -0,0 +1,206 @@
+package slack
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const messageEvent = `{
+	"type": "event_callback",
+	"event_id": "Ev0123",
+	"event": {
+		"type": "message",
+		"channel": "CANTINA",
+		"user": "nancy",
+		"text": "I'm a... firewoman",
+		"ts": "1.000"
+	}
+}`
+
+func TestEventsHandlerURLVerification(t *testing.T) {
+	h, _ := eventsHandler(t, AlwaysNotify)
+	w := postEvent(h, `{"type": "url_verification", "challenge": "3eZbrw1aBm2rZgRNFdxV2595E9CY3gmdALWMmHkvFXO7tYXAYM8P"}`, time.Now(), "", nil)
+	if w.Code != 200 {
+		t.Fatalf("Status: want %d got %d", 200, w.Code)
+	}
+	if got := w.Body.String(); got != "3eZbrw1aBm2rZgRNFdxV2595E9CY3gmdALWMmHkvFXO7tYXAYM8P" {
+		t.Errorf("Challenge: got %q", got)
+	}
+}
+
+func TestEventsHandlerDispatchesMessage(t *testing.T) {
+	h, messages := eventsHandler(t, AlwaysNotify)
+	if w := postEventAndWait(h, messageEvent, time.Now(), "", nil); w.Code != 200 {
+		t.Fatalf("Status: want %d got %d", 200, w.Code)
+	}
+	want := Message{
+		Type:    "message",
+		Channel: "CANTINA",
+		User:    "nancy",
+		Text:    "I'm a... firewoman",
+		TS:      "1.000",
+	}
+	if len(*messages) != 1 || !reflect.DeepEqual((*messages)[0], want) {
+		t.Errorf("want [%v] got %v", want, *messages)
+	}
+}
+
+func TestEventsHandlerRespondsBeforeDispatching(t *testing.T) {
+	h, _ := eventsHandler(t, AlwaysNotify)
+	unblock := make(chan struct{})
+	h.client.OnMessage(func(m Message) {
+		<-unblock
+	})
+	if w := postEvent(h, messageEvent, time.Now(), "", nil); w.Code != 200 {
+		t.Errorf("Status: want %d got %d", 200, w.Code)
+	}
+	close(unblock)
+	h.Wait()
+}
+
+func TestEventsHandlerDispatchesReaction(t *testing.T) {
+	h, _ := eventsHandler(t, AlwaysNotify)
+	var reactions []Reaction
+	h.client.OnReaction(func(r Reaction) {
+		reactions = append(reactions, r)
+	})
+	postEventAndWait(h, `{
+		"type": "event_callback",
+		"event_id": "Ev0124",
+		"event": {
+			"type": "reaction_removed",
+			"user": "nancy",
+			"reaction": "fire",
+			"item": {"type": "message", "channel": "CANTINA", "ts": "1.000"}
+		}
+	}`, time.Now(), "", nil)
+	want := Reaction{
+		Type:     "reaction_removed",
+		User:     "nancy",
+		Reaction: "fire",
+		Item:     ReactionItem{Type: "message", Channel: "CANTINA", TS: "1.000"},
+	}
+	if len(reactions) != 1 || reactions[0] != want {
+		t.Errorf("want [%v] got %v", want, reactions)
+	}
+}
+
+func TestEventsHandlerDedupesRetries(t *testing.T) {
+	h, messages := eventsHandler(t, AlwaysNotify)
+	postEventAndWait(h, messageEvent, time.Now(), "", nil)
+	postEventAndWait(h, messageEvent, time.Now(), "", map[string]string{"X-Slack-Retry-Num": "1", "X-Slack-Retry-Reason": "http_timeout"})
+	if len(*messages) != 1 {
+		t.Errorf("want 1 message got %d", len(*messages))
+	}
+}
+
+func TestEventsHandlerFiltersAndSuppressesEchoes(t *testing.T) {
+	h, messages := eventsHandler(t, func(m *Message) bool { return false })
+	postEventAndWait(h, messageEvent, time.Now(), "", nil)
+	if len(*messages) != 0 {
+		t.Errorf("want filtered message skipped, got %v", *messages)
+	}
+
+	h, messages = eventsHandler(t, AlwaysNotify)
+	h.client.echoSuppresser.Sent("1.000")
+	postEventAndWait(h, messageEvent, time.Now(), "", nil)
+	if len(*messages) != 0 {
+		t.Errorf("want echo skipped, got %v", *messages)
+	}
+}
+
+func TestEventsHandlerSuppressesBotClientEchoes(t *testing.T) {
+	h, messages := eventsHandler(t, AlwaysNotify)
+	bot := NewBotClient(h.client, "@vivian:matrix.org", "Vivian", "", http.Client{
+		Transport: &roundTripper{
+			t:        t,
+			response: `{"ok": true, "ts": "2.000"}`,
+			filter: func(req *http.Request) bool {
+				return req.URL.Path == "/api/chat.postMessage"
+			},
+		},
+	}, AlwaysNotify)
+	if _, err := bot.SendText("CANTINA", "I'm Vivian"); err != nil {
+		t.Fatal(err)
+	}
+	postEventAndWait(h, `{
+		"type": "event_callback",
+		"event_id": "Ev0125",
+		"event": {
+			"type": "message",
+			"subtype": "bot_message",
+			"channel": "CANTINA",
+			"bot_id": "B01",
+			"username": "Vivian",
+			"text": "I'm Vivian",
+			"ts": "2.000"
+		}
+	}`, time.Now(), "", nil)
+	if len(*messages) != 0 {
+		t.Errorf("want bot client's message suppressed, got %v", *messages)
+	}
+}
+
+func TestEventsHandlerRejectsBadSignature(t *testing.T) {
+	h, messages := eventsHandler(t, AlwaysNotify)
+	if w := postEventAndWait(h, messageEvent, time.Now(), "v0=0123456789abcdef", nil); w.Code != 401 {
+		t.Errorf("Status: want %d got %d", 401, w.Code)
+	}
+	if len(*messages) != 0 {
+		t.Errorf("want no messages got %v", *messages)
+	}
+}
+
+func TestEventsHandlerRejectsReplays(t *testing.T) {
+	h, messages := eventsHandler(t, AlwaysNotify)
+	if w := postEventAndWait(h, messageEvent, time.Now().Add(-10*time.Minute), "", nil); w.Code != 401 {
+		t.Errorf("Status: want %d got %d", 401, w.Code)
+	}
+	if len(*messages) != 0 {
+		t.Errorf("want no messages got %v", *messages)
+	}
+}
+
+func eventsHandler(t *testing.T, filter MessageFilter) (*EventsHandler, *[]Message) {
+	client := NewClient("cynicism", http.Client{}, filter)
+	var messages []Message
+	client.OnMessage(func(m Message) {
+		messages = append(messages, m)
+	})
+	return NewEventsHandler("8f742231b10e8888abcd99yyyzzz85a5", client), &messages
+}
+
+// postEvent sends body to h, signed at ts, unless signature is given.
+func postEvent(h http.Handler, body string, ts time.Time, signature string, headers map[string]string) *httptest.ResponseRecorder {
+	timestamp := strconv.FormatInt(ts.Unix(), 10)
+	if signature == "" {
+		mac := hmac.New(sha256.New, []byte("8f742231b10e8888abcd99yyyzzz85a5"))
+		mac.Write([]byte("v0:" + timestamp + ":" + body))
+		signature = "v0=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	req := httptest.NewRequest("POST", "/slack/events", strings.NewReader(body))
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", signature)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+// postEventAndWait sends body to h like postEvent, then waits for it to be
+// dispatched.
+func postEventAndWait(h *EventsHandler, body string, ts time.Time, signature string, headers map[string]string) *httptest.ResponseRecorder {
+	w := postEvent(h, body, ts, signature, headers)
+	h.Wait()
+	return w
+}
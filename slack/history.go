@@ -0,0 +1,81 @@
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const historyPageSize = 100
+
+// History returns the messages posted to channel since the oldest timestamp,
+// in the order they were sent. If there are more than limit messages, only the
+// most recent limit are returned. A limit of 0 means no limit.
+func History(c http.Client, token, channel, oldest string, limit int) ([]Message, error) {
+	var messages []Message
+	var cursor string
+	for {
+		v := url.Values{}
+		v.Set("token", token)
+		v.Set("channel", channel)
+		v.Set("oldest", oldest)
+		v.Set("limit", strconv.Itoa(historyPageSize))
+		if cursor != "" {
+			v.Set("cursor", cursor)
+		}
+		resp, err := c.PostForm("https://slack.com/api/conversations.history", v)
+		if err != nil {
+			return nil, fmt.Errorf("error from slack: %v", err)
+		}
+		b, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading response from slack: %v", err)
+		}
+		if resp.StatusCode != 200 {
+			return nil, fmt.Errorf("error from slack: %d: %s", resp.StatusCode, string(b))
+		}
+		var hr historyResponse
+		if err := json.Unmarshal(b, &hr); err != nil {
+			return nil, fmt.Errorf("error decoding JSON from slack: %v (%s)", err, string(b))
+		}
+		if !hr.OK {
+			return nil, fmt.Errorf("error from slack: %s", string(b))
+		}
+
+		// Pages go backwards in time, newest first.
+		messages = append(messages, hr.Messages...)
+		if limit > 0 && len(messages) >= limit {
+			if hr.HasMore || len(messages) > limit {
+				log.Printf("Only catching up on the last %d messages in %q", limit, channel)
+			}
+			messages = messages[:limit]
+			break
+		}
+		cursor = hr.ResponseMetadata.NextCursor
+		if !hr.HasMore || cursor == "" {
+			break
+		}
+	}
+
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	for i := range messages {
+		messages[i].Channel = channel
+	}
+	return messages, nil
+}
+
+type historyResponse struct {
+	OK               bool      `json:"ok"`
+	Messages         []Message `json:"messages"`
+	HasMore          bool      `json:"has_more"`
+	ResponseMetadata struct {
+		NextCursor string `json:"next_cursor"`
+	} `json:"response_metadata"`
+}
@@ -0,0 +1,76 @@
+package slack
+
+import (
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHistoryPaginatesOldestFirst(t *testing.T) {
+	client := historyClient(t)
+	messages, err := History(client, "cynicism", "CANTINA", "1.000", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Message{
+		{Type: "message", Channel: "CANTINA", TS: "2.000", User: "U1", Text: "two"},
+		{Type: "message", Channel: "CANTINA", TS: "3.000", User: "U1", Text: "three"},
+		{Type: "message", Channel: "CANTINA", TS: "4.000", User: "U2", Text: "four"},
+	}
+	if !reflect.DeepEqual(messages, want) {
+		t.Errorf("want %v got %v", want, messages)
+	}
+}
+
+func TestHistoryRespectsLimit(t *testing.T) {
+	client := historyClient(t)
+	messages, err := History(client, "cynicism", "CANTINA", "1.000", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Message{
+		{Type: "message", Channel: "CANTINA", TS: "3.000", User: "U1", Text: "three"},
+		{Type: "message", Channel: "CANTINA", TS: "4.000", User: "U2", Text: "four"},
+	}
+	if !reflect.DeepEqual(messages, want) {
+		t.Errorf("want %v got %v", want, messages)
+	}
+}
+
+// historyClient serves two pages of history, newest first, as Slack does.
+func historyClient(t *testing.T) http.Client {
+	return http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.String() != "https://slack.com/api/conversations.history" {
+				t.Errorf("Wrong URL: %q", req.URL.String())
+			}
+			if err := req.ParseForm(); err != nil {
+				t.Fatalf("Error parsing form: %v", err)
+			}
+			if req.Form.Get("token") != "cynicism" || req.Form.Get("channel") != "CANTINA" || req.Form.Get("oldest") != "1.000" {
+				t.Errorf("Got incorrect request: %v", req.Form)
+			}
+			resp := `{"ok": true, "has_more": true, "response_metadata": {"next_cursor": "page2"}, "messages": [
+				{"type": "message", "ts": "4.000", "user": "U2", "text": "four"},
+				{"type": "message", "ts": "3.000", "user": "U1", "text": "three"}
+			]}`
+			if req.Form.Get("cursor") == "page2" {
+				resp = `{"ok": true, "has_more": false, "messages": [
+					{"type": "message", "ts": "2.000", "user": "U1", "text": "two"}
+				]}`
+			}
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(strings.NewReader(resp)),
+			}, nil
+		}),
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
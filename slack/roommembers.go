@@ -28,3 +28,25 @@ func (m *RoomMembers) Add(channel string, user *User) {
 	defer m.mu.Unlock()
 	m.Members[channel] = append(m.Members[channel], user)
 }
+
+// RemoveChannel forgets every member of channel.
+func (m *RoomMembers) RemoveChannel(channel string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.Members, channel)
+}
+
+// Remove removes user from every channel.
+func (m *RoomMembers) Remove(user *User) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for channel, users := range m.Members {
+		remaining := make([]*User, 0, len(users))
+		for _, u := range users {
+			if u != user {
+				remaining = append(remaining, u)
+			}
+		}
+		m.Members[channel] = remaining
+	}
+}
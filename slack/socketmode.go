@@ -0,0 +1,108 @@
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+
+	"github.com/matrix-org/slackbridge/common"
+
+	"golang.org/x/net/websocket"
+)
+
+// NewSocketModeClient returns a client which receives events over Socket Mode
+// rather than RTM, which is not available to new Slack apps. appToken is the
+// app-level token used to open connections; token is the bot token used for
+// everything else.
+func NewSocketModeClient(token, appToken string, c http.Client, messageFilter MessageFilter) *client {
+	return &client{
+		token:          token,
+		appToken:       appToken,
+		client:         c,
+		messageFilter:  messageFilter,
+		asUser:         "",
+		echoSuppresser: common.NewEchoSuppresser(),
+		pingInterval:   pingInterval,
+		minBackoff:     minBackoff,
+	}
+}
+
+func (c *client) socketModeURL() (string, error) {
+	req, err := http.NewRequest("POST", "https://slack.com/api/apps.connections.open", nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating http request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.appToken)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error opening connection: %v", err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading connection details: %v", err)
+	}
+	var r connectionsOpenResponse
+	if err := json.Unmarshal(b, &r); err != nil {
+		return "", fmt.Errorf("error unmarshaling response: %v", err)
+	}
+	if !r.OK {
+		return "", fmt.Errorf("bad response from slack opening connection: %s", string(b))
+	}
+	return r.URL, nil
+}
+
+// handleEnvelope acknowledges and dispatches a single Socket Mode frame. It
+// returns whether the connection should be re-established.
+func (c *client) handleEnvelope(ws *websocket.Conn, b []byte) bool {
+	var e envelope
+	if err := json.Unmarshal(b, &e); err != nil {
+		log.Printf("Error unmarshaling envelope: %v", err)
+		return false
+	}
+	if e.EnvelopeID != "" {
+		if err := websocket.JSON.Send(ws, ack{e.EnvelopeID}); err != nil {
+			log.Printf("Error acknowledging envelope %q: %v", e.EnvelopeID, err)
+		}
+	}
+	switch e.Type {
+	case "hello":
+		return c.handleEvent(b)
+	case "disconnect":
+		log.Printf("Slack asked us to reconnect: %q", e.Reason)
+		return true
+	case "events_api":
+		var p eventsAPIPayload
+		if err := json.Unmarshal(e.Payload, &p); err != nil {
+			log.Printf("Error unmarshaling events API payload: %v", err)
+			return false
+		}
+		c.dispatchEvent(p.Event)
+	default:
+		log.Printf("Ignoring unknown envelope: %q", string(b))
+	}
+	return false
+}
+
+type connectionsOpenResponse struct {
+	OK  bool   `json:"ok"`
+	URL string `json:"url"`
+}
+
+type envelope struct {
+	EnvelopeID string          `json:"envelope_id"`
+	Type       string          `json:"type"`
+	Reason     string          `json:"reason"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+type eventsAPIPayload struct {
+	EventID string          `json:"event_id"`
+	Event   json.RawMessage `json:"event"`
+}
+
+type ack struct {
+	EnvelopeID string `json:"envelope_id"`
+}
@@ -0,0 +1,161 @@
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestSocketModeReceivesAndAcknowledgesMessage(t *testing.T) {
+	acks := make(chan string, 1)
+	s := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		websocket.Message.Send(ws, `{"type": "hello", "num_connections": 1}`)
+		websocket.Message.Send(ws, `{
+			"envelope_id": "57d6a792-4d35-4d0b-b6aa-3361493e1caf",
+			"type": "events_api",
+			"accepts_response_payload": false,
+			"payload": {
+				"type": "event_callback",
+				"event_id": "Ev0123",
+				"event": {
+					"type": "message",
+					"channel": "CANTINA",
+					"user": "nancy",
+					"text": "I'm a... firewoman",
+					"ts": "1.000"
+				}
+			}
+		}`)
+		var a ack
+		if err := websocket.JSON.Receive(ws, &a); err != nil {
+			t.Errorf("Error receiving ack: %v", err)
+			return
+		}
+		acks <- a.EnvelopeID
+		var b []byte
+		websocket.Message.Receive(ws, &b)
+	}))
+	defer s.Close()
+
+	b, err := json.Marshal(connectionsOpenResponse{
+		OK:  true,
+		URL: strings.Replace(s.URL, "http://", "ws://", 1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewSocketModeClient("xoxb-bot", "xapp-app", http.Client{
+		Transport: &roundTripper{
+			t:        t,
+			response: string(b),
+			filter: func(req *http.Request) bool {
+				return req.URL.String() == "https://slack.com/api/apps.connections.open" &&
+					req.Header.Get("Authorization") == "Bearer xapp-app"
+			},
+		},
+	}, AlwaysNotify)
+
+	want := Message{
+		Type:    "message",
+		Channel: "CANTINA",
+		User:    "nancy",
+		Text:    "I'm a... firewoman",
+		TS:      "1.000",
+	}
+	called := make(chan Message, 1)
+	client.OnMessage(func(m Message) {
+		called <- m
+	})
+
+	cancel := make(chan struct{})
+	defer close(cancel)
+	go client.Listen(cancel)
+
+	select {
+	case got := <-called:
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("want %v got %v", want, got)
+		}
+	case _ = <-time.After(500 * time.Millisecond):
+		t.Fatalf("Timed out waiting for message")
+	}
+	select {
+	case got := <-acks:
+		if want := "57d6a792-4d35-4d0b-b6aa-3361493e1caf"; got != want {
+			t.Errorf("Ack: want %q got %q", want, got)
+		}
+	case _ = <-time.After(500 * time.Millisecond):
+		t.Fatalf("Timed out waiting for ack")
+	}
+}
+
+func TestSocketModeClientDoesNotSendAsUser(t *testing.T) {
+	var methods []string
+	client := NewSocketModeClient("xoxb-bot", "xapp-app", http.Client{
+		Transport: &roundTripper{
+			t:        t,
+			response: `{"ok": true, "ts": "1.000"}`,
+			filter: func(req *http.Request) bool {
+				if err := req.ParseForm(); err != nil {
+					return false
+				}
+				methods = append(methods, strings.TrimPrefix(req.URL.Path, "/api/"))
+				_, ok := req.Form["as_user"]
+				return req.Form.Get("token") == "xoxb-bot" && !ok
+			},
+		},
+	}, AlwaysNotify)
+
+	if _, err := client.SendText("CANTINA", "I'm a... firewoman"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.UpdateText("CANTINA", "1.000", "I'm a firewoman"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Delete("CANTINA", "1.000"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"chat.postMessage", "chat.update", "chat.delete"}; !reflect.DeepEqual(methods, want) {
+		t.Errorf("want %v got %v", want, methods)
+	}
+}
+
+func TestSocketModeSuppressesBotClientEchoes(t *testing.T) {
+	httpClient := http.Client{
+		Transport: &roundTripper{
+			t:        t,
+			response: `{"ok": true, "ts": "2.000"}`,
+			filter: func(req *http.Request) bool {
+				return req.URL.Path == "/api/chat.postMessage"
+			},
+		},
+	}
+	client := NewSocketModeClient("xoxb-bot", "xapp-app", httpClient, AlwaysNotify)
+	var messages []Message
+	client.OnMessage(func(m Message) {
+		messages = append(messages, m)
+	})
+
+	bot := NewBotClient(client, "@vivian:matrix.org", "Vivian", "", httpClient, AlwaysNotify)
+	if _, err := bot.SendText("CANTINA", "I'm Vivian"); err != nil {
+		t.Fatal(err)
+	}
+	client.dispatchEvent([]byte(`{
+		"type": "message",
+		"subtype": "bot_message",
+		"channel": "CANTINA",
+		"bot_id": "B01",
+		"username": "Vivian",
+		"text": "I'm Vivian",
+		"ts": "2.000"
+	}`))
+	if len(messages) != 0 {
+		t.Errorf("want bot client's message suppressed, got %v", messages)
+	}
+}
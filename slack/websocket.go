@@ -7,14 +7,45 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/matrix-org/slackbridge/common"
 
 	"golang.org/x/net/websocket"
 )
 
-const bufSize = 16 * 1024
+const (
+	// How often to ping slack when idle. If nothing is heard for two
+	// intervals, the connection is assumed dead.
+	pingInterval = 30 * time.Second
+
+	// Bounds on how long to wait between reconnection attempts.
+	minBackoff = 1 * time.Second
+	maxBackoff = 2 * time.Minute
+)
+
+type ConnectionState int
+
+const (
+	Disconnected ConnectionState = iota
+	Connecting
+	Connected
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case Disconnected:
+		return "disconnected"
+	case Connecting:
+		return "connecting"
+	case Connected:
+		return "connected"
+	}
+	return fmt.Sprintf("ConnectionState(%d)", int(s))
+}
 
 type MessageFilter func(*Message) bool
 
@@ -29,80 +60,245 @@ func NewClient(token string, c http.Client, messageFilter MessageFilter) *client
 		messageFilter:  messageFilter,
 		asUser:         "",
 		echoSuppresser: common.NewEchoSuppresser(),
+		pingInterval:   pingInterval,
+		minBackoff:     minBackoff,
 	}
 }
 
-func NewBotClient(token, asUser, displayName, avatarURL string, c http.Client, messageFilter MessageFilter) *client {
+// NewBotClient returns a client which posts with parent's token as asUser,
+// who isn't linked to a slack user. The messages it posts come back to
+// parent's listener as the bot's, so they share echo suppression.
+func NewBotClient(parent Client, asUser, displayName, avatarURL string, c http.Client, messageFilter MessageFilter) *client {
+	echoSuppresser := common.NewEchoSuppresser()
+	if p, ok := parent.(*client); ok {
+		echoSuppresser = p.echoSuppresser
+	}
 	return &client{
-		token:          token,
+		token:          parent.AccessToken(),
 		client:         c,
 		messageFilter:  messageFilter,
 		asUser:         asUser,
 		displayName:    displayName,
 		avatarURL:      avatarURL,
-		echoSuppresser: common.NewEchoSuppresser(),
+		echoSuppresser: echoSuppresser,
+		pingInterval:   pingInterval,
+		minBackoff:     minBackoff,
 	}
 }
 
 func (c *client) Listen(cancel chan struct{}) error {
-	if c.ws != nil {
+	c.mu.Lock()
+	if c.listening {
+		c.mu.Unlock()
 		return fmt.Errorf("already listening")
 	}
+	c.listening = true
+	c.mu.Unlock()
 
-	url, err := c.websocketURL()
-	if err != nil {
-		return err
+	backoff := c.minBackoff
+	for {
+		c.setState(Connecting)
+		ws, err := c.dial()
+		if err == nil {
+			backoff = c.minBackoff
+			cancelled := c.serve(ws, cancel)
+			ws.Close()
+			c.setState(Disconnected)
+			if cancelled {
+				return nil
+			}
+			continue
+		}
+		log.Printf("Error connecting to slack, retrying in %v: %v", backoff, err)
+		c.setState(Disconnected)
+		select {
+		case <-time.After(backoff):
+		case <-cancel:
+			return nil
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
 	}
-	c.ws, err = websocket.Dial(url, "", "http://localhost")
+}
+
+func (c *client) dial() (*websocket.Conn, error) {
+	url := c.reconnectURL
+	c.reconnectURL = ""
+	if url == "" {
+		var err error
+		if c.appToken == "" {
+			url, err = c.websocketURL()
+		} else {
+			url, err = c.socketModeURL()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	ws, err := websocket.Dial(url, "", "http://localhost")
 	if err != nil {
-		return fmt.Errorf("error dialing: %v", err)
+		return nil, fmt.Errorf("error dialing: %v", err)
 	}
+	return ws, nil
+}
+
+// serve handles events from ws until the connection fails, slack asks us to
+// reconnect, or cancel is signalled. It returns whether it was cancelled.
+func (c *client) serve(ws *websocket.Conn, cancel chan struct{}) bool {
+	frames := make(chan []byte)
+	errs := make(chan error, 1)
+	stop := make(chan struct{})
+	defer close(stop)
+	go c.read(ws, frames, errs, stop)
 
-	ch := make(chan []byte)
+	// Socket Mode connections are kept alive by slack sending websocket
+	// pings, which the websocket library answers for us, so we only need to
+	// ping over RTM.
+	var tick <-chan time.Time
+	if c.appToken == "" {
+		ticker := time.NewTicker(c.pingInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	lastSeen := time.Now()
+	var pingID int
 	for {
-		go c.read(ch)
 		select {
-		case b := <-ch:
-			var e event
-			if err := json.Unmarshal(b, &e); err != nil {
-				log.Printf("Error unmarshaling websocket type: %v", err)
+		case b := <-frames:
+			lastSeen = time.Now()
+			var reconnect bool
+			if c.appToken == "" {
+				reconnect = c.handleEvent(b)
+			} else {
+				reconnect = c.handleEnvelope(ws, b)
+			}
+			if reconnect {
+				return false
+			}
+		case err := <-errs:
+			log.Printf("Error reading from websocket: %v", err)
+			return false
+		case <-tick:
+			if time.Since(lastSeen) > 2*c.pingInterval {
+				log.Printf("No response from slack in %v, reconnecting", time.Since(lastSeen))
+				return false
 			}
-			switch e.Type {
-			case "hello":
-				var h Hello
-				if err := json.Unmarshal(b, &h); err != nil {
-					log.Printf("Error unmarshaling websocket response: %v", err)
-				}
-				if len(c.helloHandlers) == 0 {
-					log.Printf("No listeners for hello events")
-				}
-				for _, c := range c.helloHandlers {
-					c(h)
-				}
-			case "message":
-				var m Message
-				if err := json.Unmarshal(b, &m); err != nil {
-					log.Printf("Error unmarshaling websocket response: %v", err)
-				}
-				c.echoSuppresser.Wait()
-				if !c.messageFilter(&m) || c.echoSuppresser.WasSent(m.TS) {
-					log.Printf("Skipping filtered message: %v", m)
-					continue
-				}
-				if len(c.messageHandlers) == 0 {
-					log.Printf("No listeners for message events")
-				}
-				for _, c := range c.messageHandlers {
-					c(m)
-				}
-			default:
-				log.Printf("Ignoring unknown event: %q", string(b))
+			pingID++
+			if err := websocket.JSON.Send(ws, ping{ID: pingID, Type: "ping"}); err != nil {
+				log.Printf("Error sending ping: %v", err)
+				return false
 			}
-		case _ = <-cancel:
-			return c.ws.Close()
+		case <-cancel:
+			return true
 		}
 	}
-	return nil
+}
+
+// handleEvent dispatches a single websocket frame. It returns whether the
+// connection should be re-established.
+func (c *client) handleEvent(b []byte) bool {
+	var e event
+	if err := json.Unmarshal(b, &e); err != nil {
+		log.Printf("Error unmarshaling websocket type: %v", err)
+		return false
+	}
+	switch e.Type {
+	case "hello":
+		var h Hello
+		if err := json.Unmarshal(b, &h); err != nil {
+			log.Printf("Error unmarshaling websocket response: %v", err)
+		}
+		c.setState(Connected)
+		if len(c.helloHandlers) == 0 {
+			log.Printf("No listeners for hello events")
+		}
+		for _, c := range c.helloHandlers {
+			c(h)
+		}
+	case "message":
+		var m Message
+		if err := json.Unmarshal(b, &m); err != nil {
+			log.Printf("Error unmarshaling websocket response: %v", err)
+		}
+		c.dispatchMessage(m)
+	case "reaction_added", "reaction_removed":
+		var r Reaction
+		if err := json.Unmarshal(b, &r); err != nil {
+			log.Printf("Error unmarshaling websocket response: %v", err)
+		}
+		c.dispatchReaction(r)
+	case "pong":
+	case "reconnect_url":
+		c.reconnectURL = e.URL
+	case "goodbye":
+		log.Printf("Slack said goodbye, reconnecting")
+		return true
+	default:
+		log.Printf("Ignoring unknown event: %q", string(b))
+	}
+	return false
+}
+
+func (c *client) dispatchMessage(m Message) {
+	c.echoSuppresser.Wait()
+	if !c.messageFilter(&m) || c.isEcho(&m) {
+		log.Printf("Skipping filtered message: %v", m)
+		return
+	}
+	if len(c.messageHandlers) == 0 {
+		log.Printf("No listeners for message events")
+	}
+	for _, c := range c.messageHandlers {
+		c(m)
+	}
+}
+
+func (c *client) dispatchReaction(r Reaction) {
+	c.echoSuppresser.Wait()
+	if r.Item.Type != "message" {
+		log.Printf("Skipping reaction to %s", r.Item.Type)
+		return
+	}
+	if c.echoSuppresser.WasSent(reactionKey(r.Type, r.Item.TS, r.Reaction)) {
+		log.Printf("Skipping filtered reaction: %v", r)
+		return
+	}
+	if len(c.reactionHandlers) == 0 {
+		log.Printf("No listeners for reaction events")
+	}
+	for _, c := range c.reactionHandlers {
+		c(r)
+	}
+}
+
+// dispatchEvent dispatches the inner event of an Events API callback, however
+// it was delivered.
+func (c *client) dispatchEvent(raw []byte) {
+	var e event
+	if err := json.Unmarshal(raw, &e); err != nil {
+		log.Printf("Error unmarshaling event type: %v", err)
+		return
+	}
+	switch e.Type {
+	case "message":
+		var m Message
+		if err := json.Unmarshal(raw, &m); err != nil {
+			log.Printf("Error unmarshaling message: %v", err)
+			return
+		}
+		c.dispatchMessage(m)
+	case "reaction_added", "reaction_removed":
+		var r Reaction
+		if err := json.Unmarshal(raw, &r); err != nil {
+			log.Printf("Error unmarshaling reaction: %v", err)
+			return
+		}
+		c.dispatchReaction(r)
+	default:
+		log.Printf("Ignoring unknown event: %q", string(raw))
+	}
 }
 
 func (c *client) OnHello(h func(Hello)) {
@@ -117,16 +313,53 @@ func (c *client) OnMessage(h func(Message)) {
 	c.messageHandlers = append(c.messageHandlers, h)
 }
 
+func (c *client) OnReaction(h func(Reaction)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reactionHandlers = append(c.reactionHandlers, h)
+}
+
+// OnConnectionState registers a handler which is called whenever the
+// connection to slack changes state. Handlers are called before any events
+// received on a new connection are dispatched.
+func (c *client) OnConnectionState(h func(ConnectionState)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stateHandlers = append(c.stateHandlers, h)
+}
+
+func (c *client) setState(s ConnectionState) {
+	c.mu.Lock()
+	if c.state == s {
+		c.mu.Unlock()
+		return
+	}
+	c.state = s
+	handlers := c.stateHandlers
+	c.mu.Unlock()
+	for _, h := range handlers {
+		h(s)
+	}
+}
+
 // Technically you can use the websocket to send pure text-only messages, but
 // you can't send richer messages like attachments through the websocket, so
 // we will instead consistently use the HTTP API.
-func (c *client) SendText(channelID, text string) error {
+func (c *client) SendText(channelID, text string) (string, error) {
 	v := url.Values{}
 	v.Set("text", text)
 	return c.sendMessage(channelID, v)
 }
 
-func (c *client) SendImage(channelID, fallbackText, imageURL string) error {
+// SendThreadText replies to the message threadTS in a thread.
+func (c *client) SendThreadText(channelID, threadTS, text string) (string, error) {
+	v := url.Values{}
+	v.Set("text", text)
+	v.Set("thread_ts", threadTS)
+	return c.sendMessage(channelID, v)
+}
+
+func (c *client) SendImage(channelID, fallbackText, imageURL string) (string, error) {
 	v := url.Values{}
 	attachments, err := json.Marshal([]map[string]string{
 		map[string]string{
@@ -135,19 +368,18 @@ func (c *client) SendImage(channelID, fallbackText, imageURL string) error {
 		},
 	})
 	if err != nil {
-		return fmt.Errorf("error json encoding attachments: %v", err)
+		return "", fmt.Errorf("error json encoding attachments: %v", err)
 	}
 	v.Set("attachments", string(attachments))
 	return c.sendMessage(channelID, v)
 }
 
-func (c *client) sendMessage(channelID string, v url.Values) error {
-	v.Set("token", c.token)
+func (c *client) sendMessage(channelID string, v url.Values) (string, error) {
 	v.Set("channel", channelID)
-	if c.asUser == "" {
-		v.Set("as_user", "true")
-	} else {
-		v.Set("as_user", "false")
+	if !c.isBotToken() {
+		v.Set("as_user", strconv.FormatBool(c.asUser == ""))
+	}
+	if c.asUser != "" {
 		if c.displayName == "" {
 			v.Set("username", c.asUser)
 		} else {
@@ -159,45 +391,158 @@ func (c *client) sendMessage(channelID string, v url.Values) error {
 	}
 	c.echoSuppresser.StartSending()
 	defer c.echoSuppresser.DoneSending()
-	resp, err := c.client.PostForm("https://slack.com/api/chat.postMessage", v)
+	sr, err := c.call("chat.postMessage", v)
+	if err != nil {
+		return "", err
+	}
+	c.echoSuppresser.Sent(sr.TS)
+
+	return sr.TS, nil
+}
+
+// UpdateText replaces the text of the message ts, which must have been sent
+// by this client's user.
+func (c *client) UpdateText(channelID, ts, text string) error {
+	v := url.Values{}
+	v.Set("channel", channelID)
+	v.Set("ts", ts)
+	v.Set("text", text)
+	if c.asUser == "" && !c.isBotToken() {
+		v.Set("as_user", "true")
+	}
+	c.echoSuppresser.StartSending()
+	defer c.echoSuppresser.DoneSending()
+	sr, err := c.call("chat.update", v)
+	if err != nil {
+		return err
+	}
+	c.echoSuppresser.Sent(editKey(sr.TS, sr.Text))
+	return nil
+}
+
+// Delete deletes the message ts, which must have been sent by this client's
+// user unless it is an admin.
+func (c *client) Delete(channelID, ts string) error {
+	v := url.Values{}
+	v.Set("channel", channelID)
+	v.Set("ts", ts)
+	if c.asUser == "" && !c.isBotToken() {
+		v.Set("as_user", "true")
+	}
+	c.echoSuppresser.StartSending()
+	defer c.echoSuppresser.DoneSending()
+	if _, err := c.call("chat.delete", v); err != nil {
+		return err
+	}
+	c.echoSuppresser.Sent(deleteKey(ts))
+	return nil
+}
+
+// AddReaction reacts to the message ts with the emoji called name, as this
+// client's user.
+func (c *client) AddReaction(channelID, ts, name string) error {
+	return c.react("reactions.add", "reaction_added", channelID, ts, name)
+}
+
+// RemoveReaction removes a reaction which AddReaction added.
+func (c *client) RemoveReaction(channelID, ts, name string) error {
+	return c.react("reactions.remove", "reaction_removed", channelID, ts, name)
+}
+
+func (c *client) react(method, eventType, channelID, ts, name string) error {
+	v := url.Values{}
+	v.Set("channel", channelID)
+	v.Set("timestamp", ts)
+	v.Set("name", name)
+	c.echoSuppresser.StartSending()
+	defer c.echoSuppresser.DoneSending()
+	if _, err := c.call(method, v); err != nil {
+		return err
+	}
+	c.echoSuppresser.Sent(reactionKey(eventType, ts, name))
+	return nil
+}
+
+// editKey, deleteKey and reactionKey identify edits, deletes and reactions for
+// echo suppression; the events which they cause have timestamps of their own
+// which we never see.
+func editKey(ts, text string) string {
+	return "edit:" + ts + ":" + text
+}
+
+func deleteKey(ts string) string {
+	return "delete:" + ts
+}
+
+func reactionKey(eventType, ts, name string) string {
+	return eventType + ":" + ts + ":" + name
+}
+
+// isEcho returns whether m was caused by this client.
+func (c *client) isEcho(m *Message) bool {
+	switch {
+	case m.Subtype == "message_changed" && m.Message != nil:
+		return c.echoSuppresser.WasSent(editKey(m.Message.TS, m.Message.Text))
+	case m.Subtype == "message_deleted":
+		return c.echoSuppresser.WasSent(deleteKey(m.DeletedTS))
+	}
+	return c.echoSuppresser.WasSent(m.TS)
+}
+
+// call calls a slack Web API method, returning an error if it wasn't ok.
+func (c *client) call(method string, v url.Values) (*slackResponse, error) {
+	v.Set("token", c.token)
+	resp, err := c.client.PostForm("https://slack.com/api/"+method, v)
 	if err != nil {
-		return fmt.Errorf("error from slack: %v", err)
+		return nil, fmt.Errorf("error from slack: %v", err)
 	}
 	defer resp.Body.Close()
 	b, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("error reading response from slack: %v", err)
+		return nil, fmt.Errorf("error reading response from slack: %v", err)
 	}
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("error from slack: %d: %s", resp.StatusCode, string(b))
+		return nil, fmt.Errorf("error from slack: %d: %s", resp.StatusCode, string(b))
 	}
 	var sr slackResponse
 	if err := json.Unmarshal(b, &sr); err != nil {
-		return fmt.Errorf("error decoding JSON from slack: %v (%v)", err, b)
+		return nil, fmt.Errorf("error decoding JSON from slack: %v (%v)", err, b)
 	}
 	if !sr.OK {
-		return fmt.Errorf("error from slack: %s", string(b))
+		return nil, fmt.Errorf("error from slack: %s", string(b))
 	}
-	c.echoSuppresser.Sent(sr.TS)
-
-	return nil
+	return &sr, nil
 }
 
 func (c *client) AccessToken() string {
 	return c.token
 }
 
+// isBotToken says whether the client has a bot token, which always acts as its
+// bot and doesn't support the legacy as_user parameter.
+func (c *client) isBotToken() bool {
+	return strings.HasPrefix(c.token, "xoxb-")
+}
+
 type client struct {
 	token       string
+	appToken    string
 	asUser      string
 	displayName string
 	avatarURL   string
 	client      http.Client
-	ws          *websocket.Conn
 
-	mu              sync.Mutex
-	helloHandlers   []func(Hello)
-	messageHandlers []func(Message)
+	pingInterval time.Duration
+	minBackoff   time.Duration
+	reconnectURL string
+
+	mu               sync.Mutex
+	listening        bool
+	state            ConnectionState
+	helloHandlers    []func(Hello)
+	messageHandlers  []func(Message)
+	reactionHandlers []func(Reaction)
+	stateHandlers    []func(ConnectionState)
 
 	messageFilter  MessageFilter
 	echoSuppresser *common.EchoSuppresser
@@ -224,13 +569,20 @@ func (c *client) websocketURL() (string, error) {
 	return r.URL, nil
 }
 
-func (c *client) read(ch chan []byte) {
-	b := make([]byte, bufSize)
-	n, err := c.ws.Read(b)
-	if err != nil {
-		log.Printf("Error reading from websocket: %v", err)
+// read reads whole frames from ws until it fails or stop is closed.
+func (c *client) read(ws *websocket.Conn, frames chan []byte, errs chan error, stop chan struct{}) {
+	for {
+		var b []byte
+		if err := websocket.Message.Receive(ws, &b); err != nil {
+			errs <- err
+			return
+		}
+		select {
+		case frames <- b:
+		case <-stop:
+			return
+		}
 	}
-	ch <- b[0:n]
 }
 
 type rtmStartResponse struct {
@@ -238,7 +590,13 @@ type rtmStartResponse struct {
 	URL string `json:"url"`
 }
 
+type ping struct {
+	ID   int    `json:"id"`
+	Type string `json:"type"`
+}
+
 type slackResponse struct {
-	OK bool   `json:"ok"`
-	TS string `json:"ts"`
+	OK   bool   `json:"ok"`
+	TS   string `json:"ts"`
+	Text string `json:"text"`
 }
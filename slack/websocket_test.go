@@ -8,7 +8,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -40,7 +42,29 @@ func TestReceiveMessage(t *testing.T) {
 	}
 	do := func(client *client, called func()) {
 		client.OnMessage(func(got Message) {
-			if want != got {
+			if !reflect.DeepEqual(want, got) {
+				t.Errorf("want %v got %v", want, got)
+			}
+			called()
+		})
+	}
+	testReceive(t, want, do, AlwaysNotify)
+}
+
+func TestReceiveReaction(t *testing.T) {
+	want := Reaction{
+		Type:     "reaction_added",
+		User:     "nancy",
+		Reaction: "fire",
+		Item: ReactionItem{
+			Type:    "message",
+			Channel: "CANTINA",
+			TS:      "1.000",
+		},
+	}
+	do := func(client *client, called func()) {
+		client.OnReaction(func(got Reaction) {
+			if !reflect.DeepEqual(want, got) {
 				t.Errorf("want %v got %v", want, got)
 			}
 			called()
@@ -82,7 +106,8 @@ func TestIgnoresFilteredMessages(t *testing.T) {
 func TestSendMessage(t *testing.T) {
 	text := "It's a grand gesture"
 	do := func(client Client) error {
-		return client.SendText("CANTINA", text)
+		_, err := client.SendText("CANTINA", text)
+		return err
 	}
 	verify := func(v url.Values) bool {
 		return v.Get("text") == text
@@ -94,7 +119,8 @@ func TestSendImage(t *testing.T) {
 	text := "It's a grand gesture"
 	imageURL := "https://some.url/image.jpg"
 	do := func(client Client) error {
-		return client.SendImage("CANTINA", text, imageURL)
+		_, err := client.SendImage("CANTINA", text, imageURL)
+		return err
 	}
 	verify := func(v url.Values) bool {
 		var m []map[string]string
@@ -110,6 +136,133 @@ func TestSendImage(t *testing.T) {
 	testSendMessage(t, do, verify)
 }
 
+func TestUpdateText(t *testing.T) {
+	called := false
+	client := NewClient("cynicism", http.Client{
+		Transport: &roundTripper{
+			t:        t,
+			response: `{"ok": true, "channel": "CANTINA", "ts": "1.000", "text": "It's a grander gesture"}`,
+			called:   &called,
+			filter: func(req *http.Request) bool {
+				if req.URL.String() != "https://slack.com/api/chat.update" {
+					log.Printf("Wrong URL: %q", req.URL.String())
+					return false
+				}
+				if err := req.ParseForm(); err != nil {
+					log.Printf("Error parsing form: %v", err)
+					return false
+				}
+				return req.Form.Get("token") == "cynicism" &&
+					req.Form.Get("channel") == "CANTINA" &&
+					req.Form.Get("ts") == "1.000" &&
+					req.Form.Get("text") == "It's a grander gesture" &&
+					req.Form.Get("as_user") == "true"
+			},
+		},
+	}, AlwaysNotify)
+	if err := client.UpdateText("CANTINA", "1.000", "It's a grander gesture"); err != nil {
+		t.Errorf("Error updating message: %v", err)
+	}
+	if !called {
+		t.Errorf("Expected HTTP request but got none or incorrect")
+	}
+
+	// The message_changed event caused by our own edit is an echo, but
+	// someone else's later edit isn't.
+	echo := Message{
+		Type:    "message",
+		Subtype: "message_changed",
+		Channel: "CANTINA",
+		TS:      "2.000",
+		Message: &Message{Type: "message", TS: "1.000", Text: "It's a grander gesture"},
+	}
+	if !client.isEcho(&echo) {
+		t.Errorf("want echo of own edit to be suppressed")
+	}
+	echo.Message = &Message{Type: "message", TS: "1.000", Text: "It's a grandest gesture"}
+	if client.isEcho(&echo) {
+		t.Errorf("want other edit not to be suppressed")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	called := false
+	client := NewClient("cynicism", http.Client{
+		Transport: &roundTripper{
+			t:        t,
+			response: `{"ok": true, "channel": "CANTINA", "ts": "1.000"}`,
+			called:   &called,
+			filter: func(req *http.Request) bool {
+				if err := req.ParseForm(); err != nil {
+					log.Printf("Error parsing form: %v", err)
+					return false
+				}
+				return req.URL.String() == "https://slack.com/api/chat.delete" &&
+					req.Form.Get("token") == "cynicism" &&
+					req.Form.Get("channel") == "CANTINA" &&
+					req.Form.Get("ts") == "1.000"
+			},
+		},
+	}, AlwaysNotify)
+	if err := client.Delete("CANTINA", "1.000"); err != nil {
+		t.Errorf("Error deleting message: %v", err)
+	}
+	if !called {
+		t.Errorf("Expected HTTP request but got none or incorrect")
+	}
+	if !client.isEcho(&Message{Type: "message", Subtype: "message_deleted", Channel: "CANTINA", TS: "2.000", DeletedTS: "1.000"}) {
+		t.Errorf("want echo of own delete to be suppressed")
+	}
+}
+
+func TestAddReaction(t *testing.T) {
+	called := false
+	client := NewClient("cynicism", http.Client{
+		Transport: &roundTripper{
+			t:        t,
+			response: `{"ok": true}`,
+			called:   &called,
+			filter: func(req *http.Request) bool {
+				if err := req.ParseForm(); err != nil {
+					log.Printf("Error parsing form: %v", err)
+					return false
+				}
+				return req.URL.String() == "https://slack.com/api/reactions.add" &&
+					req.Form.Get("token") == "cynicism" &&
+					req.Form.Get("channel") == "CANTINA" &&
+					req.Form.Get("timestamp") == "1.000" &&
+					req.Form.Get("name") == "+1::skin-tone-2"
+			},
+		},
+	}, AlwaysNotify)
+	if err := client.AddReaction("CANTINA", "1.000", "+1::skin-tone-2"); err != nil {
+		t.Errorf("Error adding reaction: %v", err)
+	}
+	if !called {
+		t.Errorf("Expected HTTP request but got none or incorrect")
+	}
+	client.OnReaction(func(r Reaction) {
+		t.Errorf("want echo of own reaction to be suppressed, got %v", r)
+	})
+	client.dispatchReaction(Reaction{
+		Type:     "reaction_added",
+		User:     "nancy",
+		Reaction: "+1::skin-tone-2",
+		Item:     ReactionItem{Type: "message", Channel: "CANTINA", TS: "1.000"},
+	})
+}
+
+func TestSendThreadText(t *testing.T) {
+	do := func(client Client) error {
+		_, err := client.SendThreadText("CANTINA", "1.000", "reacted with 🦄")
+		return err
+	}
+	verify := func(v url.Values) bool {
+		return v.Get("text") == "reacted with 🦄" && v.Get("thread_ts") == "1.000"
+	}
+	testSendMessage(t, do, verify)
+}
+
 func testSendMessage(t *testing.T, do func(Client) error, verify func(url.Values) bool) {
 	called := false
 	client := NewClient("cynicism", http.Client{
@@ -230,3 +383,137 @@ func (r *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 		Body:       ioutil.NopCloser(strings.NewReader(r.response)),
 	}, nil
 }
+
+func TestReceiveLargeMessage(t *testing.T) {
+	want := Message{
+		Type: "message",
+		User: "nancy",
+		Text: strings.Repeat("I'm a... firewoman ", 2000),
+	}
+	do := func(client *client, called func()) {
+		client.OnMessage(func(got Message) {
+			if !reflect.DeepEqual(want, got) {
+				t.Errorf("want message of length %d got length %d", len(want.Text), len(got.Text))
+			}
+			called()
+		})
+	}
+	testReceive(t, want, do, AlwaysNotify)
+}
+
+func TestReconnectsAfterDisconnect(t *testing.T) {
+	var connections int32
+	s := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		atomic.AddInt32(&connections, 1)
+		websocket.JSON.Send(ws, Hello{Type: "hello"})
+	}))
+	defer s.Close()
+
+	client := rtmClient(t, s.URL)
+	client.minBackoff = time.Millisecond
+	states := make(chan ConnectionState, 10)
+	client.OnConnectionState(func(s ConnectionState) {
+		select {
+		case states <- s:
+		default:
+		}
+	})
+
+	cancel := make(chan struct{})
+	defer close(cancel)
+	go client.Listen(cancel)
+
+	want := []ConnectionState{Connecting, Connected, Disconnected, Connecting, Connected}
+	for _, w := range want {
+		select {
+		case got := <-states:
+			if got != w {
+				t.Fatalf("State: want %v got %v", w, got)
+			}
+		case _ = <-time.After(500 * time.Millisecond):
+			t.Fatalf("Timed out waiting for state %v", w)
+		}
+	}
+	if got := atomic.LoadInt32(&connections); got < 2 {
+		t.Errorf("Connections: want at least 2 got %d", got)
+	}
+}
+
+func TestReconnectsToReconnectURLOnGoodbye(t *testing.T) {
+	reconnected := make(chan struct{}, 1)
+	mux := http.NewServeMux()
+	s := httptest.NewServer(mux)
+	defer s.Close()
+	wsURL := strings.Replace(s.URL, "http://", "ws://", 1)
+	mux.Handle("/first", websocket.Handler(func(ws *websocket.Conn) {
+		websocket.JSON.Send(ws, Hello{Type: "hello"})
+		websocket.JSON.Send(ws, event{Type: "reconnect_url", URL: wsURL + "/second"})
+		websocket.JSON.Send(ws, event{Type: "goodbye"})
+		// Slack keeps the socket open after saying goodbye.
+		var b []byte
+		websocket.Message.Receive(ws, &b)
+	}))
+	mux.Handle("/second", websocket.Handler(func(ws *websocket.Conn) {
+		reconnected <- struct{}{}
+	}))
+
+	client := rtmClient(t, s.URL+"/first")
+	client.minBackoff = time.Millisecond
+	cancel := make(chan struct{})
+	defer close(cancel)
+	go client.Listen(cancel)
+
+	select {
+	case _ = <-reconnected:
+	case _ = <-time.After(500 * time.Millisecond):
+		t.Fatalf("Timed out waiting for reconnection")
+	}
+}
+
+func TestPingsWhenIdle(t *testing.T) {
+	pinged := make(chan ping, 1)
+	s := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		var p ping
+		if err := websocket.JSON.Receive(ws, &p); err != nil {
+			return
+		}
+		pinged <- p
+	}))
+	defer s.Close()
+
+	client := rtmClient(t, s.URL)
+	client.pingInterval = 10 * time.Millisecond
+	cancel := make(chan struct{})
+	defer close(cancel)
+	go client.Listen(cancel)
+
+	select {
+	case p := <-pinged:
+		if p.Type != "ping" {
+			t.Errorf("Type: want %q got %q", "ping", p.Type)
+		}
+	case _ = <-time.After(500 * time.Millisecond):
+		t.Fatalf("Timed out waiting for ping")
+	}
+}
+
+// rtmClient returns a client whose rtm.start call points at the websocket
+// server at url.
+func rtmClient(t *testing.T, url string) *client {
+	b, err := json.Marshal(rtmStartResponse{
+		OK:  true,
+		URL: strings.Replace(url, "http://", "ws://", 1),
+	})
+	if err != nil {
+		t.Fatalf("Error marshaling rtmStartResponse: %v", err)
+	}
+	return NewClient("", http.Client{
+		Transport: &roundTripper{
+			t:        t,
+			response: string(b),
+			filter: func(*http.Request) bool {
+				return true
+			},
+		},
+	}, AlwaysNotify)
+}
@@ -0,0 +1,196 @@
+package store
+
+import "sync"
+
+// NewMemory returns a Store which keeps everything in memory, for tests.
+func NewMemory() Store {
+	return &memoryStore{}
+}
+
+type memoryStore struct {
+	mu                    sync.Mutex
+	lastMatrixStreamToken string
+	rooms                 []Room
+	users                 []User
+	messages              []Message
+	reactions             []Reaction
+}
+
+func (s *memoryStore) Rooms() ([]Room, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Room(nil), s.rooms...), nil
+}
+
+func (s *memoryStore) Room(slackChannelID, matrixRoomID string) (*Room, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.room_Locked(slackChannelID, matrixRoomID); i != -1 {
+		room := s.rooms[i]
+		return &room, nil
+	}
+	return nil, nil
+}
+
+func (s *memoryStore) AddRoom(slackChannelID, matrixRoomID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rooms = append(s.rooms, Room{SlackChannelID: slackChannelID, MatrixRoomID: matrixRoomID})
+	return nil
+}
+
+func (s *memoryStore) RemoveRoom(slackChannelID, matrixRoomID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.room_Locked(slackChannelID, matrixRoomID); i != -1 {
+		s.rooms = append(s.rooms[:i], s.rooms[i+1:]...)
+	}
+	return nil
+}
+
+func (s *memoryStore) SetLastSlackTimestamp(slackChannelID, matrixRoomID, ts string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.room_Locked(slackChannelID, matrixRoomID); i != -1 {
+		s.rooms[i].LastSlackTimestamp = ts
+	}
+	return nil
+}
+
+func (s *memoryStore) LastMatrixStreamToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastMatrixStreamToken, nil
+}
+
+func (s *memoryStore) SetLastMatrixStreamToken(token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastMatrixStreamToken = token
+	return nil
+}
+
+func (s *memoryStore) Users() ([]User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]User(nil), s.users...), nil
+}
+
+func (s *memoryStore) HasUser(slackUserID, matrixUserID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users {
+		if u.SlackUserID == slackUserID && u.MatrixUserID == matrixUserID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *memoryStore) AddUser(u User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users = append(s.users, u)
+	return nil
+}
+
+func (s *memoryStore) RemoveUser(slackUserID, matrixUserID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	users := s.users[:0]
+	for _, u := range s.users {
+		if u.SlackUserID != slackUserID || u.MatrixUserID != matrixUserID {
+			users = append(users, u)
+		}
+	}
+	s.users = users
+	return nil
+}
+
+func (s *memoryStore) AddMessage(m Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, m)
+	return nil
+}
+
+func (s *memoryStore) MessagesForSlack(slackChannelID, slackTS string) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var messages []Message
+	for _, m := range s.messages {
+		if m.SlackChannelID == slackChannelID && m.SlackTS == slackTS {
+			messages = append(messages, m)
+		}
+	}
+	return messages, nil
+}
+
+func (s *memoryStore) MessageForMatrix(matrixRoomID, matrixEventID string) (*Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range s.messages {
+		if m.MatrixRoomID == matrixRoomID && m.MatrixEventID == matrixEventID {
+			m := m
+			return &m, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *memoryStore) AddReaction(r Reaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reactions = append(s.reactions, r)
+	return nil
+}
+
+func (s *memoryStore) ReactionForSlack(slackChannelID, slackTS, slackUserID, name string) (*Reaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.reactions {
+		if r.SlackChannelID == slackChannelID && r.SlackTS == slackTS && r.SlackUserID == slackUserID && r.Name == name {
+			r := r
+			return &r, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *memoryStore) ReactionForMatrix(matrixRoomID, matrixEventID string) (*Reaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.reactions {
+		if r.MatrixRoomID == matrixRoomID && r.MatrixEventID == matrixEventID {
+			r := r
+			return &r, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *memoryStore) RemoveReaction(matrixRoomID, matrixEventID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reactions := s.reactions[:0]
+	for _, r := range s.reactions {
+		if r.MatrixRoomID != matrixRoomID || r.MatrixEventID != matrixEventID {
+			reactions = append(reactions, r)
+		}
+	}
+	s.reactions = reactions
+	return nil
+}
+
+func (s *memoryStore) Close() error {
+	return nil
+}
+
+func (s *memoryStore) room_Locked(slackChannelID, matrixRoomID string) int {
+	for i, r := range s.rooms {
+		if r.SlackChannelID == slackChannelID && r.MatrixRoomID == matrixRoomID {
+			return i
+		}
+	}
+	return -1
+}
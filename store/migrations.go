@@ -0,0 +1,175 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// dialect describes how the SQL databases we support differ, as far as the
+// schema is concerned.
+type dialect struct {
+	// Column definition for an auto-incrementing integer primary key.
+	primaryKey string
+}
+
+// dialects are keyed by the name of their database/sql driver.
+var dialects = map[string]*dialect{
+	"sqlite3":  {primaryKey: "INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT"},
+	"postgres": {primaryKey: "SERIAL PRIMARY KEY"},
+}
+
+type migration struct {
+	description string
+	statements  func(d *dialect) []string
+}
+
+// migrations are applied in order; the schema version of a database is the
+// number of migrations which have been applied to it. Never edit or reorder
+// a migration which has been released - add a new one instead.
+var migrations = []migration{
+	{
+		// Databases which were set up by hand before migrations existed
+		// already have these tables, so they must be created if not exists.
+		description: "Create rooms and users tables",
+		statements: func(d *dialect) []string {
+			return []string{
+				`CREATE TABLE IF NOT EXISTS rooms(
+id ` + d.primaryKey + `,
+slack_channel_id TEXT,
+matrix_room_id TEXT,
+last_slack_timestamp TEXT,
+last_matrix_stream_token TEXT
+)`,
+				`CREATE TABLE IF NOT EXISTS users(
+id ` + d.primaryKey + `,
+slack_user_id TEXT,
+slack_access_token TEXT,
+matrix_user_id TEXT,
+matrix_access_token TEXT,
+matrix_homeserver TEXT
+)`,
+			}
+		},
+	},
+	{
+		description: "Create messages table",
+		statements: func(d *dialect) []string {
+			return []string{
+				`CREATE TABLE messages(
+id ` + d.primaryKey + `,
+slack_channel_id TEXT NOT NULL,
+slack_ts TEXT NOT NULL,
+matrix_room_id TEXT NOT NULL,
+matrix_event_id TEXT NOT NULL
+)`,
+				`CREATE INDEX messages_slack ON messages(slack_channel_id, slack_ts)`,
+				`CREATE INDEX messages_matrix ON messages(matrix_room_id, matrix_event_id)`,
+			}
+		},
+	},
+	{
+		description: "Record who sent bridged messages on Matrix",
+		statements: func(d *dialect) []string {
+			return []string{
+				`ALTER TABLE messages ADD COLUMN matrix_sender TEXT`,
+			}
+		},
+	},
+	{
+		description: "Create reactions table",
+		statements: func(d *dialect) []string {
+			return []string{
+				`CREATE TABLE reactions(
+id ` + d.primaryKey + `,
+slack_channel_id TEXT NOT NULL,
+slack_ts TEXT NOT NULL,
+slack_user_id TEXT NOT NULL,
+name TEXT NOT NULL,
+matrix_room_id TEXT NOT NULL,
+matrix_event_id TEXT NOT NULL
+)`,
+				`CREATE INDEX reactions_slack ON reactions(slack_channel_id, slack_ts)`,
+				`CREATE INDEX reactions_matrix ON reactions(matrix_room_id, matrix_event_id)`,
+			}
+		},
+	},
+	{
+		// The stream token used to be copied onto every room, so it was lost
+		// when none were linked.
+		description: "Create checkpoint table",
+		statements: func(d *dialect) []string {
+			return []string{
+				`CREATE TABLE checkpoint(
+id INTEGER NOT NULL PRIMARY KEY CHECK (id = 1),
+last_matrix_stream_token TEXT
+)`,
+				`INSERT INTO checkpoint (id, last_matrix_stream_token) SELECT 1, MAX(last_matrix_stream_token) FROM rooms`,
+			}
+		},
+	},
+	{
+		description: "Record who sent bridged reactions on Matrix, and which were sent as notes",
+		statements: func(d *dialect) []string {
+			return []string{
+				`ALTER TABLE reactions ADD COLUMN matrix_sender TEXT`,
+				`ALTER TABLE reactions ADD COLUMN note BOOLEAN NOT NULL DEFAULT FALSE`,
+			}
+		},
+	},
+}
+
+// Migrate brings the schema of a database opened with the named driver up to
+// date, applying each outstanding migration in its own transaction. It refuses
+// to touch a database whose schema is newer than this code understands.
+func Migrate(db *sql.DB, driver string) error {
+	d, ok := dialects[driver]
+	if !ok {
+		return fmt.Errorf("unsupported database driver %q", driver)
+	}
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_version(version INTEGER NOT NULL)`); err != nil {
+		return fmt.Errorf("error creating schema_version table: %v", err)
+	}
+	version, err := schemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than the latest known version %d", version, len(migrations))
+	}
+	for i := version; i < len(migrations); i++ {
+		log.Printf("Migrating database to schema version %d: %s", i+1, migrations[i].description)
+		if err := applyMigration(db, d, i+1, migrations[i]); err != nil {
+			return fmt.Errorf("error migrating database to schema version %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// schemaVersion returns the schema version of the database, which is 0 if no
+// migrations have been applied.
+func schemaVersion(db *sql.DB) (int, error) {
+	var version sql.NullInt64
+	if err := db.QueryRow(`SELECT MAX(version) FROM schema_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("error reading schema version: %v", err)
+	}
+	return int(version.Int64), nil
+}
+
+func applyMigration(db *sql.DB, d *dialect, version int, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, s := range m.statements(d) {
+		if _, err := tx.Exec(s); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_version (version) VALUES ($1)`, version); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
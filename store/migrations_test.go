@@ -0,0 +1,92 @@
+package store
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"path"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestMigrateFreshDatabase(t *testing.T) {
+	db := openDB(t)
+	if err := Migrate(db, "sqlite3"); err != nil {
+		t.Fatal(err)
+	}
+	assertVersion(t, db, len(migrations))
+	if _, err := db.Exec(`INSERT INTO rooms (slack_channel_id, matrix_room_id) VALUES ($1, $2)`, "CANTINA", "!abc123:matrix.org"); err != nil {
+		t.Errorf("Error using migrated rooms table: %v", err)
+	}
+
+	// Migrating again should be a no-op.
+	if err := Migrate(db, "sqlite3"); err != nil {
+		t.Fatal(err)
+	}
+	assertVersion(t, db, len(migrations))
+}
+
+func TestMigrateAdoptsUnversionedDatabase(t *testing.T) {
+	db := openDB(t)
+	if _, err := db.Exec(`CREATE TABLE rooms(
+id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+slack_channel_id TEXT,
+matrix_room_id TEXT,
+last_slack_timestamp TEXT,
+last_matrix_stream_token TEXT
+)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO rooms (slack_channel_id, matrix_room_id, last_matrix_stream_token) VALUES ($1, $2, $3)`, "CANTINA", "!abc123:matrix.org", "s72_1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(db, "sqlite3"); err != nil {
+		t.Fatal(err)
+	}
+	assertVersion(t, db, len(migrations))
+	var slack string
+	if err := db.QueryRow(`SELECT slack_channel_id FROM rooms`).Scan(&slack); err != nil {
+		t.Fatal(err)
+	}
+	if slack != "CANTINA" {
+		t.Errorf("want %q got %q", "CANTINA", slack)
+	}
+	if token, err := NewSQL(db).LastMatrixStreamToken(); err != nil || token != "s72_1" {
+		t.Errorf("LastMatrixStreamToken: want %q, nil got %q, %v", "s72_1", token, err)
+	}
+}
+
+func TestMigrateRefusesNewerSchema(t *testing.T) {
+	db := openDB(t)
+	if err := Migrate(db, "sqlite3"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO schema_version (version) VALUES ($1)`, len(migrations)+1); err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(db, "sqlite3"); err == nil {
+		t.Errorf("want error migrating newer schema, got nil")
+	}
+}
+
+func openDB(t *testing.T) *sql.DB {
+	dir, err := ioutil.TempDir("", "testdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite3", path.Join(dir, "sqlite3.db"))
+	if err != nil {
+		t.Fatalf("Could not open database: %v", err)
+	}
+	return db
+}
+
+func assertVersion(t *testing.T, db *sql.DB, want int) {
+	got, err := schemaVersion(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("Schema version: want %d got %d", want, got)
+	}
+}
@@ -0,0 +1,247 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// NewSQL returns a Store backed by a database whose schema has been brought up
+// to date with Migrate.
+func NewSQL(db *sql.DB) Store {
+	return &sqlStore{db}
+}
+
+// sqlStore only uses SQL which SQLite and PostgreSQL agree on; differences
+// between them are confined to migrations.
+type sqlStore struct {
+	db *sql.DB
+}
+
+func (s *sqlStore) Rooms() ([]Room, error) {
+	rows, err := s.db.Query(`SELECT slack_channel_id, matrix_room_id, last_slack_timestamp FROM rooms ORDER BY id ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("error reading from db: %v", err)
+	}
+	defer rows.Close()
+	var rooms []Room
+	for rows.Next() {
+		room, err := scanRoom(rows)
+		if err != nil {
+			return nil, err
+		}
+		rooms = append(rooms, *room)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading from db: %v", err)
+	}
+	return rooms, nil
+}
+
+func (s *sqlStore) Room(slackChannelID, matrixRoomID string) (*Room, error) {
+	row := s.db.QueryRow(`SELECT slack_channel_id, matrix_room_id, last_slack_timestamp FROM rooms WHERE slack_channel_id = $1 AND matrix_room_id = $2`, slackChannelID, matrixRoomID)
+	room, err := scanRoom(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return room, err
+}
+
+func (s *sqlStore) AddRoom(slackChannelID, matrixRoomID string) error {
+	if _, err := s.db.Exec(`INSERT INTO rooms (slack_channel_id, matrix_room_id) VALUES ($1, $2)`, slackChannelID, matrixRoomID); err != nil {
+		return fmt.Errorf("error writing to db: %v", err)
+	}
+	return nil
+}
+
+func (s *sqlStore) RemoveRoom(slackChannelID, matrixRoomID string) error {
+	if _, err := s.db.Exec(`DELETE FROM rooms WHERE slack_channel_id = $1 AND matrix_room_id = $2`, slackChannelID, matrixRoomID); err != nil {
+		return fmt.Errorf("error writing to db: %v", err)
+	}
+	return nil
+}
+
+func (s *sqlStore) SetLastSlackTimestamp(slackChannelID, matrixRoomID, ts string) error {
+	if _, err := s.db.Exec(`UPDATE rooms SET last_slack_timestamp = $1 WHERE slack_channel_id = $2 AND matrix_room_id = $3`, ts, slackChannelID, matrixRoomID); err != nil {
+		return fmt.Errorf("error writing to db: %v", err)
+	}
+	return nil
+}
+
+func (s *sqlStore) LastMatrixStreamToken() (string, error) {
+	var token sql.NullString
+	if err := s.db.QueryRow(`SELECT last_matrix_stream_token FROM checkpoint WHERE id = 1`).Scan(&token); err != nil {
+		return "", fmt.Errorf("error reading from db: %v", err)
+	}
+	return token.String, nil
+}
+
+func (s *sqlStore) SetLastMatrixStreamToken(token string) error {
+	if _, err := s.db.Exec(`UPDATE checkpoint SET last_matrix_stream_token = $1 WHERE id = 1`, token); err != nil {
+		return fmt.Errorf("error writing to db: %v", err)
+	}
+	return nil
+}
+
+func (s *sqlStore) Users() ([]User, error) {
+	rows, err := s.db.Query(`SELECT slack_user_id, slack_access_token, matrix_user_id, matrix_access_token, matrix_homeserver FROM users ORDER BY id ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("error reading from db: %v", err)
+	}
+	defer rows.Close()
+	var users []User
+	for rows.Next() {
+		var slackID, matrixID string
+		var slackToken, matrixToken, matrixHomeserver sql.NullString
+		if err := rows.Scan(&slackID, &slackToken, &matrixID, &matrixToken, &matrixHomeserver); err != nil {
+			return nil, fmt.Errorf("error reading from db: %v", err)
+		}
+		users = append(users, User{
+			SlackUserID:       slackID,
+			SlackAccessToken:  slackToken.String,
+			MatrixUserID:      matrixID,
+			MatrixAccessToken: matrixToken.String,
+			MatrixHomeserver:  matrixHomeserver.String,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading from db: %v", err)
+	}
+	return users, nil
+}
+
+func (s *sqlStore) HasUser(slackUserID, matrixUserID string) (bool, error) {
+	var count int32
+	if err := s.db.QueryRow(`SELECT COUNT(id) FROM users WHERE slack_user_id = $1 AND matrix_user_id = $2`, slackUserID, matrixUserID).Scan(&count); err != nil {
+		return false, fmt.Errorf("error reading from db: %v", err)
+	}
+	return count > 0, nil
+}
+
+func (s *sqlStore) AddUser(u User) error {
+	if _, err := s.db.Exec(`INSERT INTO users (slack_user_id, slack_access_token, matrix_user_id, matrix_access_token, matrix_homeserver) VALUES ($1, $2, $3, $4, $5)`, u.SlackUserID, u.SlackAccessToken, u.MatrixUserID, u.MatrixAccessToken, u.MatrixHomeserver); err != nil {
+		return fmt.Errorf("error writing to db: %v", err)
+	}
+	return nil
+}
+
+func (s *sqlStore) RemoveUser(slackUserID, matrixUserID string) error {
+	if _, err := s.db.Exec(`DELETE FROM users WHERE slack_user_id = $1 AND matrix_user_id = $2`, slackUserID, matrixUserID); err != nil {
+		return fmt.Errorf("error writing to db: %v", err)
+	}
+	return nil
+}
+
+func (s *sqlStore) AddMessage(m Message) error {
+	if _, err := s.db.Exec(`INSERT INTO messages (slack_channel_id, slack_ts, matrix_room_id, matrix_event_id, matrix_sender) VALUES ($1, $2, $3, $4, $5)`, m.SlackChannelID, m.SlackTS, m.MatrixRoomID, m.MatrixEventID, m.MatrixSender); err != nil {
+		return fmt.Errorf("error writing to db: %v", err)
+	}
+	return nil
+}
+
+func (s *sqlStore) MessagesForSlack(slackChannelID, slackTS string) ([]Message, error) {
+	rows, err := s.db.Query(`SELECT slack_channel_id, slack_ts, matrix_room_id, matrix_event_id, matrix_sender FROM messages WHERE slack_channel_id = $1 AND slack_ts = $2 ORDER BY id ASC`, slackChannelID, slackTS)
+	if err != nil {
+		return nil, fmt.Errorf("error reading from db: %v", err)
+	}
+	defer rows.Close()
+	var messages []Message
+	for rows.Next() {
+		m, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, *m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading from db: %v", err)
+	}
+	return messages, nil
+}
+
+func (s *sqlStore) MessageForMatrix(matrixRoomID, matrixEventID string) (*Message, error) {
+	row := s.db.QueryRow(`SELECT slack_channel_id, slack_ts, matrix_room_id, matrix_event_id, matrix_sender FROM messages WHERE matrix_room_id = $1 AND matrix_event_id = $2`, matrixRoomID, matrixEventID)
+	m, err := scanMessage(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return m, err
+}
+
+func (s *sqlStore) AddReaction(r Reaction) error {
+	if _, err := s.db.Exec(`INSERT INTO reactions (slack_channel_id, slack_ts, slack_user_id, name, matrix_room_id, matrix_event_id, matrix_sender, note) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`, r.SlackChannelID, r.SlackTS, r.SlackUserID, r.Name, r.MatrixRoomID, r.MatrixEventID, r.MatrixSender, r.Note); err != nil {
+		return fmt.Errorf("error writing to db: %v", err)
+	}
+	return nil
+}
+
+func (s *sqlStore) ReactionForSlack(slackChannelID, slackTS, slackUserID, name string) (*Reaction, error) {
+	row := s.db.QueryRow(`SELECT slack_channel_id, slack_ts, slack_user_id, name, matrix_room_id, matrix_event_id, matrix_sender, note FROM reactions WHERE slack_channel_id = $1 AND slack_ts = $2 AND slack_user_id = $3 AND name = $4`, slackChannelID, slackTS, slackUserID, name)
+	r, err := scanReaction(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return r, err
+}
+
+func (s *sqlStore) ReactionForMatrix(matrixRoomID, matrixEventID string) (*Reaction, error) {
+	row := s.db.QueryRow(`SELECT slack_channel_id, slack_ts, slack_user_id, name, matrix_room_id, matrix_event_id, matrix_sender, note FROM reactions WHERE matrix_room_id = $1 AND matrix_event_id = $2`, matrixRoomID, matrixEventID)
+	r, err := scanReaction(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return r, err
+}
+
+func (s *sqlStore) RemoveReaction(matrixRoomID, matrixEventID string) error {
+	if _, err := s.db.Exec(`DELETE FROM reactions WHERE matrix_room_id = $1 AND matrix_event_id = $2`, matrixRoomID, matrixEventID); err != nil {
+		return fmt.Errorf("error writing to db: %v", err)
+	}
+	return nil
+}
+
+func (s *sqlStore) Close() error {
+	return s.db.Close()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRoom(row scanner) (*Room, error) {
+	var room Room
+	var lastSlackTimestamp sql.NullString
+	err := row.Scan(&room.SlackChannelID, &room.MatrixRoomID, &lastSlackTimestamp)
+	if err == sql.ErrNoRows {
+		return nil, err
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading from db: %v", err)
+	}
+	room.LastSlackTimestamp = lastSlackTimestamp.String
+	return &room, nil
+}
+
+func scanMessage(row scanner) (*Message, error) {
+	var m Message
+	var sender sql.NullString
+	err := row.Scan(&m.SlackChannelID, &m.SlackTS, &m.MatrixRoomID, &m.MatrixEventID, &sender)
+	if err == sql.ErrNoRows {
+		return nil, err
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading from db: %v", err)
+	}
+	m.MatrixSender = sender.String
+	return &m, nil
+}
+
+func scanReaction(row scanner) (*Reaction, error) {
+	var r Reaction
+	var sender sql.NullString
+	err := row.Scan(&r.SlackChannelID, &r.SlackTS, &r.SlackUserID, &r.Name, &r.MatrixRoomID, &r.MatrixEventID, &sender, &r.Note)
+	if err == sql.ErrNoRows {
+		return nil, err
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading from db: %v", err)
+	}
+	r.MatrixSender = sender.String
+	return &r, nil
+}
@@ -0,0 +1,93 @@
+package store
+
+// Store persists the links between Slack and Matrix, and how far through each
+// side's events the bridge has got.
+type Store interface {
+	// Rooms returns every room link, oldest first.
+	Rooms() ([]Room, error)
+	// Room returns the link between slackChannelID and matrixRoomID, or nil
+	// if there is none.
+	Room(slackChannelID, matrixRoomID string) (*Room, error)
+	AddRoom(slackChannelID, matrixRoomID string) error
+	RemoveRoom(slackChannelID, matrixRoomID string) error
+
+	// SetLastSlackTimestamp records the timestamp of the last Slack message
+	// seen in a linked room.
+	SetLastSlackTimestamp(slackChannelID, matrixRoomID, ts string) error
+	// LastMatrixStreamToken returns the token of the Matrix event stream up
+	// to which all events have been handled, or "" if none has been saved.
+	LastMatrixStreamToken() (string, error)
+	// SetLastMatrixStreamToken records the token of the Matrix event stream
+	// up to which all events have been handled. There is one stream for the
+	// whole bridge, whether or not any rooms are linked.
+	SetLastMatrixStreamToken(token string) error
+
+	// Users returns every user link, oldest first.
+	Users() ([]User, error)
+	HasUser(slackUserID, matrixUserID string) (bool, error)
+	AddUser(user User) error
+	RemoveUser(slackUserID, matrixUserID string) error
+
+	// AddMessage records that a Slack message and a Matrix event are the same
+	// message, bridged one way or the other.
+	AddMessage(message Message) error
+	// MessagesForSlack returns the Matrix events which a Slack message was
+	// bridged to or from, oldest first.
+	MessagesForSlack(slackChannelID, slackTS string) ([]Message, error)
+	// MessageForMatrix returns the Slack message which a Matrix event was
+	// bridged to or from, or nil if there is none.
+	MessageForMatrix(matrixRoomID, matrixEventID string) (*Message, error)
+
+	// AddReaction records that a Slack reaction and a Matrix annotation are
+	// the same reaction, bridged one way or the other.
+	AddReaction(reaction Reaction) error
+	// ReactionForSlack returns the reaction name which slackUserID added to a
+	// Slack message, or nil if it wasn't bridged.
+	ReactionForSlack(slackChannelID, slackTS, slackUserID, name string) (*Reaction, error)
+	// ReactionForMatrix returns the reaction which the annotation
+	// matrixEventID was bridged to or from, or nil if there is none.
+	ReactionForMatrix(matrixRoomID, matrixEventID string) (*Reaction, error)
+	RemoveReaction(matrixRoomID, matrixEventID string) error
+
+	Close() error
+}
+
+type Room struct {
+	SlackChannelID     string
+	MatrixRoomID       string
+	LastSlackTimestamp string
+}
+
+type User struct {
+	SlackUserID       string
+	SlackAccessToken  string
+	MatrixUserID      string
+	MatrixAccessToken string
+	MatrixHomeserver  string
+}
+
+type Message struct {
+	SlackChannelID string
+	SlackTS        string
+	MatrixRoomID   string
+	MatrixEventID  string
+	// The Matrix user who sent the event, whether a real user or one of the
+	// bridge's users.
+	MatrixSender string
+}
+
+type Reaction struct {
+	SlackChannelID string
+	SlackTS        string
+	SlackUserID    string
+	// The Slack name of the reaction, like "+1::skin-tone-2".
+	Name          string
+	MatrixRoomID  string
+	MatrixEventID string
+	// The Matrix user who reacted, whether a real user or one of the bridge's
+	// users.
+	MatrixSender string
+	// Note is set when the reaction was sent to Slack as a reply saying who
+	// reacted with what, and SlackTS is the reply's.
+	Note bool
+}
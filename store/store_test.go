@@ -0,0 +1,181 @@
+package store
+
+import (
+	"database/sql"
+	"os"
+	"reflect"
+	"testing"
+
+	_ "github.com/lib/pq"
+)
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemory())
+}
+
+func TestSQLiteStore(t *testing.T) {
+	db := openDB(t)
+	if err := Migrate(db, "sqlite3"); err != nil {
+		t.Fatal(err)
+	}
+	s := NewSQL(db)
+	defer s.Close()
+	testStore(t, s)
+}
+
+// Set SLACKBRIDGE_TEST_POSTGRES to the connection string of an empty database
+// to run against PostgreSQL.
+func TestPostgresStore(t *testing.T) {
+	dsn := os.Getenv("SLACKBRIDGE_TEST_POSTGRES")
+	if dsn == "" {
+		t.Skip("SLACKBRIDGE_TEST_POSTGRES not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(db, "postgres"); err != nil {
+		t.Fatal(err)
+	}
+	s := NewSQL(db)
+	defer s.Close()
+	testStore(t, s)
+}
+
+func testStore(t *testing.T, s Store) {
+	if token, err := s.LastMatrixStreamToken(); err != nil || token != "" {
+		t.Fatalf("LastMatrixStreamToken before SetLastMatrixStreamToken: want \"\", nil got %q, %v", token, err)
+	}
+	// The stream token is saved even when no rooms are linked.
+	if err := s.SetLastMatrixStreamToken("s72594_4483_1934"); err != nil {
+		t.Fatal(err)
+	}
+	if token, err := s.LastMatrixStreamToken(); err != nil || token != "s72594_4483_1934" {
+		t.Errorf("LastMatrixStreamToken: want %q, nil got %q, %v", "s72594_4483_1934", token, err)
+	}
+
+	if room, err := s.Room("CANTINA", "!abc123:matrix.org"); err != nil || room != nil {
+		t.Fatalf("Room before AddRoom: want nil, nil got %v, %v", room, err)
+	}
+	if err := s.AddRoom("CANTINA", "!abc123:matrix.org"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddRoom("KITCHEN", "!def456:matrix.org"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetLastSlackTimestamp("CANTINA", "!abc123:matrix.org", "1.000"); err != nil {
+		t.Fatal(err)
+	}
+
+	wantRooms := []Room{
+		{"CANTINA", "!abc123:matrix.org", "1.000"},
+		{"KITCHEN", "!def456:matrix.org", ""},
+	}
+	rooms, err := s.Rooms()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rooms, wantRooms) {
+		t.Errorf("Rooms: want %v got %v", wantRooms, rooms)
+	}
+	room, err := s.Room("CANTINA", "!abc123:matrix.org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if room == nil || *room != wantRooms[0] {
+		t.Errorf("Room: want %v got %v", wantRooms[0], room)
+	}
+
+	user := User{
+		SlackUserID:       "U34",
+		SlackAccessToken:  "xoxp-abc",
+		MatrixUserID:      "@nancy:st.andrews",
+		MatrixAccessToken: "def",
+		MatrixHomeserver:  "https://matrix.org",
+	}
+	if has, err := s.HasUser("U34", "@nancy:st.andrews"); err != nil || has {
+		t.Fatalf("HasUser before AddUser: want false, nil got %v, %v", has, err)
+	}
+	if err := s.AddUser(user); err != nil {
+		t.Fatal(err)
+	}
+	if has, err := s.HasUser("U34", "@nancy:st.andrews"); err != nil || !has {
+		t.Errorf("HasUser after AddUser: want true, nil got %v, %v", has, err)
+	}
+	users, err := s.Users()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []User{user}; !reflect.DeepEqual(users, want) {
+		t.Errorf("Users: want %v got %v", want, users)
+	}
+
+	if err := s.RemoveRoom("CANTINA", "!abc123:matrix.org"); err != nil {
+		t.Fatal(err)
+	}
+	if room, err := s.Room("CANTINA", "!abc123:matrix.org"); err != nil || room != nil {
+		t.Errorf("Room after RemoveRoom: want nil, nil got %v, %v", room, err)
+	}
+	if rooms, err := s.Rooms(); err != nil || !reflect.DeepEqual(rooms, wantRooms[1:]) {
+		t.Errorf("Rooms after RemoveRoom: want %v, nil got %v, %v", wantRooms[1:], rooms, err)
+	}
+	if err := s.RemoveUser("U34", "@nancy:st.andrews"); err != nil {
+		t.Fatal(err)
+	}
+	if has, err := s.HasUser("U34", "@nancy:st.andrews"); err != nil || has {
+		t.Errorf("HasUser after RemoveUser: want false, nil got %v, %v", has, err)
+	}
+
+	image := Message{"CANTINA", "1.000", "!abc123:matrix.org", "$image", "@slack_nancy:matrix.org"}
+	comment := Message{"CANTINA", "1.000", "!abc123:matrix.org", "$comment", "@slack_nancy:matrix.org"}
+	for _, m := range []Message{image, comment, {"CANTINA", "2.000", "!abc123:matrix.org", "$other", "@vivian:matrix.org"}} {
+		if err := s.AddMessage(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	messages, err := s.MessagesForSlack("CANTINA", "1.000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Message{image, comment}; !reflect.DeepEqual(messages, want) {
+		t.Errorf("MessagesForSlack: want %v got %v", want, messages)
+	}
+	message, err := s.MessageForMatrix("!abc123:matrix.org", "$comment")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message == nil || *message != comment {
+		t.Errorf("MessageForMatrix: want %v got %v", comment, message)
+	}
+	if message, err := s.MessageForMatrix("!abc123:matrix.org", "$unknown"); err != nil || message != nil {
+		t.Errorf("MessageForMatrix unknown: want nil, nil got %v, %v", message, err)
+	}
+
+	reaction := Reaction{"CANTINA", "1.000", "U34", "+1::skin-tone-2", "!abc123:matrix.org", "$reaction", "@slack_nancy:matrix.org", false}
+	if err := s.AddReaction(reaction); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.ReactionForSlack("CANTINA", "1.000", "U34", "+1::skin-tone-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || *got != reaction {
+		t.Errorf("ReactionForSlack: want %v got %v", reaction, got)
+	}
+	if got, err := s.ReactionForMatrix("!abc123:matrix.org", "$reaction"); err != nil || got == nil || *got != reaction {
+		t.Errorf("ReactionForMatrix: want %v, nil got %v, %v", reaction, got, err)
+	}
+	if err := s.RemoveReaction("!abc123:matrix.org", "$reaction"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.ReactionForSlack("CANTINA", "1.000", "U34", "+1::skin-tone-2"); err != nil || got != nil {
+		t.Errorf("ReactionForSlack after RemoveReaction: want nil, nil got %v, %v", got, err)
+	}
+	note := Reaction{"CANTINA", "3.000", "", "", "!abc123:matrix.org", "$note", "@vivian:matrix.org", true}
+	if err := s.AddReaction(note); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.ReactionForMatrix("!abc123:matrix.org", "$note"); err != nil || got == nil || *got != note {
+		t.Errorf("ReactionForMatrix note: want %v, nil got %v, %v", note, got, err)
+	}
+}